@@ -0,0 +1,284 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/config"
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/formdef"
+)
+
+const usage = `Usage:
+  server [flags]              start the HTTP server (server -h lists the flags)
+  server migrate up           apply all pending migrations
+  server migrate down [n]     revert the last n migrations (default 1)
+  server migrate status       list migrations and whether they are applied
+  server seed                 insert sample event and form data
+  server grant EMAIL EVENT_ID ROLE
+                              give an organizer a role (owner, editor, viewer) on an event
+  server export-form FORM_ID [json|yaml]
+                              print a form definition (default json)
+  server import-form [-dry-run] EVENT_ID FILE
+                              create or update a form of an event from a .json or .yaml
+                              definition, matched by slug, and print the changes
+
+Settings are read from the YAML file named by -config or CONFIG_FILE, then from
+environment variables. The server's flags override both.
+`
+
+// runCommand runs a command line subcommand and returns the process exit code
+func runCommand(name string, args []string) int {
+	var run func(dbConfig database.Config, args []string) error
+
+	switch name {
+	case "migrate":
+		run = runMigrate
+	case "seed":
+		run = runSeed
+	case "grant":
+		run = runGrant
+	case "export-form":
+		run = runExportForm
+	case "import-form":
+		run = runImportForm
+	case "help":
+		fmt.Print(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+
+	// Commands take their configuration from the config file and the environment
+	cfg, err := config.LoadCommand()
+	if err == nil {
+		err = run(cfg.Database, args)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runMigrate handles the migrate subcommand
+func runMigrate(dbConfig database.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate requires one of: up, down, status")
+	}
+
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer database.RawDB.Close()
+
+	switch args[0] {
+	case "up":
+		err = database.MigrateUp(db)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Migrations applied")
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations: %s", args[1])
+			}
+		}
+
+		// Report the migrations reverted before any failure as well
+		reverted, err := database.MigrateDown(db, steps)
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+		if err != nil {
+			return err
+		}
+
+	case "status":
+		states, err := database.MigrationStatus(db)
+		if err != nil {
+			return err
+		}
+
+		for _, state := range states {
+			status := "pending"
+			if state.Applied {
+				status = "applied " + state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", state.Version, state.Name, status)
+		}
+
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+
+	return nil
+}
+
+// runSeed inserts sample data into a migrated database
+func runSeed(dbConfig database.Config, args []string) error {
+	_, err := database.InitDB(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer database.RawDB.Close()
+
+	err = database.Seed(database.DB)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Seed data created")
+	return nil
+}
+
+// runGrant gives an existing organizer account a role on an event, which is
+// how events created before accounts existed get their first owner
+func runGrant(dbConfig database.Config, args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("grant requires EMAIL EVENT_ID ROLE")
+	}
+
+	email := auth.NormalizeEmail(args[0])
+	eventID, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid event ID: %s", args[1])
+	}
+	role := args[2]
+	if !auth.IsValidRole(role) {
+		return fmt.Errorf("invalid role %q, must be owner, editor or viewer", role)
+	}
+
+	_, err = database.InitDB(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer database.RawDB.Close()
+
+	var user database.User
+	err = database.DB.Where("email = ?", email).First(&user).Error
+	if err != nil {
+		return fmt.Errorf("no account found for %s: %w", email, err)
+	}
+
+	var event database.Event
+	err = database.DB.First(&event, eventID).Error
+	if err != nil {
+		return fmt.Errorf("event %d not found: %w", eventID, err)
+	}
+
+	// Update the existing membership or create a new one
+	member := database.EventMember{EventID: event.ID, UserID: user.ID}
+	err = database.DB.Where(&member).Assign(database.EventMember{Role: role}).FirstOrCreate(&member).Error
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is now %s of %q\n", user.Email, role, event.Name)
+	return nil
+}
+
+// runExportForm prints the definition of a form
+func runExportForm(dbConfig database.Config, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("export-form requires FORM_ID [json|yaml]")
+	}
+	formID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid form ID: %s", args[0])
+	}
+	format := formdef.FormatJSON
+	if len(args) == 2 {
+		format = args[1]
+	}
+
+	_, err = database.InitDB(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer database.RawDB.Close()
+
+	d, err := formdef.Load(database.DB, uint(formID))
+	if err != nil {
+		return fmt.Errorf("form %d not found: %w", formID, err)
+	}
+	data, err := formdef.Encode(d, format)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// runImportForm creates or updates a form from a definition file and prints
+// what changed
+func runImportForm(dbConfig database.Config, args []string) error {
+	flags := flag.NewFlagSet("import-form", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only print the changes")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("import-form requires [-dry-run] EVENT_ID FILE")
+	}
+	eventID, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid event ID: %s", flags.Arg(0))
+	}
+	path := flags.Arg(1)
+
+	format := formdef.FormatJSON
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		format = formdef.FormatYAML
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	d, err := formdef.Decode(data, format)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.InitDB(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer database.RawDB.Close()
+
+	var event database.Event
+	err = database.DB.First(&event, eventID).Error
+	if err != nil {
+		return fmt.Errorf("event %d not found: %w", eventID, err)
+	}
+
+	result, err := formdef.Import(database.DB, d, event.ID, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, change := range result.Changes {
+		fmt.Println(change)
+	}
+
+	switch {
+	case len(result.Changes) == 0:
+		fmt.Printf("Form %q is up to date\n", result.Slug)
+	case *dryRun:
+		fmt.Printf("Dry run: %d change(s) to form %q were not applied\n", len(result.Changes), result.Slug)
+	case result.Created:
+		fmt.Printf("Created form %q (ID %d)\n", result.Slug, result.FormID)
+	default:
+		fmt.Printf("Applied %d change(s) to form %q (ID %d)\n", len(result.Changes), result.Slug, result.FormID)
+	}
+	return nil
+}
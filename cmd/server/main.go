@@ -1,47 +1,153 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
+	"github.com/yourusername/event-feedback/internal/config"
 	"github.com/yourusername/event-feedback/internal/database"
 	"github.com/yourusername/event-feedback/internal/handlers"
+	"github.com/yourusername/event-feedback/internal/janitor"
 	"github.com/yourusername/event-feedback/internal/middleware"
+	"github.com/yourusername/event-feedback/internal/notify"
+	"github.com/yourusername/event-feedback/internal/respondent"
+	"github.com/yourusername/event-feedback/internal/schedule"
+	"github.com/yourusername/event-feedback/internal/spam"
+	"github.com/yourusername/event-feedback/internal/store"
+	"github.com/yourusername/event-feedback/internal/webhooks"
 )
 
 func main() {
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Run a subcommand instead of the server if one was given
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
 	}
 
+	// Load configuration from the config file, environment and flags
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	log.Printf("Configuration:\n%s", cfg)
+
+	// Stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize database
-	db, err := database.InitDB()
+	db, err := database.InitDB(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer db.Close()
+
+	// Background workers stop once ctx is cancelled
+	var workers sync.WaitGroup
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(ctx)
+		}()
+	}
+
+	// Deliver queued emails in the background
+	mailer, err := notify.NewMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to configure mail: %v", err)
+	}
+	notify.BaseURL = cfg.Mail.BaseURL
+	outbox := notify.NewWorker(database.DB, mailer)
+	outbox.DigestInterval = cfg.Mail.DigestInterval
+	runWorker(outbox.Run)
+
+	// Deliver queued webhooks in the background
+	runWorker(webhooks.NewWorker(database.DB).Run)
+
+	// Open and close scheduled forms in the background
+	runWorker(schedule.NewWorker(database.DB).Run)
+
+	// Purge abandoned in-progress submissions in the background
+	runWorker(janitor.NewWorker(database.DB, cfg.Drafts.TTL).Run)
+
+	// Serve everything from the database, checking public form submissions for spam
+	app := handlers.New(store.NewGorm(database.DB), cfg.Server.TemplateDir)
+	app.Spam, err = spam.GuardFromConfig(cfg.Spam)
+	if err != nil {
+		log.Fatalf("Failed to configure spam checks: %v", err)
+	}
+	limiter, err := middleware.RateLimiterFromConfig(cfg.RateLimit)
+	if err != nil {
+		log.Fatalf("Failed to configure rate limits: %v", err)
+	}
+
+	// Respondent cookies are signed like the spam stamps, so the rate limiter
+	// only gives cookies the form pages handed out a limit of their own
+	app.Respondents = respondent.New(app.Spam.Secret)
+	limiter.Respondents = app.Respondents
 
 	// Create router
 	mux := http.NewServeMux()
 
 	// Serve static files
-	fileServer := http.FileServer(http.Dir("./static"))
+	fileServer := http.FileServer(http.Dir(cfg.Server.StaticDir))
 	mux.Handle("/static/", http.StripPrefix("/static/", fileServer))
 
 	// Register handlers
-	handlers.RegisterHandlers(mux, db)
+	app.RegisterHandlers(mux)
+
+	// Report the database connection pool with the other metrics
+	app.Metrics.RegisterDBStats(database.RawDB)
 
 	// Apply middleware
-	handler := middleware.LogRequest(mux)
+	handler := middleware.LogRequest(limiter.Limit(middleware.Authenticate(app.Users, mux)))
+	handler = middleware.Measure(app.Metrics, mux, handler)
 
 	// Start server
-	fmt.Printf("Server starting on port %s...\n", port)
-	err = http.ListenAndServe(":"+port, handler)
-	if err != nil {
+	server := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      handler,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	fmt.Printf("Server starting on port %s...\n", cfg.Server.Port)
+
+	select {
+	case err := <-serverErr:
 		log.Fatalf("Server failed to start: %v", err)
+	case <-ctx.Done():
+	}
+	// A second signal stops the server at once
+	stop()
+
+	// Stop accepting requests and let the in-flight ones finish
+	log.Printf("Shutting down, waiting up to %s for requests to finish...", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to finish all requests: %v", err)
+	}
+
+	// Close the database once nothing uses it any more
+	workers.Wait()
+	if err := db.Close(); err != nil {
+		log.Printf("Failed to close database: %v", err)
 	}
+	log.Printf("Server stopped")
 }
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password accepted for an account
+const MinPasswordLength = 8
+
+// ErrInvalidCredentials is returned when an email/password pair does not match an account
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// contextKey is the type of values stored in a request context by this package
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// HashPassword returns the bcrypt hash of password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// NormalizeEmail lower-cases and trims an email address so lookups are case-insensitive
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Authenticate returns the user in users with the given email if password matches
+func Authenticate(ctx context.Context, users store.UserStore, email, password string) (*database.User, error) {
+	user, err := users.UserByEmail(ctx, NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			// Compare against a dummy hash so unknown emails take as long as wrong passwords
+			bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &user, nil
+}
+
+// dummyHash is compared against when no account matches an email
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// WithUser returns a copy of ctx carrying the logged-in user
+func WithUser(ctx context.Context, user *database.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the logged-in user, or nil for anonymous requests
+func UserFromContext(ctx context.Context) *database.User {
+	user, _ := ctx.Value(userContextKey).(*database.User)
+	return user
+}
+
+// roleRank orders roles so that higher roles include the permissions of lower ones
+var roleRank = map[string]int{
+	database.RoleViewer: 1,
+	database.RoleEditor: 2,
+	database.RoleOwner:  3,
+}
+
+// IsValidRole reports whether role is a known event role
+func IsValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// HasRole reports whether role grants at least the permissions of required
+func HasRole(role, required string) bool {
+	return role != "" && roleRank[role] >= roleRank[required]
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+const (
+	// SessionCookieName is the name of the cookie holding the session token
+	SessionCookieName = "session"
+	// SessionDuration is how long a session stays valid after login
+	SessionDuration = 7 * 24 * time.Hour
+)
+
+// hashToken returns the value stored in the database for a session token,
+// so a leaked sessions table cannot be used to log in
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// StartSession creates a session for user in users and sets the session cookie
+func StartSession(w http.ResponseWriter, r *http.Request, users store.UserStore, user *database.User) error {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return err
+	}
+	token := hex.EncodeToString(bytes)
+
+	session := database.Session{
+		ID:        hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(SessionDuration),
+	}
+	if err := users.CreateSession(r.Context(), &session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// EndSession deletes the request's session from users and clears the session cookie
+func EndSession(w http.ResponseWriter, r *http.Request, users store.UserStore) error {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return nil
+	}
+	return users.DeleteSession(r.Context(), hashToken(cookie.Value))
+}
+
+// UserFromSession returns the user in users owning the request's session cookie, or nil if there is no valid session
+func UserFromSession(r *http.Request, users store.UserStore) (*database.User, error) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, nil
+	}
+
+	user, err := users.SessionUser(r.Context(), hashToken(cookie.Value))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
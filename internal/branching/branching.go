@@ -0,0 +1,182 @@
+// Package branching works out which fields and steps of a form a respondent
+// sees, based on the form's rules and the answers given so far.
+package branching
+
+import "github.com/yourusername/event-feedback/internal/database"
+
+// Flow evaluates the rules of a form against the answers of one submission.
+// Single-page forms ignore rules and show every field on one page.
+type Flow struct {
+	form    database.Form
+	fields  []database.FormField
+	byID    map[uint]database.FormField
+	answers map[uint]string
+	last    int
+}
+
+// New returns the flow of form for a submission with the given answers.
+// fields must hold every field of the form ordered by step and field order.
+func New(form database.Form, fields []database.FormField, answers map[uint]string) *Flow {
+	f := &Flow{
+		form:    form,
+		fields:  fields,
+		byID:    make(map[uint]database.FormField, len(fields)),
+		answers: answers,
+	}
+	if f.answers == nil {
+		f.answers = map[uint]string{}
+	}
+	for _, field := range fields {
+		f.byID[field.ID] = field
+		if field.Step > f.last {
+			f.last = field.Step
+		}
+	}
+	return f
+}
+
+// SetAnswer records the answer to a field, replacing any earlier one
+func (f *Flow) SetAnswer(fieldID uint, answer string) {
+	f.answers[fieldID] = answer
+}
+
+// Answers returns the answers the flow is evaluated against
+func (f *Flow) Answers() map[uint]string {
+	return f.answers
+}
+
+// Visible reports whether field is shown to the respondent
+func (f *Flow) Visible(field database.FormField) bool {
+	if !f.form.IsMultiStep {
+		return true
+	}
+	return f.holds(field.ShowIf, true)
+}
+
+// StepFields returns the visible fields of step. For single-page forms every
+// field is returned whatever the step.
+func (f *Flow) StepFields(step int) []database.FormField {
+	var fields []database.FormField
+	for _, field := range f.fields {
+		if f.form.IsMultiStep && field.Step != step {
+			continue
+		}
+		if f.Visible(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// First returns the step respondents start on
+func (f *Flow) First() int {
+	if next := f.following(0); next != 0 {
+		return next
+	}
+	return 1
+}
+
+// Next returns the step that follows step, or 0 if the form ends after it.
+// The first matching step rule of step decides; otherwise the next step with
+// visible fields follows.
+func (f *Flow) Next(step int) int {
+	if !f.form.IsMultiStep {
+		return 0
+	}
+	for _, rule := range f.form.StepRules {
+		if rule.Step != step || !f.holds(rule.Condition, false) {
+			continue
+		}
+		// Rules only jump forward; anything else is ignored so that a
+		// respondent can never be sent in circles
+		if rule.GoTo == 0 {
+			return 0
+		}
+		if rule.GoTo > step {
+			if len(f.StepFields(rule.GoTo)) > 0 {
+				return rule.GoTo
+			}
+			return f.following(rule.GoTo)
+		}
+	}
+	return f.following(step)
+}
+
+// Prev returns the step visited before step, or step itself if it is the first
+func (f *Flow) Prev(step int) int {
+	path := f.Path()
+	for i, s := range path {
+		if s == step && i > 0 {
+			return path[i-1]
+		}
+	}
+
+	// step is not on the current path, go back to the last step before it that is
+	prev := step
+	for _, s := range path {
+		if s < step {
+			prev = s
+		}
+	}
+	return prev
+}
+
+// Path returns the steps a respondent goes through, in order, given the
+// answers so far. Steps that are not answered yet follow the default route.
+func (f *Flow) Path() []int {
+	path := []int{}
+	for step := f.First(); step != 0; step = f.Next(step) {
+		if len(path) > 0 && step <= path[len(path)-1] {
+			break
+		}
+		path = append(path, step)
+	}
+	return path
+}
+
+// Position returns the 1-based number of step on the path and the number of
+// steps on the path. A step that is not on the path counts as the last one.
+func (f *Flow) Position(step int) (int, int) {
+	path := f.Path()
+	for i, s := range path {
+		if s == step {
+			return i + 1, len(path)
+		}
+	}
+	return len(path), len(path)
+}
+
+// ShownFields returns the IDs of the visible fields on the path
+func (f *Flow) ShownFields() map[uint]bool {
+	shown := map[uint]bool{}
+	for _, step := range f.Path() {
+		for _, field := range f.StepFields(step) {
+			shown[field.ID] = true
+		}
+	}
+	return shown
+}
+
+// following returns the first step after step that has visible fields, or 0
+func (f *Flow) following(step int) int {
+	for s := step + 1; s <= f.last; s++ {
+		if len(f.StepFields(s)) > 0 {
+			return s
+		}
+	}
+	return 0
+}
+
+// holds evaluates c against the answers. Conditions on fields that no longer
+// exist evaluate to missing, so that deleting a field neither hides the fields
+// depending on it nor triggers the rules testing it.
+func (f *Flow) holds(c database.Condition, missing bool) bool {
+	if !c.IsSet() {
+		return true
+	}
+	field, ok := f.byID[c.FieldID]
+	if !ok {
+		return missing
+	}
+	return c.Matches(field.FieldType, f.answers[c.FieldID])
+}
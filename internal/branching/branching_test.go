@@ -0,0 +1,161 @@
+package branching
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+// deletedField is the ID of a field conditions refer to that no longer exists
+const deletedField = 99
+
+// testFields is a four-step form. Step 2 is only shown once the first
+// question is answered, and step 4 has a field depending on a deleted one.
+var testFields = []database.FormField{
+	field(1, 1, "radio", database.Condition{}),
+	field(2, 2, "text", database.Condition{FieldID: 1, Operator: database.OperatorIsNotEmpty}),
+	field(3, 2, "text", database.Condition{FieldID: 1, Operator: database.OperatorEquals, Operand: "yes"}),
+	field(4, 3, "number", database.Condition{}),
+	field(5, 4, "text", database.Condition{}),
+	field(6, 4, "text", database.Condition{FieldID: deletedField, Operator: database.OperatorEquals, Operand: "x"}),
+}
+
+// testRules skip to the last step when the first answer is no, and end the
+// form after step 3 when the rating is low
+var testRules = database.StepRules{
+	{Step: 1, Condition: database.Condition{FieldID: 1, Operator: database.OperatorEquals, Operand: "no"}, GoTo: 4},
+	{Step: 3, Condition: database.Condition{FieldID: 4, Operator: database.OperatorLessThan, Operand: "3"}, GoTo: 0},
+}
+
+// field returns a field of step shown when showIf holds
+func field(id uint, step int, fieldType string, showIf database.Condition) database.FormField {
+	return database.FormField{Model: gorm.Model{ID: id}, Step: step, FieldType: fieldType, ShowIf: showIf}
+}
+
+func TestFlow(t *testing.T) {
+	tests := []struct {
+		name       string
+		singlePage bool
+		rules      database.StepRules // replace testRules if set
+		answers    map[uint]string
+		wantPath   []int
+		wantShown  []uint
+	}{
+		{
+			name:      "steps without visible fields are skipped",
+			wantPath:  []int{1, 3, 4},
+			wantShown: []uint{1, 4, 5, 6},
+		},
+		{
+			name:      "conditional fields are shown",
+			answers:   map[uint]string{1: "yes"},
+			wantPath:  []int{1, 2, 3, 4},
+			wantShown: []uint{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:      "conditional fields are hidden",
+			answers:   map[uint]string{1: "maybe", 3: "Hidden answer"},
+			wantPath:  []int{1, 2, 3, 4},
+			wantShown: []uint{1, 2, 4, 5, 6},
+		},
+		{
+			name:      "rule jumps ahead",
+			answers:   map[uint]string{1: "no", 2: "Skipped", 4: "5"},
+			wantPath:  []int{1, 4},
+			wantShown: []uint{1, 5, 6},
+		},
+		{
+			name:      "rule ends the form",
+			answers:   map[uint]string{1: "yes", 4: "2", 5: "Not reached"},
+			wantPath:  []int{1, 2, 3},
+			wantShown: []uint{1, 2, 3, 4},
+		},
+		{
+			name:      "rule on a non-numeric answer does not match",
+			answers:   map[uint]string{1: "yes", 4: "two"},
+			wantPath:  []int{1, 2, 3, 4},
+			wantShown: []uint{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:      "rules jumping back are ignored",
+			rules:     database.StepRules{{Step: 3, Condition: database.Condition{FieldID: 4, Operator: database.OperatorIsNotEmpty}, GoTo: 1}},
+			answers:   map[uint]string{1: "yes", 4: "1"},
+			wantPath:  []int{1, 2, 3, 4},
+			wantShown: []uint{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:      "rule jumps to a step without visible fields",
+			rules:     database.StepRules{{Step: 1, Condition: database.Condition{FieldID: 1, Operator: database.OperatorIsEmpty}, GoTo: 2}},
+			wantPath:  []int{1, 3, 4},
+			wantShown: []uint{1, 4, 5, 6},
+		},
+		{
+			name:      "rules on deleted fields never match",
+			rules:     database.StepRules{{Step: 1, Condition: database.Condition{FieldID: deletedField, Operator: database.OperatorIsEmpty}, GoTo: 4}},
+			wantPath:  []int{1, 3, 4},
+			wantShown: []uint{1, 4, 5, 6},
+		},
+		{
+			name:       "single-page forms ignore rules and conditions",
+			singlePage: true,
+			answers:    map[uint]string{1: "no"},
+			wantPath:   []int{1},
+			wantShown:  []uint{1, 2, 3, 4, 5, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := database.Form{IsMultiStep: !tt.singlePage, StepRules: testRules}
+			if tt.rules != nil {
+				form.StepRules = tt.rules
+			}
+			flow := New(form, testFields, tt.answers)
+
+			if path := flow.Path(); fmt.Sprint(path) != fmt.Sprint(tt.wantPath) {
+				t.Errorf("got path %v, want %v", path, tt.wantPath)
+			}
+			shown := []uint{}
+			for id := range flow.ShownFields() {
+				shown = append(shown, id)
+			}
+			sort.Slice(shown, func(i, j int) bool { return shown[i] < shown[j] })
+			if fmt.Sprint(shown) != fmt.Sprint(tt.wantShown) {
+				t.Errorf("got shown fields %v, want %v", shown, tt.wantShown)
+			}
+		})
+	}
+}
+
+func TestFlowPrevAndPosition(t *testing.T) {
+	form := database.Form{IsMultiStep: true, StepRules: testRules}
+	flow := New(form, testFields, map[uint]string{1: "no"})
+
+	tests := []struct {
+		step     int
+		prev     int
+		position int
+	}{
+		{step: 1, prev: 1, position: 1},
+		{step: 4, prev: 1, position: 2},
+		// Step 3 is off the path, so going back leads to the step before it
+		{step: 3, prev: 1, position: 2},
+	}
+	for _, tt := range tests {
+		if prev := flow.Prev(tt.step); prev != tt.prev {
+			t.Errorf("Prev(%d) = %d, want %d", tt.step, prev, tt.prev)
+		}
+		if position, total := flow.Position(tt.step); position != tt.position || total != 2 {
+			t.Errorf("Position(%d) = %d, %d; want %d, 2", tt.step, position, total, tt.position)
+		}
+	}
+
+	// Answering the first question again changes the route
+	flow.SetAnswer(1, "yes")
+	if next := flow.Next(1); next != 2 {
+		t.Errorf("Next(1) = %d after changing the answer, want 2", next)
+	}
+}
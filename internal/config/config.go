@@ -0,0 +1,416 @@
+// Package config loads the server's configuration. Every setting has a
+// default, which a YAML file, then an environment variable and then a
+// command line flag can override, in that order.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/janitor"
+	"github.com/yourusername/event-feedback/internal/middleware"
+	"github.com/yourusername/event-feedback/internal/notify"
+	"github.com/yourusername/event-feedback/internal/spam"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the server's configuration
+type Config struct {
+	Server    Server                     `yaml:"server"`
+	Database  database.Config            `yaml:"database"`
+	Mail      notify.Config              `yaml:"mail"`
+	Spam      spam.Config                `yaml:"spam"`
+	RateLimit middleware.RateLimitConfig `yaml:"rate_limit"`
+	Drafts    Drafts                     `yaml:"drafts"`
+}
+
+// Server configures the HTTP server and the files it serves
+type Server struct {
+	Port        string `yaml:"port"`
+	TemplateDir string `yaml:"template_dir"` // HTML templates, including layout.html
+	StaticDir   string `yaml:"static_dir"`   // files served below /static/
+
+	// Timeouts of the HTTP server; 0 means none, except that an idle
+	// timeout of 0 uses the read timeout
+	ReadTimeout  time.Duration `yaml:"read_timeout"`  // reading a whole request, body included
+	WriteTimeout time.Duration `yaml:"write_timeout"` // handling a request and writing its response
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`  // keeping an idle keep-alive connection open
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	// when the server is asked to stop
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// Drafts configures how long abandoned submissions are kept
+type Drafts struct {
+	TTL time.Duration `yaml:"ttl"` // in-progress submissions not saved for this long are purged
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:        "8080",
+			TemplateDir: filepath.Join("internal", "templates"),
+			StaticDir:   "static",
+
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    2 * time.Minute,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: database.Config{
+			Driver:   "postgres",
+			Host:     "localhost",
+			Port:     "5432",
+			User:     "postgres",
+			Password: "postgres",
+			Name:     "event_feedback",
+			SSLMode:  "disable",
+			Path:     "event_feedback.db",
+		},
+		Mail: notify.Config{
+			Driver:         "log",
+			From:           "Event Feedback <no-reply@localhost>",
+			Dir:            "mail",
+			SMTPHost:       "localhost",
+			SMTPPort:       "1025",
+			BaseURL:        "http://localhost:8080",
+			DigestInterval: notify.DefaultDigestInterval,
+		},
+		Spam: spam.Config{
+			MinFillTime:     spam.DefaultMinFillTime,
+			CaptchaProvider: "none",
+			PowDifficulty:   spam.DefaultPowDifficulty,
+		},
+		RateLimit: middleware.RateLimitConfig{
+			PerRespondent: 30,
+			PerIP:         300,
+			PerForm:       3000,
+		},
+		Drafts: Drafts{TTL: janitor.DefaultTTL},
+	}
+}
+
+// poolDefaults are the connection pool limits of each database driver, used
+// where none are configured. SQLite is written by one connection at a time,
+// and an in-memory database is gone once its last connection closes, so a
+// few connections are always kept.
+var poolDefaults = map[string]struct{ maxOpen, maxIdle int }{
+	"postgres": {maxOpen: 100, maxIdle: 10},
+	"sqlite":   {maxOpen: 10, maxIdle: 2},
+}
+
+// setting is a value that can be set by an environment variable and a flag
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	field func(c *Config) interface{} // pointer to the value in c
+}
+
+// settings lists everything that can be set outside the config file
+var settings = []setting{
+	{"PORT", "port", "port to listen on", func(c *Config) interface{} { return &c.Server.Port }},
+	{"TEMPLATE_DIR", "template-dir", "directory of the HTML templates", func(c *Config) interface{} { return &c.Server.TemplateDir }},
+	{"STATIC_DIR", "static-dir", "directory of the static files", func(c *Config) interface{} { return &c.Server.StaticDir }},
+	{"READ_TIMEOUT", "read-timeout", "how long reading a request may take (0 for no limit)", func(c *Config) interface{} { return &c.Server.ReadTimeout }},
+	{"WRITE_TIMEOUT", "write-timeout", "how long handling a request and writing its response may take (0 for no limit)", func(c *Config) interface{} { return &c.Server.WriteTimeout }},
+	{"IDLE_TIMEOUT", "idle-timeout", "how long an idle keep-alive connection is kept open (0 for the read timeout)", func(c *Config) interface{} { return &c.Server.IdleTimeout }},
+	{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long in-flight requests may take to finish on shutdown", func(c *Config) interface{} { return &c.Server.ShutdownTimeout }},
+	{"DB_DRIVER", "db-driver", "database driver: postgres or sqlite", func(c *Config) interface{} { return &c.Database.Driver }},
+	{"DB_HOST", "db-host", "PostgreSQL host", func(c *Config) interface{} { return &c.Database.Host }},
+	{"DB_PORT", "db-port", "PostgreSQL port", func(c *Config) interface{} { return &c.Database.Port }},
+	{"DB_USER", "db-user", "PostgreSQL user", func(c *Config) interface{} { return &c.Database.User }},
+	{"DB_PASSWORD", "db-password", "PostgreSQL password", func(c *Config) interface{} { return &c.Database.Password }},
+	{"DB_NAME", "db-name", "PostgreSQL database name", func(c *Config) interface{} { return &c.Database.Name }},
+	{"DB_SSL_MODE", "db-ssl-mode", "PostgreSQL SSL mode", func(c *Config) interface{} { return &c.Database.SSLMode }},
+	{"DB_PATH", "db-path", `SQLite database file, or ":memory:"`, func(c *Config) interface{} { return &c.Database.Path }},
+	{"DB_MAX_OPEN_CONNS", "db-max-open-conns", "most open database connections (0 for the driver default)", func(c *Config) interface{} { return &c.Database.MaxOpenConns }},
+	{"DB_MAX_IDLE_CONNS", "db-max-idle-conns", "most idle database connections (0 for the driver default)", func(c *Config) interface{} { return &c.Database.MaxIdleConns }},
+	{"DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "how long a database connection is reused, such as 30m (0 for ever)", func(c *Config) interface{} { return &c.Database.ConnMaxLifetime }},
+	{"MAIL_DRIVER", "mail-driver", "how email is sent: smtp, file or log", func(c *Config) interface{} { return &c.Mail.Driver }},
+	{"MAIL_FROM", "mail-from", "sender of every email", func(c *Config) interface{} { return &c.Mail.From }},
+	{"MAIL_DIR", "mail-dir", "directory the file mail driver writes to", func(c *Config) interface{} { return &c.Mail.Dir }},
+	{"SMTP_HOST", "smtp-host", "SMTP server host", func(c *Config) interface{} { return &c.Mail.SMTPHost }},
+	{"SMTP_PORT", "smtp-port", "SMTP server port", func(c *Config) interface{} { return &c.Mail.SMTPPort }},
+	{"SMTP_USERNAME", "smtp-username", "SMTP user, if the server needs authentication", func(c *Config) interface{} { return &c.Mail.SMTPUsername }},
+	{"SMTP_PASSWORD", "smtp-password", "SMTP password", func(c *Config) interface{} { return &c.Mail.SMTPPassword }},
+	{"APP_BASE_URL", "base-url", "address the application is reachable at, for links in emails", func(c *Config) interface{} { return &c.Mail.BaseURL }},
+	{"NOTIFY_DIGEST_INTERVAL", "digest-interval", "how often digest forms email a summary", func(c *Config) interface{} { return &c.Mail.DigestInterval }},
+	{"SPAM_SECRET", "spam-secret", "secret signing form stamps, puzzles and respondent cookies (random if empty)", func(c *Config) interface{} { return &c.Spam.Secret }},
+	{"SPAM_MIN_FILL_TIME", "spam-min-fill-time", "steps sent back faster than this are suspicious", func(c *Config) interface{} { return &c.Spam.MinFillTime }},
+	{"CAPTCHA_PROVIDER", "captcha-provider", "verification before finishing a form: none, pow, hcaptcha, recaptcha or turnstile", func(c *Config) interface{} { return &c.Spam.CaptchaProvider }},
+	{"CAPTCHA_SITE_KEY", "captcha-site-key", "public key of the CAPTCHA widget", func(c *Config) interface{} { return &c.Spam.CaptchaSiteKey }},
+	{"CAPTCHA_SECRET", "captcha-secret", "secret key of the CAPTCHA provider", func(c *Config) interface{} { return &c.Spam.CaptchaSecret }},
+	{"POW_DIFFICULTY", "pow-difficulty", "leading zero bits proof-of-work hashes need, 1 to 32", func(c *Config) interface{} { return &c.Spam.PowDifficulty }},
+	{"RATE_LIMIT_PER_RESPONDENT", "rate-limit-per-respondent", "form requests per minute from a respondent (0 for no limit)", func(c *Config) interface{} { return &c.RateLimit.PerRespondent }},
+	{"RATE_LIMIT_PER_IP", "rate-limit-per-ip", "form requests per minute from an IP address (0 for no limit)", func(c *Config) interface{} { return &c.RateLimit.PerIP }},
+	{"RATE_LIMIT_PER_FORM", "rate-limit-per-form", "requests per minute to a form (0 for no limit)", func(c *Config) interface{} { return &c.RateLimit.PerForm }},
+	{"TRUST_PROXY", "trust-proxy", "take client IP addresses from the last X-Forwarded-For entry; set behind a reverse proxy", func(c *Config) interface{} { return &c.RateLimit.TrustProxy }},
+	{"DRAFT_TTL", "draft-ttl", "how long unfinished submissions are kept since last saved", func(c *Config) interface{} { return &c.Drafts.TTL }},
+}
+
+// Load returns the server's configuration given by the defaults, the YAML
+// file named by the -config flag or CONFIG_FILE, the environment and the
+// flags in args, after validating it and checking that the template and
+// static directories exist
+func Load(args []string) (Config, error) {
+	cfg, err := load(args)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.checkDirs()
+}
+
+// LoadCommand returns the configuration of subcommands, given by the
+// defaults, the config file and the environment, after validating it.
+// Subcommands serve no pages, so the template and static directories need
+// not exist.
+func LoadCommand() (Config, error) {
+	cfg, err := load(nil)
+	if err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// load returns the configuration given by the defaults, the config file, the
+// environment and the flags in args
+func load(args []string) (Config, error) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file")
+	flags := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flags[s.flag] = fs.String(s.flag, "", s.usage+" (env "+s.env+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if fs.NArg() > 0 {
+		return Config{}, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	cfg := Default()
+	if *configFile != "" {
+		if err := readFile(*configFile, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	// Environment variables override the file
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := set(s.field(&cfg), value); err != nil {
+				return Config{}, fmt.Errorf("invalid %s %q: %w", s.env, value, err)
+			}
+		}
+	}
+
+	// Flags override the environment
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for _, s := range settings {
+		if !given[s.flag] {
+			continue
+		}
+		if err := set(s.field(&cfg), *flags[s.flag]); err != nil {
+			return Config{}, fmt.Errorf("invalid -%s %q: %w", s.flag, *flags[s.flag], err)
+		}
+	}
+
+	// Pool limits left unset take the defaults of the driver in the end chosen
+	db := &cfg.Database
+	if pool, ok := poolDefaults[db.Driver]; ok {
+		if db.MaxOpenConns == 0 {
+			db.MaxOpenConns = pool.maxOpen
+		}
+		if db.MaxIdleConns == 0 {
+			db.MaxIdleConns = pool.maxIdle
+			if db.MaxIdleConns > db.MaxOpenConns {
+				db.MaxIdleConns = db.MaxOpenConns
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// readFile decodes the YAML file at path over cfg
+func readFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(cfg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// set parses value into the string, int, bool or duration field points to
+func set(field interface{}, value string) error {
+	switch field := field.(type) {
+	case *string:
+		*field = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		*field = n
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		*field = b
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("must be a duration such as 30m")
+		}
+		*field = d
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %T", field))
+	}
+	return nil
+}
+
+// Validate reports the first setting that cannot work
+func (c Config) Validate() error {
+	port, err := strconv.Atoi(c.Server.Port)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("server port must be between 1 and 65535, got %q", c.Server.Port)
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		return errors.New("server timeouts cannot be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("server shutdown timeout must be positive, got %s", c.Server.ShutdownTimeout)
+	}
+
+	db := c.Database
+	switch db.Driver {
+	case "postgres":
+		if db.Host == "" || db.Name == "" || db.User == "" {
+			return errors.New("database host, name and user are required for postgres")
+		}
+	case "sqlite":
+		if db.Path == "" {
+			return errors.New("database path is required for sqlite")
+		}
+	default:
+		return fmt.Errorf("database driver must be postgres or sqlite, got %q", db.Driver)
+	}
+	if db.MaxOpenConns < 0 || db.MaxIdleConns < 0 || db.ConnMaxLifetime < 0 {
+		return errors.New("database pool limits cannot be negative")
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		return fmt.Errorf("database max idle connections (%d) cannot exceed max open connections (%d)", db.MaxIdleConns, db.MaxOpenConns)
+	}
+
+	m := c.Mail
+	switch m.Driver {
+	case "smtp":
+		if m.SMTPHost == "" {
+			return errors.New("SMTP host is required for the smtp mail driver")
+		}
+		port, err := strconv.Atoi(m.SMTPPort)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("SMTP port must be between 1 and 65535, got %q", m.SMTPPort)
+		}
+	case "file":
+		if m.Dir == "" {
+			return errors.New("mail directory is required for the file mail driver")
+		}
+	case "log":
+	default:
+		return fmt.Errorf("mail driver must be smtp, file or log, got %q", m.Driver)
+	}
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("mail sender %q is not a valid address", m.From)
+	}
+	if u, err := url.Parse(m.BaseURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("base URL must be an http:// or https:// address, got %q", m.BaseURL)
+	}
+	if m.DigestInterval <= 0 {
+		return fmt.Errorf("digest interval must be positive, got %s", m.DigestInterval)
+	}
+
+	sp := c.Spam
+	if sp.MinFillTime < 0 {
+		return fmt.Errorf("spam minimum fill time cannot be negative, got %s", sp.MinFillTime)
+	}
+	switch sp.CaptchaProvider {
+	case "", "none":
+	case "pow":
+		if sp.PowDifficulty < 1 || sp.PowDifficulty > 32 {
+			return fmt.Errorf("proof-of-work difficulty must be between 1 and 32, got %d", sp.PowDifficulty)
+		}
+	case "hcaptcha", "recaptcha", "turnstile":
+		if sp.CaptchaSiteKey == "" || sp.CaptchaSecret == "" {
+			return fmt.Errorf("CAPTCHA site key and secret are required for %s", sp.CaptchaProvider)
+		}
+	default:
+		return fmt.Errorf("CAPTCHA provider must be none, pow, hcaptcha, recaptcha or turnstile, got %q", sp.CaptchaProvider)
+	}
+
+	rl := c.RateLimit
+	if rl.PerRespondent < 0 || rl.PerIP < 0 || rl.PerForm < 0 {
+		return errors.New("rate limits cannot be negative")
+	}
+	if c.Drafts.TTL <= 0 {
+		return fmt.Errorf("draft TTL must be positive, got %s", c.Drafts.TTL)
+	}
+	return nil
+}
+
+// checkDirs reports a template or static directory the server cannot serve from
+func (c Config) checkDirs() error {
+	if _, err := os.Stat(filepath.Join(c.Server.TemplateDir, "layout.html")); err != nil {
+		return fmt.Errorf("template directory %q has no layout.html", c.Server.TemplateDir)
+	}
+	if info, err := os.Stat(c.Server.StaticDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("static directory %q does not exist", c.Server.StaticDir)
+	}
+	return nil
+}
+
+// Redacted returns a copy of c with its secrets replaced, safe to print
+func (c Config) Redacted() Config {
+	for _, secret := range []*string{&c.Database.Password, &c.Mail.SMTPPassword, &c.Spam.Secret, &c.Spam.CaptchaSecret} {
+		if *secret != "" {
+			*secret = "REDACTED"
+		}
+	}
+	return c
+}
+
+// String returns c as YAML with its secrets redacted
+func (c Config) String() string {
+	var buf strings.Builder
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return buf.String()
+}
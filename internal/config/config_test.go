@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every environment variable the configuration reads for the
+// duration of the test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range append([]string{"CONFIG_FILE"}, settingEnvs()...) {
+		if value, ok := os.LookupEnv(name); ok {
+			os.Unsetenv(name)
+			t.Cleanup(func() { os.Setenv(name, value) })
+		}
+	}
+}
+
+func settingEnvs() []string {
+	names := make([]string, len(settings))
+	for i, s := range settings {
+		names[i] = s.env
+	}
+	return names
+}
+
+const testFile = `
+server:
+  port: "9000"
+  read_timeout: 10s
+database:
+  driver: sqlite
+  path: file.db
+spam:
+  secret: from-file
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	// Each layer overrides the one before it: defaults, file, environment, flags
+	tests := []struct {
+		name        string
+		file        bool
+		env         map[string]string
+		args        []string
+		port        string
+		readTimeout time.Duration
+		driver      string
+		path        string
+		maxOpen     int
+		maxIdle     int
+		secret      string
+	}{
+		{
+			name: "defaults",
+			port: "8080", readTimeout: 30 * time.Second, driver: "postgres", path: "event_feedback.db",
+			maxOpen: 100, maxIdle: 10,
+		},
+		{
+			name: "file over defaults",
+			file: true,
+			port: "9000", readTimeout: 10 * time.Second, driver: "sqlite", path: "file.db",
+			maxOpen: 10, maxIdle: 2, secret: "from-file",
+		},
+		{
+			name: "environment over file",
+			file: true,
+			env:  map[string]string{"PORT": "9100", "DB_PATH": "env.db", "DB_MAX_OPEN_CONNS": "1", "SPAM_SECRET": "from-env"},
+			port: "9100", readTimeout: 10 * time.Second, driver: "sqlite", path: "env.db",
+			maxOpen: 1, maxIdle: 1, secret: "from-env",
+		},
+		{
+			name: "flags over environment",
+			file: true,
+			env:  map[string]string{"PORT": "9100", "DB_PATH": "env.db", "DB_MAX_OPEN_CONNS": "1", "SPAM_SECRET": "from-env"},
+			args: []string{"-port", "9200", "-db-path", "flag.db", "-db-max-open-conns", "5", "-spam-secret", "from-flag"},
+			port: "9200", readTimeout: 10 * time.Second, driver: "sqlite", path: "flag.db",
+			maxOpen: 5, maxIdle: 2, secret: "from-flag",
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			if tt.file {
+				t.Setenv("CONFIG_FILE", path)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := load(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			db := cfg.Database
+			if cfg.Server.Port != tt.port || cfg.Server.ReadTimeout != tt.readTimeout || db.Driver != tt.driver || db.Path != tt.path ||
+				db.MaxOpenConns != tt.maxOpen || db.MaxIdleConns != tt.maxIdle || cfg.Spam.Secret != tt.secret {
+				t.Errorf("got port %s, read timeout %s, driver %s, path %s, pool %d/%d, secret %q; want %s, %s, %s, %s, %d/%d, %q",
+					cfg.Server.Port, cfg.Server.ReadTimeout, db.Driver, db.Path, db.MaxOpenConns, db.MaxIdleConns, cfg.Spam.Secret,
+					tt.port, tt.readTimeout, tt.driver, tt.path, tt.maxOpen, tt.maxIdle, tt.secret)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  prot: \"9000\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{name: "unknown file setting", args: []string{"-config", path}, want: "prot"},
+		{name: "invalid environment value", env: map[string]string{"DB_MAX_OPEN_CONNS": "many"}, want: "DB_MAX_OPEN_CONNS"},
+		{name: "invalid flag value", args: []string{"-read-timeout", "soon"}, want: "-read-timeout"},
+		{name: "unexpected argument", args: []string{"serve"}, want: "serve"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if _, err := load(tt.args); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "db-password"
+	cfg.Mail.SMTPPassword = "smtp-password"
+	cfg.Spam.Secret = "spam-secret"
+	cfg.Spam.CaptchaSecret = ""
+
+	out := cfg.String()
+	for _, secret := range []string{"db-password", "smtp-password", "spam-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("printed configuration holds %q:\n%s", secret, out)
+		}
+	}
+
+	redacted := cfg.Redacted()
+	if redacted.Database.Password != "REDACTED" || redacted.Spam.Secret != "REDACTED" {
+		t.Errorf("got password %q and secret %q, want them redacted", redacted.Database.Password, redacted.Spam.Secret)
+	}
+	// Unset secrets stay empty, so the output shows they are missing
+	if redacted.Spam.CaptchaSecret != "" {
+		t.Errorf("got CAPTCHA secret %q, want it left empty", redacted.Spam.CaptchaSecret)
+	}
+	if cfg.Database.Password != "db-password" {
+		t.Error("Redacted changed the original configuration")
+	}
+}
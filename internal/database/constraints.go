@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// MaxPatternLength is the longest regular expression a field may be given
+const MaxPatternLength = 500
+
+// FieldConstraints holds the optional limits an answer to a field must respect.
+// Which limits apply depends on the field type: Min and Max for number fields,
+// MinLength, MaxLength and Pattern for text, textarea and email fields, and
+// MinSelections and MaxSelections for checkbox fields.
+type FieldConstraints struct {
+	Min           *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max           *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+	MinLength     *int     `json:"min_length,omitempty" yaml:"min_length,omitempty"`
+	MaxLength     *int     `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	Pattern       string   `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	MinSelections *int     `json:"min_selections,omitempty" yaml:"min_selections,omitempty"`
+	MaxSelections *int     `json:"max_selections,omitempty" yaml:"max_selections,omitempty"`
+}
+
+// GormDBDataType stores constraints as jsonb on PostgreSQL and JSON text elsewhere
+func (FieldConstraints) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+// Value implements driver.Valuer
+func (c FieldConstraints) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (c *FieldConstraints) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*c = FieldConstraints{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into FieldConstraints", value)
+	}
+
+	var constraints FieldConstraints
+	if err := json.Unmarshal(b, &constraints); err != nil {
+		return fmt.Errorf("invalid field constraints: %w", err)
+	}
+	*c = constraints
+	return nil
+}
+
+// NormalizeFieldConstraints checks the constraints of a field of the given type
+// and returns them with every limit that does not apply to the type removed
+func NormalizeFieldConstraints(fieldType string, c FieldConstraints) (FieldConstraints, error) {
+	var normalized FieldConstraints
+
+	switch fieldType {
+	case "number":
+		if c.Min != nil && c.Max != nil && *c.Min > *c.Max {
+			return normalized, fmt.Errorf("the minimum cannot be greater than the maximum")
+		}
+		normalized.Min, normalized.Max = c.Min, c.Max
+
+	case "text", "textarea", "email":
+		if err := checkRange(c.MinLength, c.MaxLength, "length"); err != nil {
+			return normalized, err
+		}
+		if len(c.Pattern) > MaxPatternLength {
+			return normalized, fmt.Errorf("the pattern can be at most %d characters", MaxPatternLength)
+		}
+		if c.Pattern != "" {
+			if _, err := regexp.Compile(c.Pattern); err != nil {
+				return normalized, fmt.Errorf("the pattern is not a valid regular expression")
+			}
+		}
+		normalized.MinLength, normalized.MaxLength, normalized.Pattern = c.MinLength, c.MaxLength, c.Pattern
+
+	case "checkbox":
+		if err := checkRange(c.MinSelections, c.MaxSelections, "number of selections"); err != nil {
+			return normalized, err
+		}
+		normalized.MinSelections, normalized.MaxSelections = c.MinSelections, c.MaxSelections
+	}
+
+	return normalized, nil
+}
+
+// checkRange checks an optional non-negative minimum and maximum
+func checkRange(min, max *int, name string) error {
+	if (min != nil && *min < 0) || (max != nil && *max < 0) {
+		return fmt.Errorf("the %s limits cannot be negative", name)
+	}
+	if min != nil && max != nil && *min > *max {
+		return fmt.Errorf("the minimum %s cannot be greater than the maximum", name)
+	}
+	return nil
+}
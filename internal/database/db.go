@@ -3,11 +3,8 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"os"
 	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -17,55 +14,58 @@ var DB *gorm.DB
 // RawDB is the underlying sql.DB connection
 var RawDB *sql.DB
 
-// InitDB initializes the database connection
-func InitDB() (*sql.DB, error) {
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbName := getEnv("DB_NAME", "event_feedback")
-	sslMode := getEnv("DB_SSL_MODE", "disable")
-
-	// First connect to PostgreSQL server without specifying a database
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s sslmode=%s",
-		dbHost, dbPort, dbUser, dbPassword, sslMode,
-	)
-
-	// Connect to PostgreSQL server
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to PostgreSQL server: %w", err)
-	}
-	defer db.Close()
+// Config describes the database to connect to
+type Config struct {
+	Driver string `yaml:"driver"` // postgres or sqlite
+
+	// PostgreSQL connection settings
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"ssl_mode"`
+
+	// SQLite database file, or ":memory:" for a database that only lives
+	// as long as the process
+	Path string `yaml:"path"`
+
+	// Connection pool limits; 0 leaves database/sql's default. The config
+	// package fills in defaults for each driver.
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"` // 0 keeps connections open indefinitely
+}
+
+// drivers open a database of each supported kind, preparing it first if the
+// kind of database needs that
+var drivers = map[string]func(cfg Config, gormConfig *gorm.Config) (*gorm.DB, error){
+	"postgres": openPostgres,
+	"sqlite":   openSQLite,
+}
 
-	// Check if database exists
-	var exists bool
-	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = $1)", dbName).Scan(&exists)
+// InitDB initializes the database connection and applies pending migrations
+func InitDB(cfg Config) (*sql.DB, error) {
+	gormDB, err := Connect(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if database exists: %w", err)
+		return nil, err
 	}
 
-	// Create database if it doesn't exist
-	if !exists {
-		_, err = db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbName))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create database: %w", err)
-		}
+	// Run migrations
+	err = MigrateUp(gormDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	// Now connect to the specific database with proper DSN format
-	dsn = fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		dbHost, dbPort, dbUser, dbPassword, dbName, sslMode,
-	)
+	return RawDB, nil
+}
 
-	// Connect using GORM with proper configuration
-	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		PrepareStmt: true,
-	})
+// Connect opens the database described by cfg as the global connection,
+// creating it if needed, without touching the schema
+func Connect(cfg Config) (*gorm.DB, error) {
+	gormDB, err := Open(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 	DB = gormDB
 
@@ -76,123 +76,40 @@ func InitDB() (*sql.DB, error) {
 	}
 	RawDB = sqlDB
 
-	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-
-	// Run migrations
-	err = runMigrations(gormDB)
-	if err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	return sqlDB, nil
+	return gormDB, nil
 }
 
-// runMigrations creates tables if they don't exist
-func runMigrations(db *gorm.DB) error {
-	// Drop existing tables in reverse order of dependencies
-	tables := []string{
-		"submission_responses",
-		"submissions",
-		"form_fields",
-		"forms",
-		"events",
+// Open opens the database described by cfg without touching the schema or
+// the global connection
+func Open(cfg Config) (*gorm.DB, error) {
+	open, ok := drivers[cfg.Driver]
+	if !ok {
+		return nil, fmt.Errorf("unknown DB_DRIVER %q: must be postgres or sqlite", cfg.Driver)
 	}
-
-	for _, table := range tables {
-		err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", table)).Error
-		if err != nil {
-			return fmt.Errorf("failed to drop table %s: %w", table, err)
-		}
-	}
-
-	// Auto migrate all models
-	err := db.AutoMigrate(
-		&Event{},
-		&Form{},
-		&FormField{},
-		&Submission{},
-		&SubmissionResponse{},
-	)
+	gormDB, err := open(cfg, &gorm.Config{
+		PrepareStmt: true,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		return nil, err
 	}
 
-	// Seed test data
-	err = seedTestData(db)
+	// Apply the configured pool limits
+	sqlDB, err := gormDB.DB()
 	if err != nil {
-		return fmt.Errorf("failed to seed test data: %w", err)
-	}
-
-	return nil
-}
-
-// seedTestData adds some test data to the database
-func seedTestData(db *gorm.DB) error {
-	// Create a test event
-	event := Event{
-		Name:        "Test Event",
-		Description: "This is a test event for the feedback system",
-		Date:        time.Now().AddDate(0, 1, 0), // One month from now
-	}
-	result := db.Create(&event)
-	if result.Error != nil {
-		return fmt.Errorf("failed to create test event: %w", result.Error)
-	}
-
-	// Create a test form
-	form := Form{
-		EventID:     event.ID,
-		Title:       "Test Feedback Form",
-		IsMultiStep: false,
-		IsPublished: true,
-	}
-	result = db.Create(&form)
-	if result.Error != nil {
-		return fmt.Errorf("failed to create test form: %w", result.Error)
+		return nil, fmt.Errorf("failed to get DB instance: %w", err)
 	}
-
-	// Create some test form fields
-	fields := []FormField{
-		{
-			FormID:     form.ID,
-			FieldType:  "text",
-			Label:      "What did you like most about the event?",
-			IsRequired: true,
-			FieldOrder: 1,
-		},
-		{
-			FormID:     form.ID,
-			FieldType:  "textarea",
-			Label:      "Do you have any suggestions for improvement?",
-			IsRequired: false,
-			FieldOrder: 2,
-		},
-		{
-			FormID:     form.ID,
-			FieldType:  "select",
-			Label:      "How would you rate the event?",
-			Options:    `["Excellent","Good","Average","Poor"]`,
-			IsRequired: true,
-			FieldOrder: 3,
-		},
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 	}
-
-	for _, field := range fields {
-		result = db.Create(&field)
-		if result.Error != nil {
-			return fmt.Errorf("failed to create test form field: %w", result.Error)
-		}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 	}
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
-	return nil
+	return gormDB, nil
 }
 
-// Helper function to get environment variables with fallback
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return fallback
+// isPostgres reports whether db is a PostgreSQL database
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
 }
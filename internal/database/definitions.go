@@ -0,0 +1,90 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// FormDefinition is everything needed to recreate a form: its settings, its
+// fields in order and its branching rules. Fields are referred to by their
+// Ref rather than their database ID, so definitions are stable across
+// databases and can be kept in version control.
+type FormDefinition struct {
+	Slug        string               `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Title       string               `json:"title" yaml:"title"`
+	IsMultiStep bool                 `json:"is_multi_step" yaml:"is_multi_step"`
+	Fields      []FieldDefinition    `json:"fields" yaml:"fields"`
+	StepRules   []DefinitionStepRule `json:"step_rules,omitempty" yaml:"step_rules,omitempty"`
+}
+
+// FieldDefinition describes a form field. Fields of the same step keep the order they
+// are listed in.
+type FieldDefinition struct {
+	Ref         string               `json:"ref" yaml:"ref"`
+	Step        int                  `json:"step" yaml:"step"`
+	FieldType   string               `json:"field_type" yaml:"field_type"`
+	Label       string               `json:"label" yaml:"label"`
+	Placeholder string               `json:"placeholder,omitempty" yaml:"placeholder,omitempty"`
+	Options     FieldOptions         `json:"options,omitempty" yaml:"options,omitempty"`
+	Constraints FieldConstraints     `json:"constraints" yaml:"constraints,omitempty"`
+	IsRequired  bool                 `json:"is_required" yaml:"is_required"`
+	ShowIf      *DefinitionCondition `json:"show_if,omitempty" yaml:"show_if,omitempty"`
+}
+
+// DefinitionCondition is a branching condition on the field with the given Ref
+type DefinitionCondition struct {
+	Field    string `json:"field" yaml:"field"`
+	Operator string `json:"operator" yaml:"operator"`
+	Value    string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// DefinitionStepRule is a branching rule between steps; see StepRule
+type DefinitionStepRule struct {
+	Step      int                 `json:"step" yaml:"step"`
+	Condition DefinitionCondition `json:"condition" yaml:"condition"`
+	GoTo      int                 `json:"go_to" yaml:"go_to"`
+}
+
+// GormDBDataType stores definitions as jsonb on PostgreSQL and JSON text elsewhere
+func (FormDefinition) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+// Value implements driver.Valuer
+func (d FormDefinition) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (d *FormDefinition) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*d = FormDefinition{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into FormDefinition", value)
+	}
+
+	var definition FormDefinition
+	if err := json.Unmarshal(b, &definition); err != nil {
+		return fmt.Errorf("invalid form definition: %w", err)
+	}
+	*d = definition
+	return nil
+}
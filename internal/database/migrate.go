@@ -0,0 +1,212 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockID is the PostgreSQL advisory lock key held while migrations run
+const migrationLockID = 7346102935
+
+// Migration is a single numbered schema change. Up and Down are run inside
+// the same transaction that records the change in schema_migrations.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+	// UpSQLite, if set, is run instead of Up on SQLite. Migrations released
+	// before SQLite was supported keep their Up as it was applied to
+	// PostgreSQL databases, and use it where Up holds PostgreSQL-only SQL.
+	UpSQLite func(tx *gorm.DB) error
+}
+
+// SchemaMigration records a migration that has been applied
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for SchemaMigration
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationState describes a known migration and whether it has been applied
+type MigrationState struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrations returns all known migrations ordered by version
+func Migrations() []Migration {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	return sorted
+}
+
+// MigrateUp applies all pending migrations in order
+func MigrateUp(db *gorm.DB) error {
+	return withMigrationLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range Migrations() {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+
+			up := m.Up
+			if m.UpSQLite != nil && !isPostgres(conn) {
+				up = m.UpSQLite
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{
+					Version:   m.Version,
+					Name:      m.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// MigrateDown reverts the given number of most recently applied migrations,
+// and returns how many it reverted, which is fewer if fewer were applied
+func MigrateDown(db *gorm.DB, steps int) (int, error) {
+	reverted := 0
+	err := withMigrationLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		all := Migrations()
+		for i := len(all) - 1; i >= 0 && reverted < steps; i-- {
+			m := all[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if m.Down == nil {
+					return fmt.Errorf("migration is irreversible")
+				}
+				if err := m.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, m.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			reverted++
+		}
+
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatus reports every known migration and whether it has been applied
+func MigrationStatus(db *gorm.DB) ([]MigrationState, error) {
+	var states []MigrationState
+	err := withMigrationLock(db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range Migrations() {
+			state := MigrationState{Version: m.Version, Name: m.Name}
+			if record, ok := applied[m.Version]; ok {
+				state.Applied = true
+				state.AppliedAt = record.AppliedAt
+			}
+			states = append(states, state)
+		}
+		return nil
+	})
+	return states, err
+}
+
+// withMigrationLock runs fn on a single connection holding the migration
+// advisory lock, so concurrently starting replicas apply migrations one at a
+// time. SQLite databases belong to a single server and need no lock.
+func withMigrationLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		// Start every query on the connection from a clean statement, so
+		// tables and conditions of one query do not leak into the next
+		conn = conn.Session(&gorm.Session{NewDB: true})
+
+		if isPostgres(conn) {
+			err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error
+			if err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+		}
+
+		err := conn.AutoMigrate(&SchemaMigration{})
+		if err != nil {
+			return fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+
+		return fn(conn)
+	})
+}
+
+// appliedMigrations returns the applied migrations keyed by version
+func appliedMigrations(db *gorm.DB) (map[int]SchemaMigration, error) {
+	var records []SchemaMigration
+	err := db.Order("version").Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	applied := make(map[int]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// PendingMigrations returns how many known migrations have not been applied.
+// Unlike MigrationStatus it takes no lock, so it answers while another
+// replica is migrating.
+func PendingMigrations(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return len(Migrations()), nil
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, m := range Migrations() {
+		if _, ok := applied[m.Version]; !ok {
+			pending++
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// openTestDB returns a new in-memory SQLite database with every migration applied
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := Open(Config{Driver: "sqlite", Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// models are the records the migrations make tables for
+var models = []interface{}{
+	&Event{}, &Form{}, &FormField{}, &Submission{}, &SubmissionResponse{},
+	&User{}, &Session{}, &EventMember{}, &OutboxEmail{}, &Webhook{},
+	&WebhookDelivery{}, &WebhookAttempt{}, &FormTemplate{}, &FormVersion{},
+}
+
+func TestMigrateUpOnSQLite(t *testing.T) {
+	db := openTestDB(t)
+
+	// The migrations leave a column for every field of every model
+	m := db.Migrator()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		if !m.HasTable(model) {
+			t.Errorf("no table %s", stmt.Schema.Table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !m.HasColumn(model, field.DBName) {
+				t.Errorf("table %s has no column %s", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+
+	// Applying them again changes nothing
+	if err := MigrateUp(db); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if pending, err := PendingMigrations(db); err != nil || pending != 0 {
+		t.Errorf("got %d pending migrations, %v; want none", pending, err)
+	}
+}
+
+// appliedVersions returns the versions recorded in schema_migrations, in order
+func appliedVersions(t *testing.T, db *gorm.DB) []uint {
+	t.Helper()
+	var versions []uint
+	if err := db.Model(&SchemaMigration{}).Order("version").Pluck("version", &versions).Error; err != nil {
+		t.Fatal(err)
+	}
+	return versions
+}
+
+func TestMigrateDownAndUpAgain(t *testing.T) {
+	db := openTestDB(t)
+	all := Migrations()
+
+	// Reverting to version 2 turns field options back into text
+	event := Event{Name: "Conference", Date: time.Now()}
+	must(t, db.Create(&event).Error)
+	form := Form{EventID: event.ID, Slug: "feedback", Title: "Feedback"}
+	must(t, db.Create(&form).Error)
+	plain := FormField{FormID: form.ID, Ref: "name", FieldType: "text", Label: "Name", FieldOrder: 1}
+	must(t, db.Create(&plain).Error)
+	choice := FormField{FormID: form.ID, Ref: "rating", FieldType: "radio", Label: "Rating", FieldOrder: 2,
+		Options: FieldOptions{{Value: "good", Label: "Good"}, {Value: "bad", Label: "Bad"}}}
+	must(t, db.Create(&choice).Error)
+
+	reverted, err := MigrateDown(db, len(all)-2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted != len(all)-2 {
+		t.Errorf("reverted %d migrations, want %d", reverted, len(all)-2)
+	}
+	if got := fmt.Sprint(appliedVersions(t, db)); got != "[1 2]" {
+		t.Errorf("got applied versions %s, want [1 2]", got)
+	}
+	var options []sql.NullString
+	must(t, db.Table("form_fields").Order("id").Pluck("options", &options).Error)
+	want := []sql.NullString{{String: "", Valid: true}, {String: `["good","bad"]`, Valid: true}}
+	if fmt.Sprint(options) != fmt.Sprint(want) {
+		t.Errorf("got options %v, want %v", options, want)
+	}
+
+	// Applying the migrations again converts them back
+	must(t, MigrateUp(db))
+	var fields []FormField
+	must(t, db.Order("id").Find(&fields).Error)
+	if len(fields) != 2 || len(fields[0].Options) != 0 || fmt.Sprint(fields[1].Options) != "[{good good} {bad bad}]" {
+		t.Errorf("got fields %+v after migrating up again", fields)
+	}
+
+	// Reverting more migrations than are applied reverts all of them
+	reverted, err = MigrateDown(db, len(all)+5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted != len(all) {
+		t.Errorf("reverted %d migrations, want %d", reverted, len(all))
+	}
+	if versions := appliedVersions(t, db); len(versions) != 0 {
+		t.Errorf("got applied versions %v, want none", versions)
+	}
+	if db.Migrator().HasTable(&Form{}) {
+		t.Error("forms table left after reverting every migration")
+	}
+
+	must(t, MigrateUp(db))
+	if versions := appliedVersions(t, db); len(versions) != len(all) {
+		t.Errorf("got %d applied versions, want %d", len(versions), len(all))
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,793 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrations lists every schema change in the order it must be applied.
+// Migrations use their own snapshot structs rather than the models in
+// models.go so that later model changes never alter what an old migration does.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_initial_schema",
+		Up: func(tx *gorm.DB) error {
+			// Databases created before versioned migrations already have these
+			// tables, so only create the ones that are missing
+			for _, table := range []interface{}{
+				&v1Event{},
+				&v1Form{},
+				&v1FormField{},
+				&v1Submission{},
+				&v1SubmissionResponse{},
+			} {
+				if tx.Migrator().HasTable(table) {
+					continue
+				}
+				if err := tx.Migrator().CreateTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(
+				&v1SubmissionResponse{},
+				&v1Submission{},
+				&v1FormField{},
+				&v1Form{},
+				&v1Event{},
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_users_sessions_and_event_members",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&v2User{}, &v2Session{}, &v2EventMember{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v2EventMember{}, &v2Session{}, &v2User{})
+		},
+	},
+	{
+		Version: 3,
+		Name:    "convert_field_options_to_json",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.AddColumn(&v3FormField{}, "OptionsJSON"); err != nil {
+				return err
+			}
+
+			// Parse the old free-form strings into value/label pairs
+			var fields []v3FormField
+			if err := tx.Select("id, options").Find(&fields).Error; err != nil {
+				return err
+			}
+			for _, field := range fields {
+				options, err := json.Marshal(legacyFieldOptions(field.Options))
+				if err != nil {
+					return err
+				}
+				err = tx.Model(&v3FormField{}).Where("id = ?", field.ID).
+					Update("options_json", string(options)).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := m.DropColumn(&v3FormField{}, "Options"); err != nil {
+				return err
+			}
+			return m.RenameColumn(&v3FormField{}, "options_json", "options")
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.AddColumn(&v3FormField{}, "OptionsText"); err != nil {
+				return err
+			}
+
+			// Store the option values as a JSON array string, as the seed data used to
+			var fields []v3FormField
+			if err := tx.Select("id, options").Find(&fields).Error; err != nil {
+				return err
+			}
+			for _, field := range fields {
+				var options []v3FieldOption
+				if err := json.Unmarshal([]byte(field.Options), &options); err != nil {
+					return err
+				}
+				// Fields without options held an empty string rather than NULL
+				text := ""
+				if len(options) > 0 {
+					values := make([]string, len(options))
+					for i, option := range options {
+						values[i] = option.Value
+					}
+					encoded, err := json.Marshal(values)
+					if err != nil {
+						return err
+					}
+					text = string(encoded)
+				}
+				err := tx.Model(&v3FormField{}).Where("id = ?", field.ID).
+					Update("options_text", text).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := m.DropColumn(&v3FormField{}, "Options"); err != nil {
+				return err
+			}
+			return m.RenameColumn(&v3FormField{}, "options_text", "options")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_field_constraints",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&v4FormField{}, "Constraints")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&v4FormField{}, "Constraints")
+		},
+	},
+	{
+		Version: 5,
+		Name:    "encode_checkbox_answers_as_json",
+		Up: func(tx *gorm.DB) error {
+			// Checkbox answers used to be comma-separated text
+			var responses []v5SubmissionResponse
+			err := checkboxResponses(tx).Where("submission_responses.response NOT LIKE ?", "[%").Find(&responses).Error
+			if err != nil {
+				return err
+			}
+			for _, resp := range responses {
+				values := []string{}
+				for _, value := range strings.Split(resp.Response, ",") {
+					if value = strings.TrimSpace(value); value != "" {
+						values = append(values, value)
+					}
+				}
+				encoded := ""
+				if len(values) > 0 {
+					b, err := json.Marshal(values)
+					if err != nil {
+						return err
+					}
+					encoded = string(b)
+				}
+				err := tx.Model(&v5SubmissionResponse{}).Where("id = ?", resp.ID).Update("response", encoded).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			var responses []v5SubmissionResponse
+			err := checkboxResponses(tx).Where("submission_responses.response LIKE ?", "[%").Find(&responses).Error
+			if err != nil {
+				return err
+			}
+			for _, resp := range responses {
+				var values []string
+				if err := json.Unmarshal([]byte(resp.Response), &values); err != nil {
+					continue
+				}
+				err := tx.Model(&v5SubmissionResponse{}).Where("id = ?", resp.ID).
+					Update("response", strings.Join(values, ",")).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 6,
+		Name:    "unique_response_per_submission_field",
+		Up: func(tx *gorm.DB) error {
+			// Keep only the latest answer to each field, preferring ones that were not deleted
+			err := tx.Exec(`DELETE FROM submission_responses WHERE id NOT IN (
+				SELECT COALESCE(MAX(CASE WHEN deleted_at IS NULL THEN id END), MAX(id))
+				FROM submission_responses
+				GROUP BY submission_id, field_id
+			)`).Error
+			if err != nil {
+				return err
+			}
+			return tx.Migrator().CreateIndex(&v6SubmissionResponse{}, "idx_submission_responses_submission_field")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropIndex(&v6SubmissionResponse{}, "idx_submission_responses_submission_field")
+		},
+	},
+	{
+		Version: 7,
+		Name:    "add_branching_rules",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&v7FormField{}, "ShowIf"); err != nil {
+				return err
+			}
+			return tx.Migrator().AddColumn(&v7Form{}, "StepRules")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&v7Form{}, "StepRules"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&v7FormField{}, "ShowIf")
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add_notifications_and_outbox",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range []string{"NotifyMode", "SendReceipts", "LastDigestAt"} {
+				if err := m.AddColumn(&v8Form{}, column); err != nil {
+					return err
+				}
+			}
+			return m.CreateTable(&v8OutboxEmail{})
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropTable(&v8OutboxEmail{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"LastDigestAt", "SendReceipts", "NotifyMode"} {
+				if err := m.DropColumn(&v8Form{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 9,
+		Name:    "create_webhooks",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&v9Webhook{}, &v9WebhookDelivery{}, &v9WebhookAttempt{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v9WebhookAttempt{}, &v9WebhookDelivery{}, &v9Webhook{})
+		},
+	},
+	{
+		Version: 10,
+		Name:    "create_form_templates",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&v10FormTemplate{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v10FormTemplate{})
+		},
+	},
+	{
+		Version: 11,
+		Name:    "add_form_slugs_and_field_refs",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&v11Form{}, "Slug"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().AddColumn(&v11FormField{}, "Ref"); err != nil {
+				return err
+			}
+
+			// Derive slugs from titles and refs from labels, numbering duplicates
+			var forms []v11Form
+			if err := tx.Unscoped().Order("id").Find(&forms).Error; err != nil {
+				return err
+			}
+			slugs := map[uint]map[string]bool{}
+			for _, form := range forms {
+				if slugs[form.EventID] == nil {
+					slugs[form.EventID] = map[string]bool{}
+				}
+				slug := v11UniqueSlug(slugs[form.EventID], Slugify(form.Title), "form")
+				if err := tx.Model(&form).Update("slug", slug).Error; err != nil {
+					return err
+				}
+			}
+
+			var fields []v11FormField
+			if err := tx.Unscoped().Order("id").Find(&fields).Error; err != nil {
+				return err
+			}
+			refs := map[uint]map[string]bool{}
+			for _, field := range fields {
+				if refs[field.FormID] == nil {
+					refs[field.FormID] = map[string]bool{}
+				}
+				ref := v11UniqueSlug(refs[field.FormID], Slugify(field.Label), "field")
+				if err := tx.Model(&field).Update("ref", ref).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Exec("CREATE UNIQUE INDEX idx_forms_event_slug ON forms (event_id, slug) WHERE deleted_at IS NULL").Error; err != nil {
+				return err
+			}
+			return tx.Exec("CREATE UNIQUE INDEX idx_form_fields_form_ref ON form_fields (form_id, ref) WHERE deleted_at IS NULL").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&v11FormField{}, "Ref"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&v11Form{}, "Slug")
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_form_versions",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.CreateTable(&v12FormVersion{}); err != nil {
+				return err
+			}
+			if err := m.AddColumn(&v12Form{}, "PublishedVersionID"); err != nil {
+				return err
+			}
+			if err := m.CreateIndex(&v12Form{}, "PublishedVersionID"); err != nil {
+				return err
+			}
+			if err := m.AddColumn(&v12Submission{}, "FormVersionID"); err != nil {
+				return err
+			}
+			if err := m.CreateIndex(&v12Submission{}, "FormVersionID"); err != nil {
+				return err
+			}
+
+			// Forms that are published or have submissions get a first version
+			// holding their current fields, which their submissions are tied to
+			err := tx.Exec(`INSERT INTO form_versions (created_at, updated_at, form_id, number, title, is_multi_step, step_rules, fields)
+				SELECT NOW(), NOW(), forms.id, 1, forms.title, forms.is_multi_step, forms.step_rules, COALESCE((
+					SELECT jsonb_agg(jsonb_build_object(
+						'id', form_fields.id, 'ref', form_fields.ref, 'step', form_fields.step,
+						'field_type', form_fields.field_type, 'label', form_fields.label,
+						'placeholder', form_fields.placeholder, 'options', form_fields.options,
+						'constraints', form_fields.constraints, 'is_required', form_fields.is_required,
+						'show_if', form_fields.show_if, 'field_order', form_fields.field_order
+					) ORDER BY form_fields.step, form_fields.field_order)
+					FROM form_fields
+					WHERE form_fields.form_id = forms.id AND form_fields.deleted_at IS NULL
+				), '[]')
+				FROM forms
+				WHERE forms.deleted_at IS NULL
+					AND (forms.is_published OR EXISTS (SELECT 1 FROM submissions WHERE submissions.form_id = forms.id))`).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Exec(`UPDATE forms SET published_version_id = form_versions.id
+				FROM form_versions
+				WHERE form_versions.form_id = forms.id AND forms.is_published`).Error
+			if err != nil {
+				return err
+			}
+			return tx.Exec(`UPDATE submissions SET form_version_id = form_versions.id
+				FROM form_versions
+				WHERE form_versions.form_id = submissions.form_id`).Error
+		},
+		// SQLite databases were only supported after this migration, so they
+		// never hold forms to backfill versions for
+		UpSQLite: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.CreateTable(&v12FormVersion{}); err != nil {
+				return err
+			}
+			if err := m.AddColumn(&v12Form{}, "PublishedVersionID"); err != nil {
+				return err
+			}
+			if err := m.CreateIndex(&v12Form{}, "PublishedVersionID"); err != nil {
+				return err
+			}
+			if err := m.AddColumn(&v12Submission{}, "FormVersionID"); err != nil {
+				return err
+			}
+			return m.CreateIndex(&v12Submission{}, "FormVersionID")
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&v12Submission{}, "FormVersionID"); err != nil {
+				return err
+			}
+			if err := m.DropColumn(&v12Form{}, "PublishedVersionID"); err != nil {
+				return err
+			}
+			return m.DropTable(&v12FormVersion{})
+		},
+	},
+	{
+		Version: 13,
+		Name:    "add_form_schedules_and_limits",
+		Up: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, column := range []string{"OpensAt", "ClosesAt", "MaxSubmissions", "MaxPerRespondent", "ScheduleState"} {
+				if err := m.AddColumn(&v13Form{}, column); err != nil {
+					return err
+				}
+			}
+			if err := m.AddColumn(&v13Submission{}, "RespondentKey"); err != nil {
+				return err
+			}
+			return m.CreateIndex(&v13Submission{}, "RespondentKey")
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&v13Submission{}, "RespondentKey"); err != nil {
+				return err
+			}
+			for _, column := range []string{"ScheduleState", "MaxPerRespondent", "MaxSubmissions", "ClosesAt", "OpensAt"} {
+				if err := m.DropColumn(&v13Form{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 14,
+		Name:    "add_submission_flag_reason",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&v14Submission{}, "FlagReason")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&v14Submission{}, "FlagReason")
+		},
+	},
+}
+
+// Schema snapshot for migration 1
+
+type v1Event struct {
+	gorm.Model
+	Name        string `gorm:"not null"`
+	Description string
+	Date        time.Time `gorm:"not null"`
+}
+
+func (v1Event) TableName() string { return "events" }
+
+type v1Form struct {
+	gorm.Model
+	EventID     uint   `gorm:"index;not null"`
+	Title       string `gorm:"not null"`
+	IsMultiStep bool   `gorm:"default:false"`
+	IsPublished bool   `gorm:"default:false"`
+}
+
+func (v1Form) TableName() string { return "forms" }
+
+type v1FormField struct {
+	gorm.Model
+	FormID      uint   `gorm:"index;not null"`
+	Step        int    `gorm:"default:1"`
+	FieldType   string `gorm:"not null"`
+	Label       string `gorm:"not null"`
+	Placeholder string
+	Options     string
+	IsRequired  bool `gorm:"default:false"`
+	FieldOrder  int  `gorm:"not null"`
+}
+
+func (v1FormField) TableName() string { return "form_fields" }
+
+type v1Submission struct {
+	gorm.Model
+	FormID        uint   `gorm:"index;not null"`
+	SubmissionKey string `gorm:"uniqueIndex;not null"`
+	Status        string `gorm:"default:in_progress"`
+	CurrentStep   int    `gorm:"default:1"`
+	CompletedAt   sql.NullTime
+}
+
+func (v1Submission) TableName() string { return "submissions" }
+
+type v1SubmissionResponse struct {
+	gorm.Model
+	SubmissionID uint `gorm:"index;not null"`
+	FieldID      uint `gorm:"index;not null"`
+	Response     string
+}
+
+func (v1SubmissionResponse) TableName() string { return "submission_responses" }
+
+// Schema snapshot for migration 2
+
+type v2User struct {
+	gorm.Model
+	Email        string `gorm:"uniqueIndex;not null"`
+	Name         string `gorm:"not null"`
+	PasswordHash string `gorm:"not null"`
+}
+
+func (v2User) TableName() string { return "users" }
+
+type v2Session struct {
+	ID        string    `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
+
+func (v2Session) TableName() string { return "sessions" }
+
+type v2EventMember struct {
+	gorm.Model
+	EventID uint   `gorm:"uniqueIndex:idx_event_members_event_user;not null"`
+	UserID  uint   `gorm:"uniqueIndex:idx_event_members_event_user;index;not null"`
+	Role    string `gorm:"not null"`
+}
+
+func (v2EventMember) TableName() string { return "event_members" }
+
+// Schema snapshot for migration 3
+
+type v3FormField struct {
+	ID          uint
+	Options     string
+	OptionsJSON string `gorm:"column:options_json;type:jsonb;not null;default:'[]'"`
+	OptionsText string `gorm:"column:options_text"`
+}
+
+func (v3FormField) TableName() string { return "form_fields" }
+
+type v3FieldOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// legacyFieldOptions parses options stored before migration 3. They were
+// entered as a JSON array of strings, one option per line, or comma-separated.
+func legacyFieldOptions(options string) []v3FieldOption {
+	var values []string
+	if err := json.Unmarshal([]byte(options), &values); err != nil {
+		values = nil
+		separator := ","
+		if strings.Contains(options, "\n") {
+			separator = "\n"
+		}
+		for _, value := range strings.Split(strings.Trim(strings.TrimSpace(options), "[]"), separator) {
+			values = append(values, strings.Trim(strings.TrimSpace(value), `"`))
+		}
+	}
+
+	converted := []v3FieldOption{}
+	seen := make(map[string]bool)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		converted = append(converted, v3FieldOption{Value: value, Label: value})
+	}
+	return converted
+}
+
+// Schema snapshot for migration 4
+
+type v4FormField struct {
+	ID          uint
+	Constraints string `gorm:"type:jsonb;not null;default:'{}'"`
+}
+
+func (v4FormField) TableName() string { return "form_fields" }
+
+// Schema snapshot for migration 5
+
+type v5SubmissionResponse struct {
+	ID       uint
+	Response string
+}
+
+func (v5SubmissionResponse) TableName() string { return "submission_responses" }
+
+// checkboxResponses selects the non-empty answers to checkbox fields
+func checkboxResponses(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&v5SubmissionResponse{}).
+		Select("submission_responses.id, submission_responses.response").
+		Joins("JOIN form_fields ON form_fields.id = submission_responses.field_id").
+		Where("form_fields.field_type = ? AND submission_responses.response <> ''", "checkbox")
+}
+
+// Schema snapshot for migration 6
+
+type v6SubmissionResponse struct {
+	ID           uint
+	SubmissionID uint `gorm:"uniqueIndex:idx_submission_responses_submission_field"`
+	FieldID      uint `gorm:"uniqueIndex:idx_submission_responses_submission_field"`
+}
+
+func (v6SubmissionResponse) TableName() string { return "submission_responses" }
+
+// Schema snapshot for migration 7
+
+type v7Form struct {
+	ID        uint
+	StepRules string `gorm:"type:jsonb;not null;default:'[]'"`
+}
+
+func (v7Form) TableName() string { return "forms" }
+
+type v7FormField struct {
+	ID     uint
+	ShowIf string `gorm:"type:jsonb;not null;default:'{}'"`
+}
+
+func (v7FormField) TableName() string { return "form_fields" }
+
+// Schema snapshot for migration 8
+
+type v8Form struct {
+	ID           uint
+	NotifyMode   string `gorm:"not null;default:'none'"`
+	SendReceipts bool   `gorm:"default:false"`
+	LastDigestAt sql.NullTime
+}
+
+func (v8Form) TableName() string { return "forms" }
+
+type v8OutboxEmail struct {
+	gorm.Model
+	To            string    `gorm:"not null"`
+	Subject       string    `gorm:"not null"`
+	Body          string    `gorm:"type:text;not null"`
+	Status        string    `gorm:"index;not null;default:pending"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"index;not null"`
+	LastError     string
+	SentAt        sql.NullTime
+}
+
+func (v8OutboxEmail) TableName() string { return "outbox_emails" }
+
+// Schema snapshot for migration 9
+
+type v9Webhook struct {
+	gorm.Model
+	EventID  uint   `gorm:"index;not null"`
+	FormID   *uint  `gorm:"index"`
+	URL      string `gorm:"not null"`
+	Secret   string `gorm:"not null"`
+	IsActive bool   `gorm:"default:true"`
+}
+
+func (v9Webhook) TableName() string { return "webhooks" }
+
+type v9WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint      `gorm:"index;not null"`
+	Topic          string    `gorm:"not null"`
+	Payload        string    `gorm:"type:text;not null"`
+	Status         string    `gorm:"index;not null;default:pending"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"index;not null"`
+	LastStatusCode int
+	LastError      string
+	DeliveredAt    sql.NullTime
+}
+
+func (v9WebhookDelivery) TableName() string { return "webhook_deliveries" }
+
+type v9WebhookAttempt struct {
+	ID         uint `gorm:"primaryKey"`
+	DeliveryID uint `gorm:"index;not null"`
+	StatusCode int
+	Error      string
+	DurationMS int64
+	CreatedAt  time.Time
+}
+
+func (v9WebhookAttempt) TableName() string { return "webhook_attempts" }
+
+// Schema snapshot for migration 10
+
+type v10FormTemplate struct {
+	gorm.Model
+	Name        string `gorm:"not null"`
+	Description string
+	Definition  string `gorm:"type:jsonb;not null"`
+	CreatedByID uint   `gorm:"index;not null"`
+}
+
+func (v10FormTemplate) TableName() string { return "form_templates" }
+
+// Schema snapshot for migration 11
+
+type v11Form struct {
+	ID      uint
+	EventID uint
+	Title   string
+	Slug    string `gorm:"not null;default:''"`
+}
+
+func (v11Form) TableName() string { return "forms" }
+
+type v11FormField struct {
+	ID     uint
+	FormID uint
+	Label  string
+	Ref    string `gorm:"not null;default:''"`
+}
+
+func (v11FormField) TableName() string { return "form_fields" }
+
+// v11UniqueSlug returns base, or fallback if base is empty, numbered so that
+// it is not in taken, and adds it to taken
+func v11UniqueSlug(taken map[string]bool, base, fallback string) string {
+	if base == "" {
+		base = fallback
+	}
+	slug := base
+	for i := 2; taken[slug]; i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	taken[slug] = true
+	return slug
+}
+
+// Schema snapshot for migration 12
+
+type v12FormVersion struct {
+	gorm.Model
+	FormID      uint   `gorm:"uniqueIndex:idx_form_versions_form_number;not null"`
+	Number      int    `gorm:"uniqueIndex:idx_form_versions_form_number;not null"`
+	Title       string `gorm:"not null"`
+	IsMultiStep bool   `gorm:"default:false"`
+	StepRules   string `gorm:"type:jsonb;not null;default:'[]'"`
+	Fields      string `gorm:"type:jsonb;not null;default:'[]'"`
+}
+
+func (v12FormVersion) TableName() string { return "form_versions" }
+
+type v12Form struct {
+	ID                 uint
+	PublishedVersionID *uint `gorm:"index"`
+}
+
+func (v12Form) TableName() string { return "forms" }
+
+type v12Submission struct {
+	ID            uint
+	FormVersionID *uint `gorm:"index"`
+}
+
+func (v12Submission) TableName() string { return "submissions" }
+
+// Schema snapshot for migration 13
+
+type v13Form struct {
+	ID               uint
+	OpensAt          sql.NullTime
+	ClosesAt         sql.NullTime
+	MaxSubmissions   int    `gorm:"not null;default:0"`
+	MaxPerRespondent int    `gorm:"not null;default:0"`
+	ScheduleState    string `gorm:"not null;default:''"`
+}
+
+func (v13Form) TableName() string { return "forms" }
+
+type v13Submission struct {
+	ID            uint
+	RespondentKey string `gorm:"index;not null;default:''"`
+}
+
+func (v13Submission) TableName() string { return "submissions" }
+
+// Schema snapshot for migration 14
+
+type v14Submission struct {
+	ID         uint
+	FlagReason string `gorm:"not null;default:''"`
+}
+
+func (v14Submission) TableName() string { return "submissions" }
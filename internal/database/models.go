@@ -24,12 +24,23 @@ func (Event) TableName() string {
 // Form represents a feedback form for an event
 type Form struct {
 	gorm.Model
-	EventID     uint        `gorm:"index;not null" json:"event_id"`
-	Title       string      `gorm:"not null" json:"title"`
-	IsMultiStep bool        `gorm:"default:false" json:"is_multi_step"`
-	IsPublished bool        `gorm:"default:false" json:"is_published"`
-	Event       Event       `gorm:"foreignKey:EventID" json:"event,omitempty"`
-	Fields      []FormField `gorm:"foreignKey:FormID" json:"fields,omitempty"`
+	EventID            uint         `gorm:"index;uniqueIndex:idx_forms_event_slug,where:deleted_at IS NULL;not null" json:"event_id"`
+	Slug               string       `gorm:"uniqueIndex:idx_forms_event_slug,where:deleted_at IS NULL;not null" json:"slug"` // stable name used by definition imports
+	Title              string       `gorm:"not null" json:"title"`
+	IsMultiStep        bool         `gorm:"default:false" json:"is_multi_step"`
+	IsPublished        bool         `gorm:"default:false" json:"is_published"`
+	StepRules          StepRules    `gorm:"not null;default:'[]'" json:"step_rules"`    // branching between steps of multi-step forms
+	NotifyMode         string       `gorm:"not null;default:'none'" json:"notify_mode"` // none, each, digest
+	SendReceipts       bool         `gorm:"default:false" json:"send_receipts"`
+	LastDigestAt       sql.NullTime `json:"-"`
+	PublishedVersionID *uint        `gorm:"index" json:"published_version_id"`            // version respondents are shown
+	OpensAt            sql.NullTime `json:"opens_at"`                                     // respondents can answer from then on
+	ClosesAt           sql.NullTime `json:"closes_at"`                                    // respondents can no longer answer from then on
+	MaxSubmissions     int          `gorm:"not null;default:0" json:"max_submissions"`    // completed submissions accepted, 0 for no limit
+	MaxPerRespondent   int          `gorm:"not null;default:0" json:"max_per_respondent"` // completed submissions accepted from one respondent, 0 for no limit
+	ScheduleState      string       `gorm:"not null;default:''" json:"-"`                 // schedule state the scheduler last acted on
+	Event              Event        `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	Fields             []FormField  `gorm:"foreignKey:FormID" json:"fields,omitempty"`
 }
 
 // TableName specifies the table name for Form
@@ -37,18 +48,28 @@ func (Form) TableName() string {
 	return "forms"
 }
 
+// Form notification modes, deciding how event owners hear about completed submissions
+const (
+	NotifyNone   = "none"
+	NotifyEach   = "each"
+	NotifyDigest = "digest"
+)
+
 // FormField represents a field in a form
 type FormField struct {
 	gorm.Model
-	FormID      uint   `gorm:"index;not null" json:"form_id"`
-	Step        int    `gorm:"default:1" json:"step"`
-	FieldType   string `gorm:"not null" json:"field_type"` // text, textarea, select, radio, checkbox, etc.
-	Label       string `gorm:"not null" json:"label"`
-	Placeholder string `json:"placeholder"`
-	Options     string `json:"options"` // JSON string for options
-	IsRequired  bool   `gorm:"default:false" json:"is_required"`
-	FieldOrder  int    `gorm:"not null" json:"field_order"`
-	Form        Form   `gorm:"foreignKey:FormID" json:"form,omitempty"`
+	FormID      uint             `gorm:"index;uniqueIndex:idx_form_fields_form_ref,where:deleted_at IS NULL;not null" json:"form_id"`
+	Ref         string           `gorm:"uniqueIndex:idx_form_fields_form_ref,where:deleted_at IS NULL;not null" json:"ref"` // stable name used by form definitions
+	Step        int              `gorm:"default:1" json:"step"`
+	FieldType   string           `gorm:"not null" json:"field_type"` // text, textarea, select, radio, checkbox, etc.
+	Label       string           `gorm:"not null" json:"label"`
+	Placeholder string           `json:"placeholder"`
+	Options     FieldOptions     `gorm:"not null;default:'[]'" json:"options"` // choices of select, radio and checkbox fields
+	Constraints FieldConstraints `gorm:"not null;default:'{}'" json:"constraints"`
+	IsRequired  bool             `gorm:"default:false" json:"is_required"`
+	ShowIf      Condition        `gorm:"not null;default:'{}'" json:"show_if"` // field is only shown when this holds
+	FieldOrder  int              `gorm:"not null" json:"field_order"`
+	Form        Form             `gorm:"foreignKey:FormID" json:"form,omitempty"`
 }
 
 // TableName specifies the table name for FormField
@@ -61,9 +82,12 @@ type Submission struct {
 	gorm.Model
 	FormID        uint                 `gorm:"index;not null" json:"form_id"`
 	SubmissionKey string               `gorm:"uniqueIndex;not null" json:"submission_key"`
-	Status        string               `gorm:"default:in_progress" json:"status"` // in_progress, completed
+	Status        string               `gorm:"default:in_progress" json:"status"` // in_progress, completed, flagged
 	CurrentStep   int                  `gorm:"default:1" json:"current_step"`
 	CompletedAt   sql.NullTime         `json:"completed_at"`
+	FormVersionID *uint                `gorm:"index" json:"form_version_id"`                     // version of the form the submission was started against
+	RespondentKey string               `gorm:"index;not null;default:''" json:"-"`               // identifies the browser the submission was started in
+	FlagReason    string               `gorm:"not null;default:''" json:"flag_reason,omitempty"` // why the submission looks like spam; flagged submissions await moderation
 	Form          Form                 `gorm:"foreignKey:FormID" json:"form,omitempty"`
 	Responses     []SubmissionResponse `gorm:"foreignKey:SubmissionID" json:"responses,omitempty"`
 }
@@ -73,11 +97,12 @@ func (Submission) TableName() string {
 	return "submissions"
 }
 
-// SubmissionResponse represents a response to a specific form field
+// SubmissionResponse represents a response to a specific form field.
+// A submission has at most one response per field.
 type SubmissionResponse struct {
 	gorm.Model
-	SubmissionID uint       `gorm:"index;not null" json:"submission_id"`
-	FieldID      uint       `gorm:"index;not null" json:"field_id"`
+	SubmissionID uint       `gorm:"uniqueIndex:idx_submission_responses_submission_field;index;not null" json:"submission_id"`
+	FieldID      uint       `gorm:"uniqueIndex:idx_submission_responses_submission_field;index;not null" json:"field_id"`
 	Response     string     `json:"response"`
 	Submission   Submission `gorm:"foreignKey:SubmissionID" json:"submission,omitempty"`
 	Field        FormField  `gorm:"foreignKey:FieldID" json:"field,omitempty"`
@@ -87,3 +112,178 @@ type SubmissionResponse struct {
 func (SubmissionResponse) TableName() string {
 	return "submission_responses"
 }
+
+// User represents an organizer account
+type User struct {
+	gorm.Model
+	Email        string `gorm:"uniqueIndex;not null" json:"email"`
+	Name         string `gorm:"not null" json:"name"`
+	PasswordHash string `gorm:"not null" json:"-"`
+}
+
+// TableName specifies the table name for User
+func (User) TableName() string {
+	return "users"
+}
+
+// Session represents a logged-in browser session
+type Session struct {
+	ID        string    `gorm:"primaryKey" json:"-"` // SHA-256 hash of the session token
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name for Session
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// Event member roles, from least to most privileged
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+// EventMember grants a user a role on an event
+type EventMember struct {
+	gorm.Model
+	EventID uint   `gorm:"uniqueIndex:idx_event_members_event_user;not null" json:"event_id"`
+	UserID  uint   `gorm:"uniqueIndex:idx_event_members_event_user;index;not null" json:"user_id"`
+	Role    string `gorm:"not null" json:"role"` // owner, editor, viewer
+	Event   Event  `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	User    User   `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name for EventMember
+func (EventMember) TableName() string {
+	return "event_members"
+}
+
+// Outbox email statuses
+const (
+	EmailPending = "pending"
+	EmailSent    = "sent"
+	EmailFailed  = "failed"
+)
+
+// OutboxEmail is an email waiting to be delivered, or the record of one that was.
+// Emails are queued in the same transaction as the change that causes them and
+// delivered in the background, so a mail outage never fails a request.
+type OutboxEmail struct {
+	gorm.Model
+	To            string       `gorm:"not null" json:"to"`
+	Subject       string       `gorm:"not null" json:"subject"`
+	Body          string       `gorm:"type:text;not null" json:"body"`
+	Status        string       `gorm:"index;not null;default:pending" json:"status"` // pending, sent, failed
+	Attempts      int          `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time    `gorm:"index;not null" json:"next_attempt_at"`
+	LastError     string       `json:"last_error"`
+	SentAt        sql.NullTime `json:"sent_at"`
+}
+
+// TableName specifies the table name for OutboxEmail
+func (OutboxEmail) TableName() string {
+	return "outbox_emails"
+}
+
+// Webhook topics
+const (
+	TopicFormPublished       = "form.published"
+	TopicFormUnpublished     = "form.unpublished"
+	TopicSubmissionCompleted = "submission.completed"
+)
+
+// Webhook is an endpoint that is sent the lifecycle events of an event's forms.
+// A webhook with a FormID only receives the events of that form.
+type Webhook struct {
+	gorm.Model
+	EventID  uint   `gorm:"index;not null" json:"event_id"`
+	FormID   *uint  `gorm:"index" json:"form_id"`
+	URL      string `gorm:"not null" json:"url"`
+	Secret   string `gorm:"not null" json:"-"` // key the payloads are signed with
+	IsActive bool   `gorm:"default:true" json:"is_active"`
+	Form     *Form  `gorm:"foreignKey:FormID" json:"form,omitempty"`
+}
+
+// TableName specifies the table name for Webhook
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Webhook delivery statuses. Dead deliveries ran out of attempts and are only
+// retried by hand.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookDelivery is a payload queued for a webhook
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint             `gorm:"index;not null" json:"webhook_id"`
+	Topic          string           `gorm:"not null" json:"topic"`
+	Payload        string           `gorm:"type:text;not null" json:"payload"`
+	Status         string           `gorm:"index;not null;default:pending" json:"status"` // pending, delivered, dead
+	Attempts       int              `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time        `gorm:"index;not null" json:"next_attempt_at"`
+	LastStatusCode int              `json:"last_status_code"`
+	LastError      string           `json:"last_error"`
+	DeliveredAt    sql.NullTime     `json:"delivered_at"`
+	Webhook        Webhook          `gorm:"foreignKey:WebhookID" json:"webhook,omitempty"`
+	AttemptLog     []WebhookAttempt `gorm:"foreignKey:DeliveryID" json:"attempt_log,omitempty"`
+}
+
+// TableName specifies the table name for WebhookDelivery
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookAttempt records one try at sending a delivery
+type WebhookAttempt struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	DeliveryID uint      `gorm:"index;not null" json:"delivery_id"`
+	StatusCode int       `json:"status_code"` // 0 if no response was received
+	Error      string    `json:"error"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for WebhookAttempt
+func (WebhookAttempt) TableName() string {
+	return "webhook_attempts"
+}
+
+// FormTemplate is a saved form definition that can be used to create forms under any event
+type FormTemplate struct {
+	gorm.Model
+	Name        string         `gorm:"not null" json:"name"`
+	Description string         `json:"description"`
+	Definition  FormDefinition `gorm:"not null" json:"definition"`
+	CreatedByID uint           `gorm:"index;not null" json:"created_by_id"`
+	CreatedBy   User           `gorm:"foreignKey:CreatedByID" json:"-"`
+}
+
+// TableName specifies the table name for FormTemplate
+func (FormTemplate) TableName() string {
+	return "form_templates"
+}
+
+// FormVersion is an immutable snapshot of a form, taken when it is published
+type FormVersion struct {
+	gorm.Model
+	FormID      uint          `gorm:"uniqueIndex:idx_form_versions_form_number;not null" json:"form_id"`
+	Number      int           `gorm:"uniqueIndex:idx_form_versions_form_number;not null" json:"number"` // counts up from 1 for each form
+	Title       string        `gorm:"not null" json:"title"`
+	IsMultiStep bool          `gorm:"default:false" json:"is_multi_step"`
+	StepRules   StepRules     `gorm:"not null;default:'[]'" json:"step_rules"`
+	Fields      VersionFields `gorm:"not null;default:'[]'" json:"fields"`
+}
+
+// TableName specifies the table name for FormVersion
+func (FormVersion) TableName() string {
+	return "form_versions"
+}
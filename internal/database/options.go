@@ -0,0 +1,186 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// MaxFieldOptions is the largest number of options a choice field may have
+	MaxFieldOptions = 100
+	// MaxOptionLength is the longest value or label an option may have
+	MaxOptionLength = 200
+)
+
+// FieldOption is a single choice offered by a select, radio or checkbox field.
+// Value is what gets stored in answers and Label is what respondents see.
+type FieldOption struct {
+	Value string `json:"value" yaml:"value"`
+	Label string `json:"label" yaml:"label"`
+}
+
+// FieldOptions is the list of options of a field, stored as a JSON array
+type FieldOptions []FieldOption
+
+// GormDBDataType stores options as jsonb on PostgreSQL and JSON text elsewhere
+func (FieldOptions) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+// Value implements driver.Valuer
+func (o FieldOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (o *FieldOptions) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*o = FieldOptions{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into FieldOptions", value)
+	}
+
+	var options FieldOptions
+	if err := json.Unmarshal(b, &options); err != nil {
+		return fmt.Errorf("invalid field options: %w", err)
+	}
+	if options == nil {
+		options = FieldOptions{}
+	}
+	*o = options
+	return nil
+}
+
+// Has reports whether value is one of the option values
+func (o FieldOptions) Has(value string) bool {
+	for _, option := range o {
+		if option.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Label returns the label of the option with the given value, or the value
+// itself if no option has it
+func (o FieldOptions) Label(value string) string {
+	for _, option := range o {
+		if option.Value == value {
+			return option.Label
+		}
+	}
+	return value
+}
+
+// Text formats the options one per line, in the format read by ParseFieldOptions
+func (o FieldOptions) Text() string {
+	lines := make([]string, len(o))
+	for i, option := range o {
+		if option.Label == option.Value {
+			lines[i] = option.Value
+		} else {
+			lines[i] = option.Value + " | " + option.Label
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseFieldOptions reads options entered one per line. A line may be either
+// just a label, which is also used as the value, or "value | label".
+// Blank lines are ignored.
+func ParseFieldOptions(text string) FieldOptions {
+	options := FieldOptions{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		option := FieldOption{Value: line, Label: line}
+		if value, label, ok := strings.Cut(line, "|"); ok {
+			option.Value = strings.TrimSpace(value)
+			option.Label = strings.TrimSpace(label)
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// FieldTypes lists the field types a form field may have
+var FieldTypes = []string{"text", "textarea", "number", "email", "select", "radio", "checkbox", "date"}
+
+// IsValidFieldType reports whether fieldType is a supported field type
+func IsValidFieldType(fieldType string) bool {
+	for _, t := range FieldTypes {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsChoiceField reports whether fieldType offers a fixed set of options
+func IsChoiceField(fieldType string) bool {
+	return fieldType == "select" || fieldType == "radio" || fieldType == "checkbox"
+}
+
+// NormalizeFieldOptions checks the options of a field of the given type and
+// returns them cleaned up: whitespace is trimmed and missing labels default
+// to the value. Fields that are not choice fields never have options.
+func NormalizeFieldOptions(fieldType string, options FieldOptions) (FieldOptions, error) {
+	if !IsChoiceField(fieldType) {
+		return FieldOptions{}, nil
+	}
+
+	if len(options) == 0 {
+		return nil, fmt.Errorf("%s fields need at least one option", fieldType)
+	}
+	if len(options) > MaxFieldOptions {
+		return nil, fmt.Errorf("a field can have at most %d options", MaxFieldOptions)
+	}
+
+	normalized := make(FieldOptions, 0, len(options))
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		option.Value = strings.TrimSpace(option.Value)
+		option.Label = strings.TrimSpace(option.Label)
+		if option.Label == "" {
+			option.Label = option.Value
+		}
+
+		switch {
+		case option.Value == "":
+			return nil, fmt.Errorf("option values cannot be empty")
+		case len(option.Value) > MaxOptionLength || len(option.Label) > MaxOptionLength:
+			return nil, fmt.Errorf("option values and labels can be at most %d characters", MaxOptionLength)
+		case seen[option.Value]:
+			return nil, fmt.Errorf("option value %q is used more than once", option.Value)
+		}
+
+		seen[option.Value] = true
+		normalized = append(normalized, option)
+	}
+	return normalized, nil
+}
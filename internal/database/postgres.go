@@ -0,0 +1,66 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// openPostgres connects to a PostgreSQL database, creating the database on
+// the server if it does not exist yet
+func openPostgres(cfg Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	err := createPostgresDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Now connect to the specific database with proper DSN format
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
+	)
+
+	// Connect using GORM with proper configuration
+	gormDB, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return gormDB, nil
+}
+
+// createPostgresDatabase creates the database named in cfg if the server
+// does not have it yet
+func createPostgresDatabase(cfg Config) error {
+	// First connect to PostgreSQL server without specifying a database
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.SSLMode,
+	)
+
+	// Connect to PostgreSQL server
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to PostgreSQL server: %w", err)
+	}
+	defer db.Close()
+
+	// Check if database exists
+	var exists bool
+	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = $1)", cfg.Name).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if database exists: %w", err)
+	}
+
+	// Create database if it doesn't exist
+	if !exists {
+		_, err = db.Exec(fmt.Sprintf("CREATE DATABASE %s", cfg.Name))
+		if err != nil {
+			return fmt.Errorf("failed to create database: %w", err)
+		}
+	}
+
+	return nil
+}
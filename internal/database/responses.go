@@ -0,0 +1,70 @@
+package database
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveResponses stores answers, replacing any existing answer of the same
+// submission to the same field. Deleted answers that are answered again are restored.
+func SaveResponses(tx *gorm.DB, responses []SubmissionResponse) error {
+	if len(responses) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "submission_id"}, {Name: "field_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"response", "updated_at", "deleted_at"}),
+	}).Create(&responses).Error
+}
+
+// Fields of type checkbox accept several values. Their answer is stored in a
+// single SubmissionResponse as a JSON array of the selected option values, so
+// that values containing commas survive and the database can unnest them.
+
+// JoinResponseValues encodes the values selected in a multi-value field.
+// Blank values are dropped; no selection is stored as an empty response.
+func JoinResponseValues(values []string) string {
+	var kept []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			kept = append(kept, value)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+
+	// Marshalling a slice of strings cannot fail
+	b, _ := json.Marshal(kept)
+	return string(b)
+}
+
+// SplitResponseValues decodes the answer to a multi-value field. Answers
+// stored as comma-separated text before values were JSON encoded are split
+// on commas.
+func SplitResponseValues(response string) []string {
+	if response == "" {
+		return []string{}
+	}
+
+	values := []string{}
+	if err := json.Unmarshal([]byte(response), &values); err == nil {
+		return values
+	}
+
+	values = []string{}
+	for _, value := range strings.Split(response, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// IsMultiValueField reports whether answers to fieldType can hold several values
+func IsMultiValueField(fieldType string) bool {
+	return fieldType == "checkbox"
+}
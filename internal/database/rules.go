@@ -0,0 +1,298 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Multi-step forms can branch on earlier answers. A field may carry a
+// condition that decides whether it is shown, and a form may carry step rules
+// that decide which step follows a given step. Conditions only refer to fields
+// on earlier steps so that they can be evaluated on the server before a step
+// is shown; step rules may also refer to fields on the step they leave.
+
+// Condition operators
+const (
+	OperatorEquals      = "equals"
+	OperatorNotEquals   = "not_equals"
+	OperatorIsEmpty     = "is_empty"
+	OperatorIsNotEmpty  = "is_not_empty"
+	OperatorGreaterThan = "greater_than"
+	OperatorLessThan    = "less_than"
+)
+
+// MaxStepRules is the largest number of step rules a form may have
+const MaxStepRules = 100
+
+// ConditionOperator describes an operator for the rule editor
+type ConditionOperator struct {
+	Name  string
+	Label string
+}
+
+// ConditionOperators lists the operators a condition can use, in the order
+// they are offered in the rule editor
+var ConditionOperators = []ConditionOperator{
+	{OperatorEquals, "is"},
+	{OperatorNotEquals, "is not"},
+	{OperatorIsEmpty, "is empty"},
+	{OperatorIsNotEmpty, "is not empty"},
+	{OperatorGreaterThan, "is greater than"},
+	{OperatorLessThan, "is less than"},
+}
+
+// OperatorLabel returns the human readable form of an operator
+func OperatorLabel(name string) string {
+	for _, operator := range ConditionOperators {
+		if operator.Name == name {
+			return operator.Label
+		}
+	}
+	return name
+}
+
+// Condition compares the answer to a field with an operand. For checkbox fields,
+// equals and not_equals test whether the operand is among the selected options.
+// The zero Condition is unset and always holds.
+type Condition struct {
+	FieldID  uint   `json:"field_id,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Operand  string `json:"value,omitempty"`
+}
+
+// IsSet reports whether the condition refers to a field
+func (c Condition) IsSet() bool {
+	return c.FieldID != 0
+}
+
+// Matches reports whether answer, the stored response to a field of type
+// fieldType, satisfies the condition
+func (c Condition) Matches(fieldType, answer string) bool {
+	var values []string
+	if IsMultiValueField(fieldType) {
+		values = SplitResponseValues(answer)
+	} else if answer = strings.TrimSpace(answer); answer != "" {
+		values = []string{answer}
+	}
+
+	switch c.Operator {
+	case OperatorEquals:
+		return containsValue(values, c.Operand)
+	case OperatorNotEquals:
+		return !containsValue(values, c.Operand)
+	case OperatorIsEmpty:
+		return len(values) == 0
+	case OperatorIsNotEmpty:
+		return len(values) > 0
+	case OperatorGreaterThan, OperatorLessThan:
+		if len(values) != 1 {
+			return false
+		}
+		n, err := strconv.ParseFloat(values[0], 64)
+		limit, lerr := strconv.ParseFloat(c.Operand, 64)
+		if err != nil || lerr != nil {
+			return false
+		}
+		if c.Operator == OperatorGreaterThan {
+			return n > limit
+		}
+		return n < limit
+	}
+	return false
+}
+
+// GormDBDataType stores conditions as jsonb on PostgreSQL and JSON text elsewhere
+func (Condition) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+// Value implements driver.Valuer
+func (c Condition) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (c *Condition) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*c = Condition{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Condition", value)
+	}
+
+	var condition Condition
+	if err := json.Unmarshal(b, &condition); err != nil {
+		return fmt.Errorf("invalid condition: %w", err)
+	}
+	*c = condition
+	return nil
+}
+
+// StepRule sends respondents leaving Step to GoTo when Condition holds.
+// A GoTo of 0 ends the form.
+type StepRule struct {
+	Step      int       `json:"step"`
+	Condition Condition `json:"condition"`
+	GoTo      int       `json:"go_to"`
+}
+
+// StepRules is the list of step rules of a form, stored as a JSON array.
+// When several rules of a step match, the first one wins.
+type StepRules []StepRule
+
+// GormDBDataType stores step rules as jsonb on PostgreSQL and JSON text elsewhere
+func (StepRules) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+// Value implements driver.Valuer
+func (r StepRules) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (r *StepRules) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*r = StepRules{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StepRules", value)
+	}
+
+	var rules StepRules
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return fmt.Errorf("invalid step rules: %w", err)
+	}
+	if rules == nil {
+		rules = StepRules{}
+	}
+	*r = rules
+	return nil
+}
+
+// NormalizeShowIf checks the visibility condition of field against the other
+// fields of its form. The referenced field must be on an earlier step.
+func NormalizeShowIf(field FormField, fields []FormField, c Condition) (Condition, error) {
+	if !c.IsSet() {
+		return Condition{}, nil
+	}
+	return normalizeCondition(c, fields, func(target FormField) error {
+		if target.Step >= field.Step {
+			return fmt.Errorf("a field can only depend on fields of earlier steps")
+		}
+		return nil
+	})
+}
+
+// NormalizeStepRule checks a step rule against the fields of its form. The
+// condition may refer to fields of the rule's step or earlier steps, and the
+// rule can only jump forward, so that respondents cannot be sent in circles.
+func NormalizeStepRule(rule StepRule, fields []FormField) (StepRule, error) {
+	if rule.Step < 1 {
+		return rule, fmt.Errorf("the step must be at least 1")
+	}
+	if rule.GoTo != 0 && rule.GoTo <= rule.Step {
+		return rule, fmt.Errorf("a rule can only go to a later step")
+	}
+	if !rule.Condition.IsSet() {
+		return rule, fmt.Errorf("a rule needs a condition")
+	}
+
+	condition, err := normalizeCondition(rule.Condition, fields, func(target FormField) error {
+		if target.Step > rule.Step {
+			return fmt.Errorf("a rule can only depend on fields of its own or earlier steps")
+		}
+		return nil
+	})
+	if err != nil {
+		return rule, err
+	}
+	rule.Condition = condition
+	return rule, nil
+}
+
+// normalizeCondition checks that c refers to one of fields, accepted by
+// checkField, and that its operator and value suit that field
+func normalizeCondition(c Condition, fields []FormField, checkField func(FormField) error) (Condition, error) {
+	var target *FormField
+	for i := range fields {
+		if fields[i].ID == c.FieldID {
+			target = &fields[i]
+			break
+		}
+	}
+	if target == nil {
+		return c, fmt.Errorf("the condition refers to a field that does not exist")
+	}
+	if err := checkField(*target); err != nil {
+		return c, err
+	}
+
+	c.Operand = strings.TrimSpace(c.Operand)
+	switch c.Operator {
+	case OperatorIsEmpty, OperatorIsNotEmpty:
+		c.Operand = ""
+	case OperatorEquals, OperatorNotEquals:
+		if c.Operand == "" {
+			return c, fmt.Errorf("the condition needs a value to compare with")
+		}
+		if IsChoiceField(target.FieldType) && !target.Options.Has(c.Operand) {
+			return c, fmt.Errorf("%q is not an option of %q", c.Operand, target.Label)
+		}
+	case OperatorGreaterThan, OperatorLessThan:
+		if target.FieldType != "number" {
+			return c, fmt.Errorf("only number fields can be compared")
+		}
+		if _, err := strconv.ParseFloat(c.Operand, 64); err != nil {
+			return c, fmt.Errorf("the condition needs a number to compare with")
+		}
+	default:
+		return c, fmt.Errorf("unknown operator %q", c.Operator)
+	}
+	return c, nil
+}
+
+// containsValue reports whether value is one of values
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
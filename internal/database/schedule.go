@@ -0,0 +1,66 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Schedule states of a form
+const (
+	ScheduleNone      = ""          // the form has no open or close time
+	ScheduleScheduled = "scheduled" // the form opens later
+	ScheduleOpen      = "open"
+	ScheduleClosed    = "closed"
+)
+
+// ScheduleAt returns the schedule state of the form at now. Forms open at
+// OpensAt and close at ClosesAt; either may be left unset.
+func (f Form) ScheduleAt(now time.Time) string {
+	switch {
+	case f.ClosesAt.Valid && !now.Before(f.ClosesAt.Time):
+		return ScheduleClosed
+	case f.OpensAt.Valid && now.Before(f.OpensAt.Time):
+		return ScheduleScheduled
+	case f.OpensAt.Valid || f.ClosesAt.Valid:
+		return ScheduleOpen
+	default:
+		return ScheduleNone
+	}
+}
+
+// CheckLimits checks the schedule and response limits of a form
+func CheckLimits(form Form) error {
+	if form.OpensAt.Valid && form.ClosesAt.Valid && !form.ClosesAt.Time.After(form.OpensAt.Time) {
+		return errors.New("the form must close after it opens")
+	}
+	if form.MaxSubmissions < 0 {
+		return errors.New("the maximum number of responses cannot be negative")
+	}
+	if form.MaxPerRespondent < 0 {
+		return errors.New("the maximum number of responses per respondent cannot be negative")
+	}
+	return nil
+}
+
+// CountCompleted returns the number of completed submissions of a form, only
+// counting those of the given respondent unless respondentKey is empty
+func CountCompleted(tx *gorm.DB, formID uint, respondentKey string) (int64, error) {
+	query := tx.Model(&Submission{}).Where("form_id = ? AND status = ?", formID, "completed")
+	if respondentKey != "" {
+		query = query.Where("respondent_key = ?", respondentKey)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// LockForm locks the row of a form until tx ends, so that checking its
+// response limits and completing a submission within them happen for one
+// submission at a time. SQLite locks the whole database for every write
+// transaction instead.
+func LockForm(tx *gorm.DB, formID uint) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&Form{}, formID).Error
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Seed adds some test data to the database
+func Seed(db *gorm.DB) error {
+	// Create a test event
+	event := Event{
+		Name:        "Test Event",
+		Description: "This is a test event for the feedback system",
+		Date:        time.Now().AddDate(0, 1, 0), // One month from now
+	}
+	result := db.Create(&event)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create test event: %w", result.Error)
+	}
+
+	// Create a test form
+	form := Form{
+		EventID:     event.ID,
+		Title:       "Test Feedback Form",
+		IsMultiStep: false,
+	}
+	result = db.Create(&form)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create test form: %w", result.Error)
+	}
+
+	// Create some test form fields
+	fields := []FormField{
+		{
+			FormID:     form.ID,
+			FieldType:  "text",
+			Label:      "What did you like most about the event?",
+			IsRequired: true,
+			FieldOrder: 1,
+		},
+		{
+			FormID:     form.ID,
+			FieldType:  "textarea",
+			Label:      "Do you have any suggestions for improvement?",
+			IsRequired: false,
+			FieldOrder: 2,
+		},
+		{
+			FormID:    form.ID,
+			FieldType: "select",
+			Label:     "How would you rate the event?",
+			Options: FieldOptions{
+				{Value: "Excellent", Label: "Excellent"},
+				{Value: "Good", Label: "Good"},
+				{Value: "Average", Label: "Average"},
+				{Value: "Poor", Label: "Poor"},
+			},
+			IsRequired: true,
+			FieldOrder: 3,
+		},
+	}
+
+	for _, field := range fields {
+		result = db.Create(&field)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create test form field: %w", result.Error)
+		}
+	}
+
+	// Publish the form with its fields
+	if _, _, err := PublishForm(db, &form); err != nil {
+		return fmt.Errorf("failed to publish test form: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxSlugLength is the longest slug generated from a title or label
+const maxSlugLength = 50
+
+// slugPattern matches valid form slugs and field refs: lower case words of
+// letters and digits separated by single hyphens or underscores
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+
+// IsValidSlug reports whether s can be used as a form slug or field ref
+func IsValidSlug(s string) bool {
+	return len(s) <= 100 && slugPattern.MatchString(s)
+}
+
+// Slugify turns a title into a slug, keeping letters and digits and joining
+// words with hyphens. It returns "" if there is nothing to keep.
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		default:
+			hyphen = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	return b.String()
+}
+
+// BeforeCreate gives a form without a slug one derived from its title that is
+// unique within its event
+func (f *Form) BeforeCreate(tx *gorm.DB) error {
+	if f.Slug != "" {
+		return nil
+	}
+	query := tx.Session(&gorm.Session{NewDB: true}).Model(&Form{}).Where("event_id = ?", f.EventID)
+	slug, err := uniqueSlug(query, "slug", Slugify(f.Title), "form")
+	f.Slug = slug
+	return err
+}
+
+// BeforeCreate gives a field without a ref one derived from its label that is
+// unique within its form
+func (f *FormField) BeforeCreate(tx *gorm.DB) error {
+	if f.Ref != "" {
+		return nil
+	}
+	query := tx.Session(&gorm.Session{NewDB: true}).Model(&FormField{}).Where("form_id = ?", f.FormID)
+	ref, err := uniqueSlug(query, "ref", Slugify(f.Label), "field")
+	f.Ref = ref
+	return err
+}
+
+// uniqueSlug returns base, or fallback if base is empty, followed by the
+// smallest number that makes it unique among the values of column in query
+func uniqueSlug(query *gorm.DB, column, base, fallback string) (string, error) {
+	if base == "" {
+		base = fallback
+	}
+
+	var used []string
+	err := query.Where(column+" = ? OR "+column+" LIKE ?", base, base+"-%").Pluck(column, &used).Error
+	if err != nil {
+		return "", err
+	}
+	return PickSlug(base, fallback, used), nil
+}
+
+// PickSlug returns base, or fallback if base is empty, followed by the
+// smallest number that makes it differ from every slug in used
+func PickSlug(base, fallback string, used []string) string {
+	if base == "" {
+		base = fallback
+	}
+	taken := make(map[string]bool, len(used))
+	for _, s := range used {
+		taken[s] = true
+	}
+
+	slug := base
+	for i := 2; taken[slug]; i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return slug
+}
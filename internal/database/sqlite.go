@@ -0,0 +1,38 @@
+package database
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// memoryDatabases numbers the in-memory SQLite databases opened so far, so
+// each Open gets a database of its own
+var memoryDatabases atomic.Int64
+
+// openSQLite opens an SQLite database file, creating it if needed, or a new
+// in-memory database if cfg.Path is ":memory:"
+func openSQLite(cfg Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("DB_PATH is required for sqlite")
+	}
+
+	// Enforce foreign keys like PostgreSQL does, wait for locks held by other
+	// connections instead of failing, and take the write lock when a
+	// transaction starts so transactions cannot deadlock upgrading to it
+	options := "_foreign_keys=on&_busy_timeout=5000&_txlock=immediate"
+	memory := cfg.Path == ":memory:"
+	dsn := "file:" + cfg.Path + "?_journal_mode=WAL&" + options
+	if memory {
+		// The connections of the pool share one named in-memory database
+		dsn = fmt.Sprintf("file:memory%d?mode=memory&cache=shared&%s", memoryDatabases.Add(1), options)
+	}
+
+	gormDB, err := gorm.Open(sqlite.Open(dsn), gormConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database %s: %w", cfg.Path, err)
+	}
+	return gormDB, nil
+}
@@ -0,0 +1,364 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// The fields of a form are its draft, which editors change freely.
+// Publishing a form snapshots the draft into an immutable, numbered
+// FormVersion. Respondents are shown the published version, and each
+// submission records the version it was started against, so that its
+// answers keep the labels and options they were given under.
+
+// VersionField is a form field as it was when a version was published. It
+// keeps the field's ID, so answers to the field can be matched to it.
+type VersionField struct {
+	ID          uint             `json:"id"`
+	Ref         string           `json:"ref"`
+	Step        int              `json:"step"`
+	FieldType   string           `json:"field_type"`
+	Label       string           `json:"label"`
+	Placeholder string           `json:"placeholder"`
+	Options     FieldOptions     `json:"options"`
+	Constraints FieldConstraints `json:"constraints"`
+	IsRequired  bool             `json:"is_required"`
+	ShowIf      Condition        `json:"show_if"`
+	FieldOrder  int              `json:"field_order"`
+}
+
+// VersionFields is the list of fields of a version in step and field order,
+// stored as a JSON array
+type VersionFields []VersionField
+
+// GormDBDataType stores version fields as jsonb on PostgreSQL and JSON text elsewhere
+func (VersionFields) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+// Value implements driver.Valuer
+func (f VersionFields) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (f *VersionFields) Scan(value interface{}) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*f = VersionFields{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into VersionFields", value)
+	}
+
+	var fields VersionFields
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return fmt.Errorf("invalid version fields: %w", err)
+	}
+	if fields == nil {
+		fields = VersionFields{}
+	}
+	*f = fields
+	return nil
+}
+
+// FormFields returns the fields of the version as form fields
+func (v FormVersion) FormFields() []FormField {
+	fields := make([]FormField, 0, len(v.Fields))
+	for _, f := range v.Fields {
+		field := FormField{
+			FormID:      v.FormID,
+			Ref:         f.Ref,
+			Step:        f.Step,
+			FieldType:   f.FieldType,
+			Label:       f.Label,
+			Placeholder: f.Placeholder,
+			Options:     f.Options,
+			Constraints: f.Constraints,
+			IsRequired:  f.IsRequired,
+			ShowIf:      f.ShowIf,
+			FieldOrder:  f.FieldOrder,
+		}
+		field.ID = f.ID
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// SameContent reports whether two versions describe the same form
+func (v FormVersion) SameContent(other FormVersion) bool {
+	a, errA := json.Marshal([]interface{}{v.Title, v.IsMultiStep, v.StepRules, v.Fields})
+	b, errB := json.Marshal([]interface{}{other.Title, other.IsMultiStep, other.StepRules, other.Fields})
+	return errA == nil && errB == nil && string(a) == string(b)
+}
+
+// DraftVersion returns an unsaved version holding the current draft of form
+func DraftVersion(tx *gorm.DB, form Form) (FormVersion, error) {
+	var fields []FormField
+	if err := tx.Where("form_id = ?", form.ID).Order("step, field_order").Find(&fields).Error; err != nil {
+		return FormVersion{}, err
+	}
+	return NewVersion(form, fields), nil
+}
+
+// NewVersion returns an unsaved version of form with the given fields, which
+// are in step and field order
+func NewVersion(form Form, fields []FormField) FormVersion {
+	version := FormVersion{
+		FormID:      form.ID,
+		Title:       form.Title,
+		IsMultiStep: form.IsMultiStep,
+		StepRules:   form.StepRules,
+		Fields:      make(VersionFields, 0, len(fields)),
+	}
+	if version.StepRules == nil {
+		version.StepRules = StepRules{}
+	}
+	for _, field := range fields {
+		version.Fields = append(version.Fields, VersionField{
+			ID:          field.ID,
+			Ref:         field.Ref,
+			Step:        field.Step,
+			FieldType:   field.FieldType,
+			Label:       field.Label,
+			Placeholder: field.Placeholder,
+			Options:     field.Options,
+			Constraints: field.Constraints,
+			IsRequired:  field.IsRequired,
+			ShowIf:      field.ShowIf,
+			FieldOrder:  field.FieldOrder,
+		})
+	}
+	return version
+}
+
+// PublishedVersion returns the version of form respondents are shown, or nil
+// if the form has never been published
+func PublishedVersion(tx *gorm.DB, form Form) (*FormVersion, error) {
+	if form.PublishedVersionID == nil {
+		return nil, nil
+	}
+	var version FormVersion
+	if err := tx.First(&version, *form.PublishedVersionID).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// PublishForm publishes the current draft of form. A new version is only
+// created if the draft differs from the version published last; created
+// reports whether it was.
+func PublishForm(tx *gorm.DB, form *Form) (version FormVersion, created bool, err error) {
+	draft, err := DraftVersion(tx, *form)
+	if err != nil {
+		return version, false, err
+	}
+
+	var latest FormVersion
+	err = tx.Where("form_id = ?", form.ID).Order("number desc").First(&latest).Error
+	switch {
+	case err == nil && latest.SameContent(draft):
+		version = latest
+	case err == nil || errors.Is(err, gorm.ErrRecordNotFound):
+		draft.Number = latest.Number + 1
+		if err := tx.Create(&draft).Error; err != nil {
+			return version, false, err
+		}
+		version, created = draft, true
+	default:
+		return version, false, err
+	}
+
+	form.IsPublished = true
+	form.PublishedVersionID = &version.ID
+	err = tx.Model(form).Select("IsPublished", "PublishedVersionID").Updates(form).Error
+	return version, created, err
+}
+
+// HasDraftChanges reports whether the draft of a published form differs
+// from the version respondents are shown
+func HasDraftChanges(tx *gorm.DB, form Form) (bool, error) {
+	published, err := PublishedVersion(tx, form)
+	if err != nil || published == nil {
+		return false, err
+	}
+	draft, err := DraftVersion(tx, form)
+	if err != nil {
+		return false, err
+	}
+	return !published.SameContent(draft), nil
+}
+
+// FormAsOf returns form and its fields as they were in the version with ID
+// versionID, or the current draft if versionID is nil
+func FormAsOf(tx *gorm.DB, form Form, versionID *uint) (Form, []FormField, error) {
+	if versionID == nil {
+		var fields []FormField
+		err := tx.Where("form_id = ?", form.ID).Order("step, field_order").Find(&fields).Error
+		return form, fields, err
+	}
+
+	var version FormVersion
+	if err := tx.Where("form_id = ?", form.ID).First(&version, *versionID).Error; err != nil {
+		return form, nil, err
+	}
+	form.Title = version.Title
+	form.IsMultiStep = version.IsMultiStep
+	form.StepRules = version.StepRules
+	return form, version.FormFields(), nil
+}
+
+// Answer is the answer of a submission to a single field
+type Answer struct {
+	Field    FormField
+	Response string
+}
+
+// SubmissionAnswers returns the answers of a submission in form order, with
+// each field as it was in the version the submission was started against.
+// Answers to fields missing from that version, e.g. from before versions were
+// kept, follow with the field as it is now, even if it has been deleted.
+func SubmissionAnswers(tx *gorm.DB, submission Submission) ([]Answer, error) {
+	var responses []SubmissionResponse
+	if err := tx.Where("submission_id = ?", submission.ID).Find(&responses).Error; err != nil {
+		return nil, err
+	}
+	var fields []FormField
+	if submission.FormVersionID != nil {
+		var version FormVersion
+		if err := tx.First(&version, *submission.FormVersionID).Error; err != nil {
+			return nil, err
+		}
+		fields = version.FormFields()
+	}
+
+	// Look up the fields of the answers the version does not have
+	inVersion := make(map[uint]bool, len(fields))
+	for _, field := range fields {
+		inVersion[field.ID] = true
+	}
+	var ids []uint
+	for _, response := range responses {
+		if !inVersion[response.FieldID] {
+			ids = append(ids, response.FieldID)
+		}
+	}
+	var current []FormField
+	if len(ids) > 0 {
+		if err := tx.Unscoped().Where("id IN ?", ids).Order("step, field_order, id").Find(&current).Error; err != nil {
+			return nil, err
+		}
+	}
+	return MatchAnswers(responses, fields, current), nil
+}
+
+// MatchAnswers pairs responses with the fields they answer: first with
+// versionFields, in their order, then the rest with currentFields, in theirs.
+// Responses to fields in neither are left out.
+func MatchAnswers(responses []SubmissionResponse, versionFields, currentFields []FormField) []Answer {
+	byField := make(map[uint]string, len(responses))
+	for _, response := range responses {
+		byField[response.FieldID] = response.Response
+	}
+
+	answers := make([]Answer, 0, len(responses))
+	for _, fields := range [][]FormField{versionFields, currentFields} {
+		for _, field := range fields {
+			if response, ok := byField[field.ID]; ok {
+				answers = append(answers, Answer{Field: field, Response: response})
+				delete(byField, field.ID)
+			}
+		}
+	}
+	return answers
+}
+
+// ReportFields returns the fields to report the answers to form under, in
+// step and field order: every field of the published version and of the
+// versions its submissions were started against, each as it was in the
+// newest of them. Fields deleted or relabelled in the draft thus keep the
+// answers given to them. Submissions from before versions were kept are
+// reported under the current draft fields.
+func ReportFields(tx *gorm.DB, form Form) ([]FormField, error) {
+	var versionIDs []uint
+	err := tx.Model(&Submission{}).Where("form_id = ? AND form_version_id IS NOT NULL", form.ID).
+		Distinct().Pluck("form_version_id", &versionIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	if form.PublishedVersionID != nil {
+		versionIDs = append(versionIDs, *form.PublishedVersionID)
+	}
+
+	var unversioned int64
+	err = tx.Model(&Submission{}).Where("form_id = ? AND form_version_id IS NULL", form.ID).Count(&unversioned).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []FormVersion
+	if len(versionIDs) > 0 {
+		err := tx.Where("form_id = ? AND id IN ?", form.ID, versionIDs).Order("number desc").Find(&versions).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var draft []FormField
+	if unversioned > 0 || len(versions) == 0 {
+		_, draft, err = FormAsOf(tx, form, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return MergeFields(versions, draft), nil
+}
+
+// MergeFields returns every field of versions, which are newest first, and of
+// draft, each as it was in the first of them that has it, in step and field
+// order
+func MergeFields(versions []FormVersion, draft []FormField) []FormField {
+	var fields []FormField
+	seen := make(map[uint]bool)
+	add := func(candidates []FormField) {
+		for _, field := range candidates {
+			if !seen[field.ID] {
+				seen[field.ID] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	for _, version := range versions {
+		add(version.FormFields())
+	}
+	add(draft)
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].Step != fields[j].Step {
+			return fields[i].Step < fields[j].Step
+		}
+		return fields[i].FieldOrder < fields[j].FieldOrder
+	})
+	return fields
+}
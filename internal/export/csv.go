@@ -0,0 +1,49 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// csvWriter writes rows as comma-separated values
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter returns a Writer producing CSV
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+// WriteHeader writes the header row
+func (c *csvWriter) WriteHeader(columns []string) error {
+	return c.WriteRow(columns)
+}
+
+// WriteRow writes a single row
+func (c *csvWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeFormula(value)
+	}
+	return c.w.Write(escaped)
+}
+
+// Close flushes any buffered rows
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula prefixes values that spreadsheet applications would evaluate
+// as formulas, so respondents cannot inject formulas into an organizer's spreadsheet
+func escapeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
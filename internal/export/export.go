@@ -0,0 +1,69 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Writer writes a table of rows in a particular file format. WriteHeader must
+// be called once before any rows are written, and Close once after the last.
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []string) error
+	Close() error
+}
+
+// Format describes a supported export file format
+type Format struct {
+	Name        string
+	ContentType string
+	Extension   string
+	NewWriter   func(w io.Writer) Writer
+}
+
+// Formats lists the supported export formats by name
+var Formats = map[string]Format{
+	"csv": {
+		Name:        "csv",
+		ContentType: "text/csv; charset=utf-8",
+		Extension:   "csv",
+		NewWriter:   NewCSVWriter,
+	},
+	"json": {
+		Name:        "json",
+		ContentType: "application/x-ndjson",
+		Extension:   "ndjson",
+		NewWriter:   NewJSONWriter,
+	},
+	"xlsx": {
+		Name:        "xlsx",
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Extension:   "xlsx",
+		NewWriter:   NewXLSXWriter,
+	},
+}
+
+// LookupFormat returns the format with the given name
+func LookupFormat(name string) (Format, error) {
+	format, ok := Formats[strings.ToLower(name)]
+	if !ok {
+		return Format{}, fmt.Errorf("unsupported export format %q", name)
+	}
+	return format, nil
+}
+
+// UniqueColumns returns columns with duplicate names disambiguated by a numeric suffix,
+// so each column can be used as a key
+func UniqueColumns(columns []string) []string {
+	seen := make(map[string]int, len(columns))
+	unique := make([]string, len(columns))
+	for i, column := range columns {
+		seen[column]++
+		if seen[column] > 1 {
+			column = fmt.Sprintf("%s (%d)", column, seen[column])
+		}
+		unique[i] = column
+	}
+	return unique
+}
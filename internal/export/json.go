@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// jsonWriter writes each row as a JSON object on its own line (NDJSON)
+type jsonWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+// NewJSONWriter returns a Writer producing newline-delimited JSON
+func NewJSONWriter(w io.Writer) Writer {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader records the column names used as object keys
+func (j *jsonWriter) WriteHeader(columns []string) error {
+	j.columns = UniqueColumns(columns)
+	return nil
+}
+
+// WriteRow writes a single row as an object, keeping keys in column order
+func (j *jsonWriter) WriteRow(values []string) error {
+	j.w.WriteByte('{')
+	for i, column := range j.columns {
+		if i > 0 {
+			j.w.WriteByte(',')
+		}
+
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		j.w.Write(key)
+		j.w.WriteByte(':')
+		j.w.Write(encoded)
+	}
+	j.w.WriteString("}\n")
+
+	// Flush each row so the response streams rather than buffering
+	return j.w.Flush()
+}
+
+// Close flushes any buffered output
+func (j *jsonWriter) Close() error {
+	return j.w.Flush()
+}
@@ -0,0 +1,350 @@
+// Package formdef describes forms independently of the database rows they
+// are stored in, so that they can be copied, saved as templates and created
+// again under any event.
+package formdef
+
+import (
+	"fmt"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+// ValidationError reports a definition that cannot be turned into a form
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// invalid returns a ValidationError with a formatted message
+func invalid(format string, args ...interface{}) error {
+	return &ValidationError{Err: fmt.Errorf(format, args...)}
+}
+
+// FromForm describes form and its fields, which must be ordered by step and
+// field order
+func FromForm(form database.Form, fields []database.FormField) database.FormDefinition {
+	d := database.FormDefinition{
+		Slug:        form.Slug,
+		Title:       form.Title,
+		IsMultiStep: form.IsMultiStep,
+		Fields:      make([]database.FieldDefinition, 0, len(fields)),
+	}
+
+	refs := make(map[uint]string, len(fields))
+	for _, field := range fields {
+		refs[field.ID] = field.Ref
+	}
+	condition := func(c database.Condition) (database.DefinitionCondition, bool) {
+		ref, ok := refs[c.FieldID]
+		return database.DefinitionCondition{Field: ref, Operator: c.Operator, Value: c.Operand}, ok
+	}
+
+	for _, field := range fields {
+		f := database.FieldDefinition{
+			Ref:         field.Ref,
+			Step:        field.Step,
+			FieldType:   field.FieldType,
+			Label:       field.Label,
+			Placeholder: field.Placeholder,
+			Options:     field.Options,
+			Constraints: field.Constraints,
+			IsRequired:  field.IsRequired,
+		}
+		if c, ok := condition(field.ShowIf); ok && field.ShowIf.IsSet() {
+			f.ShowIf = &c
+		}
+		d.Fields = append(d.Fields, f)
+	}
+
+	// Rules on fields that no longer exist have no effect and are left out
+	for _, rule := range form.StepRules {
+		if c, ok := condition(rule.Condition); ok {
+			d.StepRules = append(d.StepRules, database.DefinitionStepRule{Step: rule.Step, Condition: c, GoTo: rule.GoTo})
+		}
+	}
+	return d
+}
+
+// Load reads a form and its fields and describes them
+func Load(db *gorm.DB, formID uint) (database.FormDefinition, error) {
+	var form database.Form
+	if err := db.First(&form, formID).Error; err != nil {
+		return database.FormDefinition{}, err
+	}
+	var fields []database.FormField
+	if err := db.Where("form_id = ?", form.ID).Order("step, field_order").Find(&fields).Error; err != nil {
+		return database.FormDefinition{}, err
+	}
+	return FromForm(form, fields), nil
+}
+
+// Validate checks a definition before it is imported: the slug, field refs,
+// step numbering and each field's type, options and constraints. Conditions
+// and step rules are checked when the form is written.
+func Validate(d database.FormDefinition) error {
+	if d.Slug != "" && !database.IsValidSlug(d.Slug) {
+		return invalid("slug %q must be lower case letters and digits separated by hyphens", d.Slug)
+	}
+	if d.Title == "" {
+		return invalid("the form needs a title")
+	}
+
+	// Fields are listed step by step and steps are numbered from 1 without gaps
+	lastStep := 1
+	for _, f := range d.Fields {
+		step := f.Step
+		if step == 0 {
+			step = 1 // the step may be left out of single-page forms
+		}
+		if step < lastStep || step > lastStep+1 {
+			return invalid("field %q is on step %d: steps must start at 1, be listed in order and have no gaps", f.Ref, f.Step)
+		}
+		lastStep = step
+	}
+
+	return validateFields(d)
+}
+
+// validateFields checks the refs of the fields of d and converts each of them
+func validateFields(d database.FormDefinition) error {
+	refs := make(map[string]bool, len(d.Fields))
+	for i, f := range d.Fields {
+		if f.Ref == "" {
+			return invalid("field %d has no ref", i+1)
+		}
+		if !database.IsValidSlug(f.Ref) {
+			return invalid("field ref %q must be lower case letters and digits separated by hyphens or underscores", f.Ref)
+		}
+		if refs[f.Ref] {
+			return invalid("field ref %q is used more than once", f.Ref)
+		}
+		refs[f.Ref] = true
+
+		if _, err := newField(f); err != nil {
+			return invalid("field %q: %w", f.Ref, err)
+		}
+	}
+	return nil
+}
+
+// Create creates an unpublished form under eventID from d, with the given
+// title, and returns it. Every field, option, constraint and rule is checked
+// as if it had been entered in the form editor. The form gets a new slug
+// derived from its title; fields keep their refs.
+func Create(tx *gorm.DB, d database.FormDefinition, eventID uint, title string) (database.Form, error) {
+	form, err := NewForm(d, eventID, title)
+	if err != nil {
+		return form, err
+	}
+	if err := tx.Create(&form).Error; err != nil {
+		return form, err
+	}
+	return form, write(tx, &form, nil, d)
+}
+
+// NewForm checks the fields of d and returns the unsaved form Create makes
+// from it, without its fields
+func NewForm(d database.FormDefinition, eventID uint, title string) (database.Form, error) {
+	form := database.Form{
+		EventID:     eventID,
+		Title:       title,
+		IsMultiStep: d.IsMultiStep,
+		NotifyMode:  database.NotifyNone,
+		StepRules:   database.StepRules{},
+	}
+	if form.Title == "" {
+		return form, invalid("the form needs a title")
+	}
+	return form, validateFields(d)
+}
+
+// write makes the fields and step rules of form match d. Existing fields are
+// matched to the definition by ref and updated in place, so that answers to
+// them are kept; fields missing from d are deleted. d must be valid.
+func write(tx *gorm.DB, form *database.Form, existing []database.FormField, d database.FormDefinition) error {
+	// Write the fields first so that conditions can refer to their IDs
+	fields, removed, err := Fields(d, existing)
+	if err != nil {
+		return err
+	}
+	for i := range fields {
+		fields[i].FormID = form.ID
+		if fields[i].ID != 0 {
+			err = tx.Select("Step", "FieldType", "Label", "Placeholder", "Options", "Constraints", "IsRequired", "FieldOrder").
+				Save(&fields[i]).Error
+		} else {
+			err = tx.Create(&fields[i]).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, field := range removed {
+		if err := tx.Delete(&field).Error; err != nil {
+			return err
+		}
+	}
+
+	conditions, rules, err := Conditions(d, fields)
+	if err != nil {
+		return err
+	}
+	for i, c := range conditions {
+		if c == fields[i].ShowIf {
+			continue
+		}
+		fields[i].ShowIf = c
+		if err := tx.Model(&fields[i]).Update("show_if", c).Error; err != nil {
+			return err
+		}
+	}
+	if len(rules) > 0 || len(form.StepRules) > 0 {
+		form.StepRules = rules
+		if err := tx.Model(form).Update("step_rules", form.StepRules).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fields converts the fields of d, which must be valid, into form fields
+// numbered from 1 within each step. A field whose ref matches one of existing
+// takes over its ID and condition; the existing fields left over are returned
+// as removed.
+func Fields(d database.FormDefinition, existing []database.FormField) (fields, removed []database.FormField, err error) {
+	current := make(map[string]database.FormField, len(existing))
+	for _, field := range existing {
+		current[field.Ref] = field
+	}
+
+	fields = make([]database.FormField, 0, len(d.Fields))
+	orders := map[int]int{}
+	for _, f := range d.Fields {
+		field, err := newField(f)
+		if err != nil {
+			return nil, nil, invalid("field %q: %w", f.Ref, err)
+		}
+		orders[field.Step]++
+		field.Ref = f.Ref
+		field.FieldOrder = orders[field.Step]
+
+		if old, ok := current[f.Ref]; ok {
+			field.Model = old.Model
+			field.ShowIf = old.ShowIf
+			delete(current, f.Ref)
+		}
+		fields = append(fields, field)
+	}
+
+	for _, field := range existing {
+		if _, ok := current[field.Ref]; ok {
+			removed = append(removed, field)
+		}
+	}
+	return fields, removed, nil
+}
+
+// Conditions resolves the conditions of d against fields, which Fields
+// returned for d and which have been given IDs. It returns the condition of
+// each field, in the same order, and the step rules of the form.
+func Conditions(d database.FormDefinition, fields []database.FormField) ([]database.Condition, database.StepRules, error) {
+	ids := make(map[string]uint, len(fields))
+	for _, field := range fields {
+		ids[field.Ref] = field.ID
+	}
+	condition := func(c database.DefinitionCondition) (database.Condition, error) {
+		id, ok := ids[c.Field]
+		if !ok {
+			return database.Condition{}, invalid("the condition refers to unknown field %q", c.Field)
+		}
+		return database.Condition{FieldID: id, Operator: c.Operator, Operand: c.Value}, nil
+	}
+
+	conditions := make([]database.Condition, len(d.Fields))
+	for i, f := range d.Fields {
+		if f.ShowIf == nil {
+			continue
+		}
+		c, err := condition(*f.ShowIf)
+		if err == nil {
+			c, err = database.NormalizeShowIf(fields[i], fields, c)
+		}
+		if err != nil {
+			return nil, nil, invalid("field %q: %w", f.Ref, err)
+		}
+		conditions[i] = c
+	}
+
+	if len(d.StepRules) > database.MaxStepRules {
+		return nil, nil, invalid("a form can have at most %d step rules", database.MaxStepRules)
+	}
+	rules := database.StepRules{}
+	for i, r := range d.StepRules {
+		c, err := condition(r.Condition)
+		if err != nil {
+			return nil, nil, invalid("step rule %d: %w", i+1, err)
+		}
+		rule, err := database.NormalizeStepRule(database.StepRule{Step: r.Step, Condition: c, GoTo: r.GoTo}, fields)
+		if err != nil {
+			return nil, nil, invalid("step rule %d: %w", i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return conditions, rules, nil
+}
+
+// CopyEvent creates event and copies every form of the event with ID
+// sourceID into it, keeping their titles. The copies are unpublished.
+func CopyEvent(tx *gorm.DB, sourceID uint, event *database.Event) error {
+	var forms []database.Form
+	if err := tx.Where("event_id = ?", sourceID).Order("id").Find(&forms).Error; err != nil {
+		return err
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return err
+	}
+
+	for _, form := range forms {
+		d, err := Load(tx, form.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := Create(tx, d, event.ID, form.Title); err != nil {
+			return fmt.Errorf("form %q: %w", form.Title, err)
+		}
+	}
+	return nil
+}
+
+// newField checks a field definition and converts it to a form field
+func newField(f database.FieldDefinition) (database.FormField, error) {
+	field := database.FormField{
+		Step:        f.Step,
+		FieldType:   f.FieldType,
+		Label:       f.Label,
+		Placeholder: f.Placeholder,
+		IsRequired:  f.IsRequired,
+	}
+	if field.Step < 1 {
+		field.Step = 1
+	}
+	if !database.IsValidFieldType(field.FieldType) {
+		return field, fmt.Errorf("unknown field type %q", f.FieldType)
+	}
+	if field.Label == "" {
+		return field, fmt.Errorf("the field needs a label")
+	}
+
+	var err error
+	if field.Options, err = database.NormalizeFieldOptions(field.FieldType, f.Options); err != nil {
+		return field, err
+	}
+	if field.Constraints, err = database.NormalizeFieldConstraints(field.FieldType, f.Constraints); err != nil {
+		return field, err
+	}
+	return field, nil
+}
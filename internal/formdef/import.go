@@ -0,0 +1,269 @@
+package formdef
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Document formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Encode writes d as an indented JSON or YAML document
+func Encode(d database.FormDefinition, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatYAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), encoder.Close()
+	default:
+		return nil, fmt.Errorf("unknown format %q, must be json or yaml", format)
+	}
+}
+
+// Decode reads a JSON or YAML document, rejecting unknown keys
+func Decode(data []byte, format string) (database.FormDefinition, error) {
+	var d database.FormDefinition
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&d); err != nil {
+			return d, fmt.Errorf("invalid JSON document: %w", err)
+		}
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&d); err != nil {
+			return d, fmt.Errorf("invalid YAML document: %w", err)
+		}
+	default:
+		return d, fmt.Errorf("unknown format %q, must be json or yaml", format)
+	}
+	return d, nil
+}
+
+// Change is a single difference an import makes to a form
+type Change struct {
+	Action string `json:"action"` // add, change, remove
+	Target string `json:"target"` // what is changed, e.g. form, field "rating", step rules
+	Detail string `json:"detail,omitempty"`
+}
+
+// String formats a change as a line of a diff
+func (c Change) String() string {
+	sign := map[string]string{"add": "+", "change": "~", "remove": "-"}[c.Action]
+	if c.Detail == "" {
+		return sign + " " + c.Target
+	}
+	return sign + " " + c.Target + ": " + c.Detail
+}
+
+// ImportResult describes the outcome of an import
+type ImportResult struct {
+	Form    database.Form `json:"-"`
+	FormID  uint          `json:"form_id,omitempty"` // 0 for a dry run that would create the form
+	Slug    string        `json:"slug"`
+	Created bool          `json:"created"`
+	DryRun  bool          `json:"dry_run"`
+	Changes []Change      `json:"changes"`
+}
+
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
+// Import creates the form described by d under eventID, or updates the form
+// of the event with the same slug, and reports what changed. Fields are
+// matched by ref, so importing the same document again changes nothing. With
+// dryRun set, the import is rolled back and only the changes are reported.
+// Publishing and notification settings are left as they are.
+func Import(db *gorm.DB, d database.FormDefinition, eventID uint, dryRun bool) (ImportResult, error) {
+	d, err := Prepare(d)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	result := ImportResult{Slug: d.Slug, DryRun: dryRun}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var before database.FormDefinition
+		var fields []database.FormField
+
+		var form database.Form
+		err := tx.Where("event_id = ? AND slug = ?", eventID, d.Slug).First(&form).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			result.Created = true
+			form = database.Form{
+				EventID:     eventID,
+				Slug:        d.Slug,
+				Title:       d.Title,
+				IsMultiStep: d.IsMultiStep,
+				NotifyMode:  database.NotifyNone,
+				StepRules:   database.StepRules{},
+			}
+			if err := tx.Create(&form).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			if err := tx.Where("form_id = ?", form.ID).Order("step, field_order").Find(&fields).Error; err != nil {
+				return err
+			}
+			before = FromForm(form, fields)
+
+			form.Title = d.Title
+			form.IsMultiStep = d.IsMultiStep
+			if err := tx.Model(&form).Select("Title", "IsMultiStep").Updates(&form).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := write(tx, &form, fields, d); err != nil {
+			return err
+		}
+
+		after, err := Load(tx, form.ID)
+		if err != nil {
+			return err
+		}
+		result.Form = form
+		result.Changes = Diff(before, after)
+		if !result.Created || !dryRun {
+			result.FormID = form.ID
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return result, err
+}
+
+// Prepare validates d for an import and gives it a slug derived from its
+// title if it has none
+func Prepare(d database.FormDefinition) (database.FormDefinition, error) {
+	if err := Validate(d); err != nil {
+		return d, err
+	}
+	if d.Slug == "" {
+		d.Slug = database.Slugify(d.Title)
+		if d.Slug == "" {
+			return d, invalid("the document needs a slug")
+		}
+	}
+	return d, nil
+}
+
+// Diff lists the changes that turn the form described by a into the one
+// described by b. Fields are matched by ref. A zero a stands for a form that
+// does not exist yet.
+func Diff(a, b database.FormDefinition) []Change {
+	changes := []Change{}
+	if a.Title == "" {
+		changes = append(changes, Change{Action: "add", Target: "form " + strconv.Quote(b.Slug)})
+	}
+
+	change := func(target, name string, from, to interface{}) {
+		if f, t := format(from), format(to); f != t {
+			changes = append(changes, Change{Action: "change", Target: target, Detail: name + " " + f + " -> " + t})
+		}
+	}
+	if a.Title != "" {
+		change("form", "title", a.Title, b.Title)
+		change("form", "is_multi_step", a.IsMultiStep, b.IsMultiStep)
+	}
+
+	old := make(map[string]database.FieldDefinition, len(a.Fields))
+	oldPositions := positions(a)
+	for _, f := range a.Fields {
+		old[f.Ref] = f
+	}
+	newPositions := positions(b)
+	for _, f := range b.Fields {
+		target := "field " + strconv.Quote(f.Ref)
+		o, ok := old[f.Ref]
+		if !ok {
+			changes = append(changes, Change{Action: "add", Target: target, Detail: fmt.Sprintf("%s %s on step %d", f.FieldType, strconv.Quote(f.Label), f.Step)})
+			continue
+		}
+		delete(old, f.Ref)
+
+		change(target, "step", o.Step, f.Step)
+		change(target, "position", oldPositions[f.Ref], newPositions[f.Ref])
+		change(target, "field_type", o.FieldType, f.FieldType)
+		change(target, "label", o.Label, f.Label)
+		change(target, "placeholder", o.Placeholder, f.Placeholder)
+		change(target, "options", o.Options, f.Options)
+		change(target, "constraints", o.Constraints, f.Constraints)
+		change(target, "is_required", o.IsRequired, f.IsRequired)
+		change(target, "show_if", o.ShowIf, f.ShowIf)
+	}
+	for _, f := range a.Fields {
+		if _, ok := old[f.Ref]; ok {
+			changes = append(changes, Change{Action: "remove", Target: "field " + strconv.Quote(f.Ref), Detail: strconv.Quote(f.Label)})
+		}
+	}
+
+	change("step rules", "rules", a.StepRules, b.StepRules)
+	return changes
+}
+
+// positions returns the position of each field within its step, by ref
+func positions(d database.FormDefinition) map[string]int {
+	counts := map[int]int{}
+	result := make(map[string]int, len(d.Fields))
+	for _, f := range d.Fields {
+		counts[f.Step]++
+		result[f.Ref] = counts[f.Step]
+	}
+	return result
+}
+
+// format renders a value in a diff
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case database.FieldOptions:
+		if len(v) == 0 {
+			return "none"
+		}
+	case []database.DefinitionStepRule:
+		if len(v) == 0 {
+			return "none"
+		}
+	case *database.DefinitionCondition:
+		if v == nil {
+			return "none"
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
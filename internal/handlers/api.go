@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultPerPage is the page size used when per_page is not given
+	defaultPerPage = 20
+	// maxPerPage caps the page size a client may request
+	maxPerPage = 100
+	// maxAPIBodySize limits the size of JSON request bodies
+	maxAPIBodySize = 1 << 20
+)
+
+// registerAPIHandlers registers the JSON API routes under /api/v1/
+func (h *Handler) registerAPIHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/api/v1/events", h.APIEventsHandler)
+	mux.HandleFunc("/api/v1/events/", h.APIEventHandler)
+	mux.HandleFunc("/api/v1/forms", h.APIFormsHandler)
+	mux.HandleFunc("/api/v1/forms/", h.APIFormHandler)
+	mux.HandleFunc("/api/v1/fields", h.APIFieldsHandler)
+	mux.HandleFunc("/api/v1/fields/", h.APIFieldHandler)
+	mux.HandleFunc("/api/v1/submissions", h.APISubmissionsHandler)
+	mux.HandleFunc("/api/v1/submissions/", h.APISubmissionHandler)
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "Resource not found")
+	})
+}
+
+// apiErrorResponse is the JSON body returned for every failed API request
+type apiErrorResponse struct {
+	Error apiErrorBody `json:"error"`
+}
+
+// apiErrorBody describes an API error
+type apiErrorBody struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// itemResponse wraps a single resource
+type itemResponse struct {
+	Data interface{} `json:"data"`
+}
+
+// listResponse wraps a page of resources
+type listResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination pagination  `json:"pagination"`
+}
+
+// pagination describes the requested page of a list and the total number of rows
+type pagination struct {
+	Page    int   `json:"page"`
+	PerPage int   `json:"per_page"`
+	Total   int64 `json:"total"`
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes a JSON error response
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiErrorResponse{
+		Error: apiErrorBody{Status: status, Message: message},
+	})
+}
+
+// writeMethodNotAllowed writes a 405 response listing the allowed methods
+func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+// writeLookupError writes a 404 for missing records and a 500 for anything else
+func writeLookupError(w http.ResponseWriter, err error, resource string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, store.ErrNotFound) {
+		writeAPIError(w, http.StatusNotFound, resource+" not found")
+		return
+	}
+	writeAPIError(w, http.StatusInternalServerError, "Failed to fetch "+strings.ToLower(resource))
+}
+
+// decodeJSONBody decodes the request body into v, rejecting unknown fields
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("request body is empty")
+		}
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+// requiredRole returns the event role needed to perform an API request with the given method
+func requiredRole(method string) string {
+	if method == http.MethodGet || method == http.MethodHead {
+		return database.RoleViewer
+	}
+	return database.RoleEditor
+}
+
+// formEventID returns the ID of the event a form belongs to
+func (h *Handler) formEventID(ctx context.Context, formID uint) (uint, error) {
+	form, err := h.Forms.Form(ctx, formID)
+	return form.EventID, err
+}
+
+// parseAPIID extracts the numeric ID that follows prefix in the request path
+func parseAPIID(r *http.Request, prefix string) (uint64, bool) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	id, err := strconv.ParseUint(path, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// parsePagination reads the page and per_page query parameters
+func parsePagination(r *http.Request) (pagination, error) {
+	p := pagination{Page: 1, PerPage: defaultPerPage}
+
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return p, fmt.Errorf("page must be a positive integer")
+		}
+		p.Page = page
+	}
+
+	if perPageStr := r.URL.Query().Get("per_page"); perPageStr != "" {
+		perPage, err := strconv.Atoi(perPageStr)
+		if err != nil || perPage < 1 || perPage > maxPerPage {
+			return p, fmt.Errorf("per_page must be between 1 and %d", maxPerPage)
+		}
+		p.PerPage = perPage
+	}
+
+	return p, nil
+}
+
+// parseUintQuery parses an optional unsigned integer query parameter
+func parseUintQuery(r *http.Request, name string) (uint64, bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, false, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, true, nil
+}
+
+// parseBoolQuery parses an optional boolean query parameter
+func parseBoolQuery(r *http.Request, name string) (bool, bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false, fmt.Errorf("%s must be true or false", name)
+	}
+	return b, true, nil
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/formdef"
+)
+
+// definitionContentTypes maps document formats to their content types
+var definitionContentTypes = map[string]string{
+	formdef.FormatJSON: "application/json",
+	formdef.FormatYAML: "application/yaml",
+}
+
+// APIFormDefinitionHandler handles GET /api/v1/forms/{id}/definition, which
+// exports a form and its fields as a JSON or YAML document (?format=yaml)
+func (h *Handler) APIFormDefinitionHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/definition")
+	id, err := strconv.ParseUint(strings.TrimPrefix(path, "/api/v1/forms/"), 10, 64)
+	if err != nil || id == 0 {
+		writeAPIError(w, http.StatusNotFound, "Form not found")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formdef.FormatJSON
+	}
+	contentType, ok := definitionContentTypes[format]
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "format must be json or yaml")
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+
+	if !h.apiRequireEventRole(w, r, form.EventID, database.RoleViewer) {
+		return
+	}
+
+	d, err := h.formDefinition(r.Context(), form)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch form fields")
+		return
+	}
+	data, err := formdef.Encode(d, format)
+	if err != nil {
+		log.Printf("Failed to encode definition of form %d: %v", form.ID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to export form")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, form.Slug, format))
+	w.Write(data)
+}
+
+// APIFormImportHandler handles POST /api/v1/events/{id}/forms/import, which
+// creates or updates a form of the event from a JSON or YAML document. The
+// format follows the Content-Type; ?dry_run=true only reports the changes.
+func (h *Handler) APIFormImportHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/forms/import")
+	id, err := strconv.ParseUint(strings.TrimPrefix(path, "/api/v1/events/"), 10, 64)
+	if err != nil || id == 0 {
+		writeAPIError(w, http.StatusNotFound, "Event not found")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	dryRun, _, err := parseBoolQuery(r, "dry_run")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Get event
+	event, err := h.Events.Event(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Event")
+		return
+	}
+
+	if !h.apiRequireEventRole(w, r, event.ID, database.RoleEditor) {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAPIBodySize))
+	if err != nil {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "Request body is too large")
+		return
+	}
+	d, err := formdef.Decode(body, documentFormat(r.Header.Get("Content-Type")))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	importResult, err := h.Forms.ImportForm(r.Context(), d, event.ID, dryRun)
+	if err != nil {
+		var invalid *formdef.ValidationError
+		if errors.As(err, &invalid) {
+			writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		log.Printf("Failed to import form into event %d: %v", event.ID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to import form")
+		return
+	}
+
+	status := http.StatusOK
+	if importResult.Created && !dryRun {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, itemResponse{Data: importResult})
+}
+
+// documentFormat returns the definition format for a request content type,
+// treating anything that is not YAML as JSON
+func documentFormat(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return formdef.FormatYAML
+	}
+	return formdef.FormatJSON
+}
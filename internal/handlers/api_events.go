@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// apiEvent is the JSON representation of an event
+type apiEvent struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Date        string    `json:"date"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// apiEventInput is the request body for creating or updating an event
+type apiEventInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Date        *string `json:"date"`
+}
+
+// toAPIEvent converts an event model to its JSON representation
+func toAPIEvent(event database.Event) apiEvent {
+	return apiEvent{
+		ID:          event.ID,
+		Name:        event.Name,
+		Description: event.Description,
+		Date:        event.Date.Format("2006-01-02"),
+		CreatedAt:   event.CreatedAt,
+		UpdatedAt:   event.UpdatedAt,
+	}
+}
+
+// parseAPIDate accepts either a plain date or an RFC 3339 timestamp
+func parseAPIDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// apply copies the provided input values onto event
+func (in apiEventInput) apply(event *database.Event) string {
+	if in.Name != nil {
+		event.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Description != nil {
+		event.Description = *in.Description
+	}
+	if in.Date != nil {
+		date, err := parseAPIDate(*in.Date)
+		if err != nil {
+			return "date must be YYYY-MM-DD or RFC 3339"
+		}
+		event.Date = date
+	}
+
+	if event.Name == "" {
+		return "name is required"
+	}
+	if event.Date.IsZero() {
+		return "date is required"
+	}
+	return ""
+}
+
+// APIEventsHandler lists and creates events
+func (h *Handler) APIEventsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listAPIEvents(w, r)
+	case http.MethodPost:
+		h.createAPIEvent(w, r)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// APIEventHandler reads, updates and deletes a single event
+func (h *Handler) APIEventHandler(w http.ResponseWriter, r *http.Request) {
+	// Forms are imported below the event's own path
+	if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/forms/import") {
+		h.APIFormImportHandler(w, r)
+		return
+	}
+
+	id, ok := parseAPIID(r, "/api/v1/events/")
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Event not found")
+		return
+	}
+
+	// Get event
+	event, err := h.Events.Event(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Event")
+		return
+	}
+
+	// Deleting an event is reserved for its owners
+	role := requiredRole(r.Method)
+	if r.Method == http.MethodDelete {
+		role = database.RoleOwner
+	}
+	if !h.apiRequireEventRole(w, r, event.ID, role) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, itemResponse{Data: toAPIEvent(event)})
+
+	case http.MethodPut, http.MethodPatch:
+		var input apiEventInput
+		err = decodeJSONBody(w, r, &input)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if msg := input.apply(&event); msg != "" {
+			writeAPIError(w, http.StatusUnprocessableEntity, msg)
+			return
+		}
+
+		err = h.Events.SaveEvent(r.Context(), &event)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "Failed to update event")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, itemResponse{Data: toAPIEvent(event)})
+
+	case http.MethodDelete:
+		// Delete the event together with its forms and their fields
+		err = h.Events.DeleteEvent(r.Context(), event)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "Failed to delete event")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// listAPIEvents returns a page of events, optionally filtered by name and date range
+func (h *Handler) listAPIEvents(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiRequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := parsePagination(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := store.EventFilter{UserID: user.ID}
+	filter.Name = strings.TrimSpace(r.URL.Query().Get("q"))
+
+	if from := r.URL.Query().Get("date_from"); from != "" {
+		date, err := parseAPIDate(from)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "date_from must be YYYY-MM-DD or RFC 3339")
+			return
+		}
+		filter.DateFrom = &date
+	}
+
+	if to := r.URL.Query().Get("date_to"); to != "" {
+		date, err := parseAPIDate(to)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "date_to must be YYYY-MM-DD or RFC 3339")
+			return
+		}
+		filter.DateTo = &date
+	}
+
+	events, total, err := h.Events.SearchEvents(r.Context(), filter, (p.Page-1)*p.PerPage, p.PerPage)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch events")
+		return
+	}
+	p.Total = total
+
+	data := make([]apiEvent, 0, len(events))
+	for _, event := range events {
+		data = append(data, toAPIEvent(event))
+	}
+
+	writeJSON(w, http.StatusOK, listResponse{Data: data, Pagination: p})
+}
+
+// createAPIEvent creates an event from a JSON body
+func (h *Handler) createAPIEvent(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiRequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input apiEventInput
+	err := decodeJSONBody(w, r, &input)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var event database.Event
+	if msg := input.apply(&event); msg != "" {
+		writeAPIError(w, http.StatusUnprocessableEntity, msg)
+		return
+	}
+
+	// Create the event with its creator as owner
+	err = h.Events.CreateEvent(r.Context(), &event, user.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to create event")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, itemResponse{Data: toAPIEvent(event)})
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/layout"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// apiField is the JSON representation of a form field
+type apiField struct {
+	ID          uint                      `json:"id"`
+	FormID      uint                      `json:"form_id"`
+	Ref         string                    `json:"ref"`
+	Step        int                       `json:"step"`
+	FieldType   string                    `json:"field_type"`
+	Label       string                    `json:"label"`
+	Placeholder string                    `json:"placeholder"`
+	Options     database.FieldOptions     `json:"options"`
+	Constraints database.FieldConstraints `json:"constraints"`
+	ShowIf      database.Condition        `json:"show_if"`
+	IsRequired  bool                      `json:"is_required"`
+	FieldOrder  int                       `json:"field_order"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// apiFieldInput is the request body for creating or updating a form field
+type apiFieldInput struct {
+	FormID      *uint                      `json:"form_id"`
+	Step        *int                       `json:"step"`
+	FieldType   *string                    `json:"field_type"`
+	Label       *string                    `json:"label"`
+	Placeholder *string                    `json:"placeholder"`
+	Options     *database.FieldOptions     `json:"options"`
+	Constraints *database.FieldConstraints `json:"constraints"`
+	ShowIf      *database.Condition        `json:"show_if"`
+	IsRequired  *bool                      `json:"is_required"`
+	FieldOrder  *int                       `json:"field_order"`
+}
+
+// toAPIField converts a form field model to its JSON representation
+func toAPIField(field database.FormField) apiField {
+	return apiField{
+		ID:          field.ID,
+		FormID:      field.FormID,
+		Ref:         field.Ref,
+		Step:        field.Step,
+		FieldType:   field.FieldType,
+		Label:       field.Label,
+		Placeholder: field.Placeholder,
+		Options:     field.Options,
+		Constraints: field.Constraints,
+		ShowIf:      field.ShowIf,
+		IsRequired:  field.IsRequired,
+		FieldOrder:  field.FieldOrder,
+		CreatedAt:   field.CreatedAt,
+		UpdatedAt:   field.UpdatedAt,
+	}
+}
+
+// apply copies the provided input values onto field
+func (in apiFieldInput) apply(ctx context.Context, forms store.FormStore, field *database.FormField) string {
+	if in.FormID != nil {
+		if field.ID != 0 && *in.FormID != field.FormID {
+			return "form_id cannot be changed"
+		}
+		if _, err := forms.Form(ctx, *in.FormID); err != nil {
+			return "form_id does not reference an existing form"
+		}
+		field.FormID = *in.FormID
+	}
+	if in.Step != nil {
+		if *in.Step < 1 {
+			return "step must be at least 1"
+		}
+		field.Step = *in.Step
+	}
+	if in.FieldType != nil {
+		field.FieldType = *in.FieldType
+	}
+	if in.Label != nil {
+		field.Label = strings.TrimSpace(*in.Label)
+	}
+	if in.Placeholder != nil {
+		field.Placeholder = *in.Placeholder
+	}
+	if in.Options != nil {
+		field.Options = *in.Options
+	}
+	if in.Constraints != nil {
+		field.Constraints = *in.Constraints
+	}
+	if in.ShowIf != nil {
+		field.ShowIf = *in.ShowIf
+	}
+	if in.IsRequired != nil {
+		field.IsRequired = *in.IsRequired
+	}
+	if in.FieldOrder != nil {
+		if *in.FieldOrder < 1 {
+			return "field_order must be at least 1"
+		}
+		field.FieldOrder = *in.FieldOrder
+	}
+
+	if field.FormID == 0 {
+		return "form_id is required"
+	}
+	if !database.IsValidFieldType(field.FieldType) {
+		return "field_type must be one of: " + strings.Join(database.FieldTypes, ", ")
+	}
+	if field.Label == "" {
+		return "label is required"
+	}
+
+	options, err := database.NormalizeFieldOptions(field.FieldType, field.Options)
+	if err != nil {
+		return "options: " + err.Error()
+	}
+	field.Options = options
+
+	constraints, err := database.NormalizeFieldConstraints(field.FieldType, field.Constraints)
+	if err != nil {
+		return "constraints: " + err.Error()
+	}
+	field.Constraints = constraints
+
+	if field.ShowIf.IsSet() {
+		fields, err := forms.Fields(ctx, field.FormID)
+		if err != nil {
+			return "show_if could not be checked"
+		}
+		showIf, err := database.NormalizeShowIf(*field, fields, field.ShowIf)
+		if err != nil {
+			return "show_if: " + err.Error()
+		}
+		field.ShowIf = showIf
+	}
+	return ""
+}
+
+// APIFieldsHandler lists and creates form fields
+func (h *Handler) APIFieldsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listAPIFields(w, r)
+	case http.MethodPost:
+		h.createAPIField(w, r)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// APIFieldHandler reads, updates and deletes a single form field
+func (h *Handler) APIFieldHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseAPIID(r, "/api/v1/fields/")
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Field not found")
+		return
+	}
+
+	// Get field
+	field, err := h.Forms.Field(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Field")
+		return
+	}
+
+	eventID, err := h.formEventID(r.Context(), field.FormID)
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+	if !h.apiRequireEventRole(w, r, eventID, requiredRole(r.Method)) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, itemResponse{Data: toAPIField(field)})
+
+	case http.MethodPut, http.MethodPatch:
+		var input apiFieldInput
+		err = decodeJSONBody(w, r, &input)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		fromStep, fromOrder, fromType := field.Step, field.FieldOrder, field.FieldType
+		if msg := input.apply(r.Context(), h.Forms, &field); msg != "" {
+			writeAPIError(w, http.StatusUnprocessableEntity, msg)
+			return
+		}
+		if field.FieldType != fromType {
+			answered, err := h.Submissions.FieldAnswered(r.Context(), field.ID)
+			if err != nil {
+				writeAPIError(w, http.StatusInternalServerError, "Failed to update field")
+				return
+			}
+			if answered {
+				writeAPIError(w, http.StatusConflict, "field_type cannot be changed once the field has answers")
+				return
+			}
+		}
+
+		// Changes of step or order move the field, shifting the others
+		var change layout.Change
+		if field.Step != fromStep || field.FieldOrder != fromOrder {
+			change = layout.MoveField(field.ID, field.Step, field.FieldOrder)
+		}
+		err = h.Forms.SaveField(r.Context(), &field, change)
+		if err != nil {
+			writeAPILayoutError(w, "Failed to update field", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, itemResponse{Data: toAPIField(field)})
+
+	case http.MethodDelete:
+		// Delete the field and close the gap it leaves in its step
+		err := h.Forms.DeleteField(r.Context(), field)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "Failed to delete field")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// listAPIFields returns a page of form fields, optionally filtered by form, step and type
+func (h *Handler) listAPIFields(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiRequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := parsePagination(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := store.FieldFilter{UserID: user.ID}
+
+	formID, ok, err := parseUintQuery(r, "form_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if ok {
+		id := uint(formID)
+		filter.FormID = &id
+	}
+
+	step, ok, err := parseUintQuery(r, "step")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if ok {
+		n := int(step)
+		filter.Step = &n
+	}
+
+	filter.FieldType = r.URL.Query().Get("field_type")
+
+	fields, total, err := h.Forms.SearchFields(r.Context(), filter, (p.Page-1)*p.PerPage, p.PerPage)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch fields")
+		return
+	}
+	p.Total = total
+
+	data := make([]apiField, 0, len(fields))
+	for _, field := range fields {
+		data = append(data, toAPIField(field))
+	}
+
+	writeJSON(w, http.StatusOK, listResponse{Data: data, Pagination: p})
+}
+
+// createAPIField creates a form field from a JSON body
+func (h *Handler) createAPIField(w http.ResponseWriter, r *http.Request) {
+	// Require a user before looking up what the input references, so that
+	// anonymous callers cannot probe which records exist
+	if _, ok := apiRequireUser(w, r); !ok {
+		return
+	}
+
+	var input apiFieldInput
+	err := decodeJSONBody(w, r, &input)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	field := database.FormField{Step: 1}
+	if msg := input.apply(r.Context(), h.Forms, &field); msg != "" {
+		writeAPIError(w, http.StatusUnprocessableEntity, msg)
+		return
+	}
+
+	eventID, err := h.formEventID(r.Context(), field.FormID)
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+	if !h.apiRequireEventRole(w, r, eventID, database.RoleEditor) {
+		return
+	}
+
+	// Append the field to the end of its step, then move it into place if an order was given
+	position := 0
+	if input.FieldOrder != nil {
+		position = field.FieldOrder
+	}
+	err = h.Forms.CreateField(r.Context(), &field, position)
+	if err != nil {
+		writeAPILayoutError(w, "Failed to create field", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, itemResponse{Data: toAPIField(field)})
+}
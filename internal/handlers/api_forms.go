@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/notify"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// apiForm is the JSON representation of a form
+type apiForm struct {
+	ID               uint               `json:"id"`
+	EventID          uint               `json:"event_id"`
+	Slug             string             `json:"slug"`
+	Title            string             `json:"title"`
+	IsMultiStep      bool               `json:"is_multi_step"`
+	IsPublished      bool               `json:"is_published"`
+	VersionID        *uint              `json:"published_version_id"`
+	StepRules        database.StepRules `json:"step_rules"`
+	NotifyMode       string             `json:"notify_mode"`
+	SendReceipts     bool               `json:"send_receipts"`
+	OpensAt          *time.Time         `json:"opens_at"`
+	ClosesAt         *time.Time         `json:"closes_at"`
+	MaxSubmissions   int                `json:"max_submissions"`
+	MaxPerRespondent int                `json:"max_per_respondent"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	Fields           []apiField         `json:"fields,omitempty"`
+}
+
+// apiFormInput is the request body for creating or updating a form
+type apiFormInput struct {
+	EventID          *uint               `json:"event_id"`
+	Title            *string             `json:"title"`
+	IsMultiStep      *bool               `json:"is_multi_step"`
+	IsPublished      *bool               `json:"is_published"`
+	StepRules        *database.StepRules `json:"step_rules"`
+	NotifyMode       *string             `json:"notify_mode"`
+	SendReceipts     *bool               `json:"send_receipts"`
+	OpensAt          nullableTime        `json:"opens_at"`
+	ClosesAt         nullableTime        `json:"closes_at"`
+	MaxSubmissions   *int                `json:"max_submissions"`
+	MaxPerRespondent *int                `json:"max_per_respondent"`
+}
+
+// nullableTime is an optional time in a request body, which may be given as
+// null to clear it
+type nullableTime struct {
+	Set  bool
+	Time sql.NullTime
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *nullableTime) UnmarshalJSON(b []byte) error {
+	t.Set = true
+	if string(b) == "null" {
+		t.Time = sql.NullTime{}
+		return nil
+	}
+	var value time.Time
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	t.Time = sql.NullTime{Time: value, Valid: true}
+	return nil
+}
+
+// toAPIForm converts a form model to its JSON representation
+func toAPIForm(form database.Form) apiForm {
+	if form.StepRules == nil {
+		form.StepRules = database.StepRules{}
+	}
+	data := apiForm{
+		ID:               form.ID,
+		EventID:          form.EventID,
+		Slug:             form.Slug,
+		Title:            form.Title,
+		IsMultiStep:      form.IsMultiStep,
+		IsPublished:      form.IsPublished,
+		VersionID:        form.PublishedVersionID,
+		StepRules:        form.StepRules,
+		NotifyMode:       form.NotifyMode,
+		SendReceipts:     form.SendReceipts,
+		MaxSubmissions:   form.MaxSubmissions,
+		MaxPerRespondent: form.MaxPerRespondent,
+		CreatedAt:        form.CreatedAt,
+		UpdatedAt:        form.UpdatedAt,
+	}
+	if form.OpensAt.Valid {
+		opensAt := form.OpensAt.Time
+		data.OpensAt = &opensAt
+	}
+	if form.ClosesAt.Valid {
+		closesAt := form.ClosesAt.Time
+		data.ClosesAt = &closesAt
+	}
+	return data
+}
+
+// toAPIFormWithFields converts a form model to its JSON representation,
+// including its fields
+func (h *Handler) toAPIFormWithFields(ctx context.Context, form database.Form) (apiForm, error) {
+	fields, err := h.Forms.Fields(ctx, form.ID)
+	if err != nil {
+		return apiForm{}, err
+	}
+
+	data := toAPIForm(form)
+	data.Fields = make([]apiField, 0, len(fields))
+	for _, field := range fields {
+		data.Fields = append(data.Fields, toAPIField(field))
+	}
+	return data, nil
+}
+
+// apply copies the provided input values onto form
+func (in apiFormInput) apply(ctx context.Context, events store.EventStore, forms store.FormStore, form *database.Form) string {
+	if in.EventID != nil {
+		if _, err := events.Event(ctx, *in.EventID); err != nil {
+			return "event_id does not reference an existing event"
+		}
+		form.EventID = *in.EventID
+	}
+	if in.Title != nil {
+		form.Title = strings.TrimSpace(*in.Title)
+	}
+	if in.IsMultiStep != nil {
+		form.IsMultiStep = *in.IsMultiStep
+	}
+	if in.IsPublished != nil {
+		form.IsPublished = *in.IsPublished
+	}
+	if in.NotifyMode != nil {
+		if !notify.IsValidNotifyMode(*in.NotifyMode) {
+			return "notify_mode must be one of: " + strings.Join(notify.NotifyModes, ", ")
+		}
+		form.NotifyMode = *in.NotifyMode
+	}
+	if in.SendReceipts != nil {
+		form.SendReceipts = *in.SendReceipts
+	}
+	if in.OpensAt.Set || in.ClosesAt.Set || in.MaxSubmissions != nil || in.MaxPerRespondent != nil {
+		if in.OpensAt.Set {
+			form.OpensAt = in.OpensAt.Time
+		}
+		if in.ClosesAt.Set {
+			form.ClosesAt = in.ClosesAt.Time
+		}
+		if in.MaxSubmissions != nil {
+			form.MaxSubmissions = *in.MaxSubmissions
+		}
+		if in.MaxPerRespondent != nil {
+			form.MaxPerRespondent = *in.MaxPerRespondent
+		}
+		if err := database.CheckLimits(*form); err != nil {
+			return err.Error()
+		}
+		// Only transitions after now are acted on by the scheduler
+		form.ScheduleState = form.ScheduleAt(time.Now())
+	}
+
+	if form.EventID == 0 {
+		return "event_id is required"
+	}
+	if form.Title == "" {
+		return "title is required"
+	}
+
+	if in.StepRules != nil {
+		if len(*in.StepRules) > database.MaxStepRules {
+			return "a form can have at most " + strconv.Itoa(database.MaxStepRules) + " step_rules"
+		}
+
+		// Rules refer to the form's fields, so a new form cannot have any yet
+		var fields []database.FormField
+		if form.ID != 0 {
+			var err error
+			if fields, err = forms.Fields(ctx, form.ID); err != nil {
+				return "step_rules could not be checked"
+			}
+		}
+		rules := make(database.StepRules, 0, len(*in.StepRules))
+		for i, rule := range *in.StepRules {
+			rule, err := database.NormalizeStepRule(rule, fields)
+			if err != nil {
+				return "step_rules[" + strconv.Itoa(i) + "]: " + err.Error()
+			}
+			rules = append(rules, rule)
+		}
+		form.StepRules = rules
+	}
+	return ""
+}
+
+// APIFormsHandler lists and creates forms
+func (h *Handler) APIFormsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listAPIForms(w, r)
+	case http.MethodPost:
+		h.createAPIForm(w, r)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// APIFormHandler reads, updates and deletes a single form
+func (h *Handler) APIFormHandler(w http.ResponseWriter, r *http.Request) {
+	// Results, the form definition, the layout and versions are served below the form's own path
+	if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/results") {
+		h.APIFormResultsHandler(w, r)
+		return
+	}
+	if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/definition") {
+		h.APIFormDefinitionHandler(w, r)
+		return
+	}
+	if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/layout") {
+		h.APIFormLayoutHandler(w, r)
+		return
+	}
+	if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/versions") {
+		h.APIFormVersionsHandler(w, r)
+		return
+	}
+
+	id, ok := parseAPIID(r, "/api/v1/forms/")
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Form not found")
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+
+	if !h.apiRequireEventRole(w, r, form.EventID, requiredRole(r.Method)) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// Include the form's fields
+		data, err := h.toAPIFormWithFields(r.Context(), form)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "Failed to fetch form fields")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, itemResponse{Data: data})
+
+	case http.MethodPut, http.MethodPatch:
+		var input apiFormInput
+		err := decodeJSONBody(w, r, &input)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if msg := input.apply(r.Context(), h.Events, h.Forms, &form); msg != "" {
+			writeAPIError(w, http.StatusUnprocessableEntity, msg)
+			return
+		}
+
+		// Moving a form to another event requires edit access there too
+		if !h.apiRequireEventRole(w, r, form.EventID, database.RoleEditor) {
+			return
+		}
+
+		// Publishing snapshots the draft into a new version if it has changed
+		err = h.Forms.SaveForm(r.Context(), &form, input.IsPublished != nil && *input.IsPublished)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "Failed to update form")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, itemResponse{Data: toAPIForm(form)})
+
+	case http.MethodDelete:
+		// Delete the form together with its fields
+		err := h.Forms.DeleteForm(r.Context(), form)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "Failed to delete form")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// listAPIForms returns a page of forms, optionally filtered by event, publish state and title
+func (h *Handler) listAPIForms(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiRequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := parsePagination(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := store.FormFilter{UserID: user.ID}
+
+	eventID, ok, err := parseUintQuery(r, "event_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if ok {
+		id := uint(eventID)
+		filter.EventID = &id
+	}
+
+	published, ok, err := parseBoolQuery(r, "is_published")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if ok {
+		filter.IsPublished = &published
+	}
+
+	filter.Title = strings.TrimSpace(r.URL.Query().Get("q"))
+
+	forms, total, err := h.Forms.SearchForms(r.Context(), filter, (p.Page-1)*p.PerPage, p.PerPage)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch forms")
+		return
+	}
+	p.Total = total
+
+	data := make([]apiForm, 0, len(forms))
+	for _, form := range forms {
+		data = append(data, toAPIForm(form))
+	}
+
+	writeJSON(w, http.StatusOK, listResponse{Data: data, Pagination: p})
+}
+
+// createAPIForm creates a form from a JSON body
+func (h *Handler) createAPIForm(w http.ResponseWriter, r *http.Request) {
+	// Require a user before looking up what the input references, so that
+	// anonymous callers cannot probe which records exist
+	if _, ok := apiRequireUser(w, r); !ok {
+		return
+	}
+
+	var input apiFormInput
+	err := decodeJSONBody(w, r, &input)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var form database.Form
+	if msg := input.apply(r.Context(), h.Events, h.Forms, &form); msg != "" {
+		writeAPIError(w, http.StatusUnprocessableEntity, msg)
+		return
+	}
+
+	if !h.apiRequireEventRole(w, r, form.EventID, database.RoleEditor) {
+		return
+	}
+
+	err = h.Forms.CreateForm(r.Context(), &form)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to create form")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, itemResponse{Data: toAPIForm(form)})
+}
+
+// apiFormVersion is the JSON representation of a published version of a form
+type apiFormVersion struct {
+	ID          uint               `json:"id"`
+	FormID      uint               `json:"form_id"`
+	Number      int                `json:"number"`
+	Title       string             `json:"title"`
+	IsMultiStep bool               `json:"is_multi_step"`
+	StepRules   database.StepRules `json:"step_rules"`
+	Fields      []apiField         `json:"fields"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// APIFormVersionsHandler handles GET /api/v1/forms/{id}/versions, which lists
+// the published versions of a form, newest first
+func (h *Handler) APIFormVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/versions")
+	id, err := strconv.ParseUint(strings.TrimPrefix(path, "/api/v1/forms/"), 10, 64)
+	if err != nil || id == 0 {
+		writeAPIError(w, http.StatusNotFound, "Form not found")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+
+	if !h.apiRequireEventRole(w, r, form.EventID, database.RoleViewer) {
+		return
+	}
+
+	versions, err := h.Forms.Versions(r.Context(), form.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch versions")
+		return
+	}
+
+	data := make([]apiFormVersion, 0, len(versions))
+	for _, version := range versions {
+		item := apiFormVersion{
+			ID:          version.ID,
+			FormID:      version.FormID,
+			Number:      version.Number,
+			Title:       version.Title,
+			IsMultiStep: version.IsMultiStep,
+			StepRules:   version.StepRules,
+			Fields:      make([]apiField, 0, len(version.Fields)),
+			CreatedAt:   version.CreatedAt,
+		}
+		for _, field := range version.FormFields() {
+			item.Fields = append(item.Fields, toAPIField(field))
+		}
+		data = append(data, item)
+	}
+
+	writeJSON(w, http.StatusOK, itemResponse{Data: data})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/layout"
+)
+
+// apiLayoutInput is the request body for arranging the fields of a form
+type apiLayoutInput struct {
+	Steps [][]uint `json:"steps"` // field IDs of each step in order, starting with step 1
+}
+
+// APIFormLayoutHandler handles PUT /api/v1/forms/{id}/layout, which sets the
+// order of every field of a form and the step each of them is on
+func (h *Handler) APIFormLayoutHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/layout")
+	id, err := strconv.ParseUint(strings.TrimPrefix(path, "/api/v1/forms/"), 10, 64)
+	if err != nil || id == 0 {
+		writeAPIError(w, http.StatusNotFound, "Form not found")
+		return
+	}
+
+	if r.Method != http.MethodPut {
+		writeMethodNotAllowed(w, http.MethodPut)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+
+	if !h.apiRequireEventRole(w, r, form.EventID, database.RoleEditor) {
+		return
+	}
+
+	var input apiLayoutInput
+	err = decodeJSONBody(w, r, &input)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.Forms.Rearrange(r.Context(), &form, layout.Arrange(input.Steps))
+	if err != nil {
+		writeAPILayoutError(w, "Failed to arrange fields", err)
+		return
+	}
+
+	data, err := h.toAPIFormWithFields(r.Context(), form)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch form fields")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, itemResponse{Data: data})
+}
+
+// writeAPILayoutError reports a layout change that was rejected as invalid,
+// or logs any other error and reports msg
+func writeAPILayoutError(w http.ResponseWriter, msg string, err error) {
+	var invalid *layout.InvalidError
+	if errors.As(err, &invalid) {
+		writeAPIError(w, http.StatusUnprocessableEntity, invalid.Reason)
+		return
+	}
+	log.Printf("%s: %v", msg, err)
+	writeAPIError(w, http.StatusInternalServerError, msg)
+}
@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+	"github.com/yourusername/event-feedback/internal/validation"
+)
+
+// apiSubmission is the JSON representation of a submission
+type apiSubmission struct {
+	ID            uint          `json:"id"`
+	FormID        uint          `json:"form_id"`
+	FormVersionID *uint         `json:"form_version_id"`
+	SubmissionKey string        `json:"submission_key"`
+	Status        string        `json:"status"`
+	FlagReason    string        `json:"flag_reason,omitempty"`
+	CurrentStep   int           `json:"current_step"`
+	CompletedAt   *time.Time    `json:"completed_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Responses     []apiResponse `json:"responses,omitempty"`
+}
+
+// apiResponse is the JSON representation of an answer to a single field.
+// Answers to multi-value fields are also given as a list in Values, and
+// may be written either way.
+type apiResponse struct {
+	FieldID  uint     `json:"field_id"`
+	Response string   `json:"response"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// apiSubmissionInput is the request body for creating or updating a submission
+type apiSubmissionInput struct {
+	FormID      *uint         `json:"form_id"`
+	Status      *string       `json:"status"`
+	CurrentStep *int          `json:"current_step"`
+	Responses   []apiResponse `json:"responses"`
+}
+
+// toAPISubmission converts a submission model to its JSON representation
+func toAPISubmission(submission database.Submission) apiSubmission {
+	data := apiSubmission{
+		ID:            submission.ID,
+		FormID:        submission.FormID,
+		FormVersionID: submission.FormVersionID,
+		SubmissionKey: submission.SubmissionKey,
+		Status:        submission.Status,
+		FlagReason:    submission.FlagReason,
+		CurrentStep:   submission.CurrentStep,
+		CreatedAt:     submission.CreatedAt,
+		UpdatedAt:     submission.UpdatedAt,
+	}
+	if submission.CompletedAt.Valid {
+		completedAt := submission.CompletedAt.Time
+		data.CompletedAt = &completedAt
+	}
+	return data
+}
+
+// apply copies the provided input values onto submission
+func (in apiSubmissionInput) apply(ctx context.Context, forms store.FormStore, submission *database.Submission) string {
+	if in.FormID != nil {
+		if submission.ID != 0 && *in.FormID != submission.FormID {
+			return "form_id cannot be changed"
+		}
+		form, err := forms.Form(ctx, *in.FormID)
+		if err != nil {
+			return "form_id does not reference an existing form"
+		}
+		if submission.ID == 0 {
+			submission.FormVersionID = form.PublishedVersionID
+		}
+		submission.FormID = *in.FormID
+	}
+	if in.CurrentStep != nil {
+		if *in.CurrentStep < 1 {
+			return "current_step must be at least 1"
+		}
+		submission.CurrentStep = *in.CurrentStep
+	}
+	if in.Status != nil {
+		switch *in.Status {
+		case "in_progress":
+			submission.CompletedAt = sql.NullTime{}
+		case "completed":
+			if !submission.CompletedAt.Valid {
+				submission.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			}
+		default:
+			return "status must be in_progress or completed"
+		}
+		submission.Status = *in.Status
+	}
+
+	if submission.FormID == 0 {
+		return "form_id is required"
+	}
+	return ""
+}
+
+// errInvalidResponseField is returned when a response references a field outside the submission's form
+var errInvalidResponseField = errors.New("response references a field that does not belong to the form")
+
+// apiResponseRecords checks responses against the fields of the submission's
+// form and returns the answers to store for them
+func apiResponseRecords(fields []database.FormField, responses []apiResponse) ([]database.SubmissionResponse, error) {
+	byID := make(map[uint]database.FormField, len(fields))
+	for _, field := range fields {
+		byID[field.ID] = field
+	}
+
+	saved := make([]database.SubmissionResponse, 0, len(responses))
+	position := make(map[uint]int, len(responses))
+	for _, resp := range responses {
+		field, ok := byID[resp.FieldID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", errInvalidResponseField, resp.FieldID)
+		}
+
+		// Check the answer as the form pages do, and store it as it was
+		// checked, multi-value answers in their canonical form
+		var values []string
+		switch {
+		case !database.IsMultiValueField(field.FieldType):
+			values = []string{resp.Response}
+		case resp.Values != nil:
+			values = resp.Values
+		default:
+			values = database.SplitResponseValues(resp.Response)
+		}
+		values = validation.Normalize(values)
+		if err := validation.Answer(field, values); err != nil {
+			return nil, err
+		}
+
+		response := ""
+		if database.IsMultiValueField(field.FieldType) {
+			response = database.JoinResponseValues(values)
+		} else if len(values) > 0 {
+			response = values[0]
+		}
+
+		// A later answer to the same field in the request replaces an earlier one
+		answer := database.SubmissionResponse{
+			FieldID:  field.ID,
+			Response: response,
+		}
+		if i, ok := position[field.ID]; ok {
+			saved[i] = answer
+			continue
+		}
+		position[field.ID] = len(saved)
+		saved = append(saved, answer)
+	}
+
+	return saved, nil
+}
+
+// saveAPISubmission saves submission and its responses together, replacing
+// any existing answer to the same field. Answers are checked against the
+// fields of the form version the submission belongs to, as the form pages do.
+// from is the submission as it was stored, or empty for a new one; if the
+// submission is being completed, it is saved as the form pages save their
+// last step, checking the form's schedule and response limits and queuing
+// its notifications and webhooks.
+func (h *Handler) saveAPISubmission(w http.ResponseWriter, r *http.Request, submission *database.Submission, from database.Submission, responses []apiResponse) bool {
+	form, err := h.Forms.Form(r.Context(), submission.FormID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to save submission")
+		return false
+	}
+	form, fields, err := h.Forms.FormAsOf(r.Context(), form, submission.FormVersionID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to save submission")
+		return false
+	}
+	saved, err := apiResponseRecords(fields, responses)
+	var full *unavailable
+	if err == nil {
+		if submission.Status == "completed" && from.Status != "completed" {
+			save := store.Step{Form: form, Submission: submission, Responses: saved, OnStep: from.CurrentStep}
+			save.Check = func(submissions store.SubmissionStore) error {
+				reason, err := formAvailability(r.Context(), submissions, form, submission.RespondentKey)
+				if err != nil {
+					return err
+				}
+				if reason != nil {
+					full = reason
+					return errFormUnavailable
+				}
+				return nil
+			}
+			err = h.Submissions.SaveStep(r.Context(), save)
+		} else {
+			err = h.Submissions.SaveSubmission(r.Context(), submission, saved)
+		}
+	}
+	var answerErr *validation.Error
+	if errors.Is(err, errInvalidResponseField) || errors.As(err, &answerErr) {
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return false
+	}
+	if full != nil {
+		writeAPIError(w, http.StatusConflict, full.Heading)
+		return false
+	}
+	if err == store.ErrConflict {
+		writeAPIError(w, http.StatusConflict, "Submission was changed by another request")
+		return false
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to save submission")
+		return false
+	}
+	return true
+}
+
+// APISubmissionsHandler lists and creates submissions
+func (h *Handler) APISubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listAPISubmissions(w, r)
+	case http.MethodPost:
+		h.createAPISubmission(w, r)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// APISubmissionHandler reads, updates and deletes a single submission
+func (h *Handler) APISubmissionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseAPIID(r, "/api/v1/submissions/")
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "Submission not found")
+		return
+	}
+
+	// Get submission
+	submission, err := h.Submissions.Submission(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Submission")
+		return
+	}
+
+	eventID, err := h.formEventID(r.Context(), submission.FormID)
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+	if !h.apiRequireEventRole(w, r, eventID, requiredRole(r.Method)) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.writeAPISubmission(w, r, http.StatusOK, submission)
+
+	case http.MethodPut, http.MethodPatch:
+		var input apiSubmissionInput
+		err = decodeJSONBody(w, r, &input)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		from := submission
+		if msg := input.apply(r.Context(), h.Forms, &submission); msg != "" {
+			writeAPIError(w, http.StatusUnprocessableEntity, msg)
+			return
+		}
+
+		if !h.saveAPISubmission(w, r, &submission, from, input.Responses) {
+			return
+		}
+
+		h.writeAPISubmission(w, r, http.StatusOK, submission)
+
+	case http.MethodDelete:
+		err = h.Submissions.DeleteSubmission(r.Context(), submission)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "Failed to delete submission")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// writeAPISubmission writes a submission together with its responses
+func (h *Handler) writeAPISubmission(w http.ResponseWriter, r *http.Request, status int, submission database.Submission) {
+	responses, err := h.Submissions.Responses(r.Context(), submission.ID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch responses")
+		return
+	}
+
+	data := toAPISubmission(submission)
+	data.Responses = make([]apiResponse, 0, len(responses))
+	for _, resp := range responses {
+		item := apiResponse{
+			FieldID:  resp.FieldID,
+			Response: resp.Response,
+		}
+		if database.IsMultiValueField(resp.Field.FieldType) {
+			item.Values = database.SplitResponseValues(resp.Response)
+		}
+		data.Responses = append(data.Responses, item)
+	}
+
+	writeJSON(w, status, itemResponse{Data: data})
+}
+
+// listAPISubmissions returns a page of submissions, optionally filtered by form and status
+func (h *Handler) listAPISubmissions(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiRequireUser(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := parsePagination(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := store.SubmissionFilter{UserID: user.ID}
+
+	formID, ok, err := parseUintQuery(r, "form_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if ok {
+		id := uint(formID)
+		filter.FormID = &id
+	}
+
+	filter.Status = r.URL.Query().Get("status")
+
+	submissions, total, err := h.Submissions.SearchSubmissions(r.Context(), filter, (p.Page-1)*p.PerPage, p.PerPage)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch submissions")
+		return
+	}
+	p.Total = total
+
+	data := make([]apiSubmission, 0, len(submissions))
+	for _, submission := range submissions {
+		data = append(data, toAPISubmission(submission))
+	}
+
+	writeJSON(w, http.StatusOK, listResponse{Data: data, Pagination: p})
+}
+
+// createAPISubmission creates a submission and its responses from a JSON body
+func (h *Handler) createAPISubmission(w http.ResponseWriter, r *http.Request) {
+	// Require a user before looking up what the input references, so that
+	// anonymous callers cannot probe which records exist
+	if _, ok := apiRequireUser(w, r); !ok {
+		return
+	}
+
+	var input apiSubmissionInput
+	err := decodeJSONBody(w, r, &input)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, err := generateSubmissionKey()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to create submission")
+		return
+	}
+	submission := database.Submission{
+		SubmissionKey: key,
+		Status:        "in_progress",
+		CurrentStep:   1,
+	}
+	if msg := input.apply(r.Context(), h.Forms, &submission); msg != "" {
+		writeAPIError(w, http.StatusUnprocessableEntity, msg)
+		return
+	}
+
+	eventID, err := h.formEventID(r.Context(), submission.FormID)
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+	if !h.apiRequireEventRole(w, r, eventID, database.RoleEditor) {
+		return
+	}
+
+	if !h.saveAPISubmission(w, r, &submission, database.Submission{}, input.Responses) {
+		return
+	}
+
+	h.writeAPISubmission(w, r, http.StatusCreated, submission)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/event-feedback/internal/database"
+)
+
+func TestAPISubmissionsCheckAnswersAgainstFormVersion(t *testing.T) {
+	for _, kind := range testStores {
+		t.Run(kind, func(t *testing.T) {
+			s := newTestServer(t, kind)
+			ctx := context.Background()
+
+			// The published version limits answers in length, which the
+			// draft no longer does, and the draft gains a field of its own
+			maxLength := 5
+			s.field.Constraints.MaxLength = &maxLength
+			s.must(s.store.SaveField(ctx, &s.field, nil))
+			s.must(s.store.SaveForm(ctx, &s.form, true))
+			s.field.Constraints.MaxLength = nil
+			s.must(s.store.SaveField(ctx, &s.field, nil))
+			draftOnly := database.FormField{FormID: s.form.ID, Step: 1, FieldType: "text", Label: "Unpublished"}
+			s.must(s.store.CreateField(ctx, &draftOnly, 0))
+
+			body := `{"form_id":{form},"responses":[{"field_id":` + formatID(draftOnly.ID) + `,"response":"Hidden"}]}`
+			if rec := s.do(http.MethodPost, "/api/v1/submissions", nil, body, false); rec.Code != http.StatusUnprocessableEntity {
+				t.Errorf("answering a draft field: got status %d, want 422: %s", rec.Code, rec.Body.String())
+			}
+			body = `{"form_id":{form},"responses":[{"field_id":{field},"response":"Far too long"}]}`
+			if rec := s.do(http.MethodPost, "/api/v1/submissions", nil, body, false); rec.Code != http.StatusUnprocessableEntity {
+				t.Errorf("answering past the published limit: got status %d, want 422: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPICompletionQueuesNotifications(t *testing.T) {
+	s := newTestServer(t, "gorm")
+	s.form.NotifyMode = database.NotifyEach
+	s.must(s.store.SaveForm(context.Background(), &s.form, false))
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantEmails int64
+	}{
+		{name: "create completed", method: http.MethodPost, path: "/api/v1/submissions", body: `{"form_id":{form},"status":"completed","responses":[{"field_id":{field},"response":"Nice"}]}`, wantEmails: 1},
+		{name: "complete draft", method: http.MethodPatch, path: "/api/v1/submissions/" + formatID(s.draft.ID), body: `{"status":"completed"}`, wantEmails: 2},
+		{name: "update completed", method: http.MethodPatch, path: "/api/v1/submissions/" + formatID(s.draft.ID), body: `{"status":"completed","responses":[{"field_id":{field},"response":"Changed"}]}`, wantEmails: 2},
+	}
+	for _, step := range steps {
+		rec := s.do(step.method, step.path, nil, step.body, false)
+		if rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+			t.Fatalf("%s: got status %d: %s", step.name, rec.Code, rec.Body.String())
+		}
+		var emails int64
+		s.must(s.db.Model(&database.OutboxEmail{}).Count(&emails).Error)
+		if emails != step.wantEmails {
+			t.Errorf("%s: got %d queued emails, want %d", step.name, emails, step.wantEmails)
+		}
+	}
+}
+
+func TestAPICompletionChecksFormLimits(t *testing.T) {
+	for _, kind := range testStores {
+		t.Run(kind, func(t *testing.T) {
+			s := newTestServer(t, kind)
+			ctx := context.Background()
+
+			// The form already has the one completed submission it accepts
+			s.form.MaxSubmissions = 1
+			s.must(s.store.SaveForm(ctx, &s.form, false))
+
+			rec := s.do(http.MethodPost, "/api/v1/submissions", nil, `{"form_id":{form},"status":"completed","responses":[{"field_id":{field},"response":"Nice"}]}`, false)
+			if rec.Code != http.StatusConflict {
+				t.Errorf("creating: got status %d, want 409: %s", rec.Code, rec.Body.String())
+			}
+			rec = s.do(http.MethodPatch, "/api/v1/submissions/"+formatID(s.draft.ID), nil, `{"status":"completed"}`, false)
+			if rec.Code != http.StatusConflict {
+				t.Errorf("completing: got status %d, want 409: %s", rec.Code, rec.Body.String())
+			}
+			// Drafts can still be saved
+			rec = s.do(http.MethodPatch, "/api/v1/submissions/"+formatID(s.draft.ID), nil, `{"responses":[{"field_id":{field},"response":"More"}]}`, false)
+			if rec.Code != http.StatusOK {
+				t.Errorf("saving a draft: got status %d, want 200: %s", rec.Code, rec.Body.String())
+			}
+
+			if count, _ := s.store.CountCompleted(ctx, s.form.ID, ""); count != 1 {
+				t.Errorf("got %d completed submissions, want 1", count)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// loginPageData holds the data for the login page
+type loginPageData struct {
+	PageData
+	Next  string
+	Email string
+}
+
+// LoginHandler displays the login page and logs organizers in
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.FormValue("next"))
+
+	if r.Method != http.MethodPost {
+		data := loginPageData{
+			PageData: PageData{Title: "Log In", User: auth.UserFromContext(r.Context())},
+			Next:     next,
+		}
+		h.RenderTemplate(w, r, "login.html", data)
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	email := r.FormValue("email")
+	password := r.FormValue("password")
+
+	user, err := auth.Authenticate(r.Context(), h.Users, email, password)
+	if err != nil {
+		message := "Invalid email or password"
+		if err != auth.ErrInvalidCredentials {
+			log.Printf("Login failed: %v", err)
+			message = "Login failed, please try again"
+		}
+
+		data := loginPageData{
+			PageData: PageData{Title: "Log In", Error: message},
+			Next:     next,
+			Email:    email,
+		}
+		h.RenderTemplate(w, r, "login.html", data)
+		return
+	}
+
+	err = auth.StartSession(w, r, h.Users, user)
+	if err != nil {
+		http.Error(w, "Failed to start session", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// LogoutHandler ends the current session
+func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := auth.EndSession(w, r, h.Users)
+	if err != nil {
+		log.Printf("Failed to delete session: %v", err)
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// SignupHandler displays the signup page and creates organizer accounts
+func (h *Handler) SignupHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		data := PageData{
+			Title: "Create Organizer Account",
+		}
+		h.RenderTemplate(w, r, "signup.html", data)
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Get form values
+	name := strings.TrimSpace(r.FormValue("name"))
+	email := auth.NormalizeEmail(r.FormValue("email"))
+	password := r.FormValue("password")
+	confirm := r.FormValue("confirm_password")
+
+	// Validate values
+	errMsg := ""
+	switch {
+	case name == "" || email == "" || password == "":
+		errMsg = "Name, email and password are required"
+	case !strings.Contains(email, "@"):
+		errMsg = "Please enter a valid email address"
+	case len(password) < auth.MinPasswordLength:
+		errMsg = "Password must be at least 8 characters"
+	case password != confirm:
+		errMsg = "Passwords do not match"
+	}
+
+	if errMsg == "" {
+		_, err := h.Users.UserByEmail(r.Context(), email)
+		switch {
+		case err == nil:
+			errMsg = "An account with this email already exists"
+		case err != store.ErrNotFound:
+			http.Error(w, "Failed to check email", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if errMsg != "" {
+		data := PageData{
+			Title: "Create Organizer Account",
+			Error: errMsg,
+		}
+		h.RenderTemplate(w, r, "signup.html", data)
+		return
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		http.Error(w, "Failed to create account", http.StatusInternalServerError)
+		return
+	}
+
+	// Create user
+	user := database.User{
+		Email:        email,
+		Name:         name,
+		PasswordHash: hash,
+	}
+	err = h.Users.CreateUser(r.Context(), &user)
+	if err != nil {
+		http.Error(w, "Failed to create account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = auth.StartSession(w, r, h.Users, &user)
+	if err != nil {
+		http.Error(w, "Failed to start session", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/events", http.StatusSeeOther)
+}
+
+// safeRedirect returns target if it is a local path, otherwise the events page
+func safeRedirect(target string) string {
+	if target == "" || !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return "/events"
+	}
+	return target
+}
+
+// requireUser returns the logged-in user, redirecting to the login page if there is none
+func requireUser(w http.ResponseWriter, r *http.Request) (*database.User, bool) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		next := r.URL.RequestURI()
+		if r.Method != http.MethodGet {
+			next = r.Referer()
+			if u, err := url.Parse(next); err == nil {
+				next = u.RequestURI()
+			}
+		}
+		http.Redirect(w, r, "/login?next="+url.QueryEscape(next), http.StatusSeeOther)
+		return nil, false
+	}
+	return user, true
+}
+
+// requireEventRole checks that the logged-in user has at least the given role on an event.
+// It returns the user's actual role, or writes a redirect or error response and returns false.
+func (h *Handler) requireEventRole(w http.ResponseWriter, r *http.Request, eventID uint, role string) (string, bool) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return "", false
+	}
+
+	actual, err := h.Events.EventRole(r.Context(), user.ID, eventID)
+	if err != nil {
+		http.Error(w, "Failed to check permissions", http.StatusInternalServerError)
+		return "", false
+	}
+
+	if !auth.HasRole(actual, role) {
+		http.Error(w, "You do not have permission to do that", http.StatusForbidden)
+		return "", false
+	}
+	return actual, true
+}
+
+// apiRequireUser returns the logged-in user, writing a 401 JSON error if there is none
+func apiRequireUser(w http.ResponseWriter, r *http.Request) (*database.User, bool) {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="event-feedback"`)
+		writeAPIError(w, http.StatusUnauthorized, "Authentication required")
+		return nil, false
+	}
+	return user, true
+}
+
+// apiRequireEventRole checks that the logged-in user has at least the given role on an event,
+// writing a JSON error response if not
+func (h *Handler) apiRequireEventRole(w http.ResponseWriter, r *http.Request, eventID uint, role string) bool {
+	user, ok := apiRequireUser(w, r)
+	if !ok {
+		return false
+	}
+
+	actual, err := h.Events.EventRole(r.Context(), user.ID, eventID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return false
+	}
+
+	if actual == "" {
+		// Don't reveal that events the user cannot see exist
+		writeAPIError(w, http.StatusNotFound, "Resource not found")
+		return false
+	}
+	if !auth.HasRole(actual, role) {
+		writeAPIError(w, http.StatusForbidden, "You do not have permission to do that")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// errFormUnavailable stops a step from being saved to a form found closed or full
+var errFormUnavailable = errors.New("form is unavailable")
+
+// unavailable explains to a respondent why a form cannot be answered
+type unavailable struct {
+	Heading string
+	Message string
+}
+
+// formUnavailablePageData is the page shown instead of a closed or full form
+type formUnavailablePageData struct {
+	PageData
+	Heading string
+	Message string
+}
+
+// formAvailability checks the schedule and response limits of a form for a
+// respondent against submissions, returning nil if they may answer it
+func formAvailability(ctx context.Context, submissions store.SubmissionStore, form database.Form, respondent string) (*unavailable, error) {
+	switch form.ScheduleAt(time.Now()) {
+	case database.ScheduleScheduled:
+		return &unavailable{
+			Heading: "This form is not open yet",
+			Message: "It opens for responses on " + form.OpensAt.Time.Format("January 2, 2006 at 3:04 PM") + ".",
+		}, nil
+	case database.ScheduleClosed:
+		return &unavailable{
+			Heading: "This form is closed",
+			Message: "It stopped accepting responses on " + form.ClosesAt.Time.Format("January 2, 2006 at 3:04 PM") + ".",
+		}, nil
+	}
+
+	if form.MaxSubmissions > 0 {
+		count, err := submissions.CountCompleted(ctx, form.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(form.MaxSubmissions) {
+			return &unavailable{
+				Heading: "This form is full",
+				Message: "It has received all the responses it accepts. Thank you for your interest.",
+			}, nil
+		}
+	}
+
+	if form.MaxPerRespondent > 0 && respondent != "" {
+		count, err := submissions.CountCompleted(ctx, form.ID, respondent)
+		if err != nil {
+			return nil, err
+		}
+		if count >= int64(form.MaxPerRespondent) {
+			return &unavailable{
+				Heading: "You have already responded",
+				Message: "This form only accepts a limited number of responses from each person, and you have reached it.",
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// renderFormUnavailable shows the respondent why they cannot answer a form
+func (h *Handler) renderFormUnavailable(w http.ResponseWriter, r *http.Request, form database.Form, event database.Event, reason *unavailable) {
+	data := formUnavailablePageData{
+		PageData: PageData{
+			Title: form.Title,
+			Form:  form,
+			Event: event,
+			User:  auth.UserFromContext(r.Context()),
+		},
+		Heading: reason.Heading,
+		Message: reason.Message,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	h.RenderTemplate(w, r, "form_unavailable.html", data)
+}
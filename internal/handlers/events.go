@@ -6,15 +6,21 @@ import (
 	"strings"
 	"time"
 
+	"github.com/yourusername/event-feedback/internal/auth"
 	"github.com/yourusername/event-feedback/internal/database"
-	"gorm.io/gorm"
+	"github.com/yourusername/event-feedback/internal/store"
 )
 
 // ListEventsHandler handles listing all events
-func ListEventsHandler(w http.ResponseWriter, r *http.Request) {
-	var events []database.Event
-	result := database.DB.Order("date desc").Find(&events)
-	if result.Error != nil {
+func (h *Handler) ListEventsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	// Only list events the user is a member of
+	events, err := h.Events.MemberEvents(r.Context(), user.ID)
+	if err != nil {
 		http.Error(w, "Failed to fetch events", http.StatusInternalServerError)
 		return
 	}
@@ -24,25 +30,34 @@ func ListEventsHandler(w http.ResponseWriter, r *http.Request) {
 		Events: events,
 	}
 
-	RenderTemplate(w, "events.html", data)
+	h.RenderTemplate(w, r, "events.html", data)
 }
 
 // NewEventHandler displays the form to create a new event
-func NewEventHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) NewEventHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireUser(w, r); !ok {
+		return
+	}
+
 	data := PageData{
 		Title: "Create New Event",
 	}
 
-	RenderTemplate(w, "new_event.html", data)
+	h.RenderTemplate(w, r, "new_event.html", data)
 }
 
 // CreateEventHandler handles the form submission to create a new event
-func CreateEventHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateEventHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
 	// Parse form
 	err := r.ParseForm()
 	if err != nil {
@@ -61,7 +76,7 @@ func CreateEventHandler(w http.ResponseWriter, r *http.Request) {
 			Title: "Create New Event",
 			Error: "Name and date are required",
 		}
-		RenderTemplate(w, "new_event.html", data)
+		h.RenderTemplate(w, r, "new_event.html", data)
 		return
 	}
 
@@ -72,7 +87,7 @@ func CreateEventHandler(w http.ResponseWriter, r *http.Request) {
 			Title: "Create New Event",
 			Error: "Invalid date format",
 		}
-		RenderTemplate(w, "new_event.html", data)
+		h.RenderTemplate(w, r, "new_event.html", data)
 		return
 	}
 
@@ -83,13 +98,14 @@ func CreateEventHandler(w http.ResponseWriter, r *http.Request) {
 		Date:        date,
 	}
 
-	result := database.DB.Create(&event)
-	if result.Error != nil {
+	// Create the event with its creator as owner
+	err = h.Events.CreateEvent(r.Context(), &event, user.ID)
+	if err != nil {
 		data := PageData{
 			Title: "Create New Event",
-			Error: "Failed to create event: " + result.Error.Error(),
+			Error: "Failed to create event: " + err.Error(),
 		}
-		RenderTemplate(w, "new_event.html", data)
+		h.RenderTemplate(w, r, "new_event.html", data)
 		return
 	}
 
@@ -97,8 +113,69 @@ func CreateEventHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/events/view/"+strconv.FormatUint(uint64(event.ID), 10), http.StatusSeeOther)
 }
 
+// DuplicateEventHandler copies an event with all of its forms. The user who
+// copies it becomes the owner of the copy.
+func (h *Handler) DuplicateEventHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Parse event ID
+	eventID, err := strconv.ParseUint(r.FormValue("event_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	// Check if event exists
+	source, err := h.Events.Event(r.Context(), uint(eventID))
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	if _, ok := h.requireEventRole(w, r, source.ID, database.RoleEditor); !ok {
+		return
+	}
+	user := auth.UserFromContext(r.Context())
+
+	event := database.Event{
+		Name:        strings.TrimSpace(r.FormValue("name")),
+		Description: source.Description,
+		Date:        source.Date,
+	}
+	if event.Name == "" {
+		event.Name = "Copy of " + source.Name
+	}
+	if dateStr := r.FormValue("date"); dateStr != "" {
+		event.Date, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			http.Error(w, "Invalid date format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Copy the event and its forms with the user as owner
+	err = h.Events.CopyEvent(r.Context(), source.ID, &event, user.ID)
+	if err != nil {
+		http.Error(w, "Failed to duplicate event: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Redirect to view the copy
+	http.Redirect(w, r, "/events/view/"+strconv.FormatUint(uint64(event.ID), 10), http.StatusSeeOther)
+}
+
 // ViewEventHandler displays an event and its forms
-func ViewEventHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ViewEventHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract event ID from URL
 	path := strings.TrimPrefix(r.URL.Path, "/events/view/")
 	id, err := strconv.ParseUint(path, 10, 64)
@@ -108,10 +185,9 @@ func ViewEventHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get event
-	var event database.Event
-	result := database.DB.First(&event, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	event, err := h.Events.Event(r.Context(), uint(id))
+	if err != nil {
+		if err == store.ErrNotFound {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
@@ -119,10 +195,14 @@ func ViewEventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	role, ok := h.requireEventRole(w, r, event.ID, database.RoleViewer)
+	if !ok {
+		return
+	}
+
 	// Get forms for this event
-	var forms []database.Form
-	result = database.DB.Where("event_id = ?", id).Find(&forms)
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+	forms, err := h.Forms.EventForms(r.Context(), event.ID)
+	if err != nil {
 		http.Error(w, "Failed to fetch forms", http.StatusInternalServerError)
 		return
 	}
@@ -131,7 +211,8 @@ func ViewEventHandler(w http.ResponseWriter, r *http.Request) {
 		Title: event.Name,
 		Event: event,
 		Forms: forms,
+		Role:  role,
 	}
 
-	RenderTemplate(w, "view_event.html", data)
+	h.RenderTemplate(w, r, "view_event.html", data)
 }
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/export"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// exportFlushEvery is the number of submissions written between flushes of the response
+const exportFlushEvery = 100
+
+// exportFilenameUnsafe matches characters not allowed in export file names
+var exportFilenameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ExportSubmissionsHandler streams all submissions of a form with one column per field
+func (h *Handler) ExportSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract form ID from URL
+	path := strings.TrimPrefix(r.URL.Path, "/forms/export/")
+	formID, err := strconv.ParseUint(path, 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
+		if err == store.ErrNotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if _, ok := h.requireEventRole(w, r, form.EventID, database.RoleViewer); !ok {
+		return
+	}
+
+	// Parse export options
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, err := export.LookupFormat(formatName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	includeInProgress, _ := strconv.ParseBool(r.URL.Query().Get("include_in_progress"))
+
+	// Get the fields answered under any version, which become the columns
+	// after the submission details
+	fields, err := h.Forms.ReportFields(r.Context(), form)
+	if err != nil {
+		http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
+		return
+	}
+
+	columns := []string{"Submission ID", "Status", "Started At", "Completed At"}
+	fieldColumn := make(map[uint]int, len(fields))
+	multiValue := make(map[uint]bool)
+	for i, field := range fields {
+		columns = append(columns, field.Label)
+		fieldColumn[field.ID] = 4 + i
+		if database.IsMultiValueField(field.FieldType) {
+			multiValue[field.ID] = true
+		}
+	}
+
+	filename := exportFilenameUnsafe.ReplaceAllString(form.Title, "_")
+	writer := format.NewWriter(w)
+	flusher, _ := w.(http.Flusher)
+	written := 0
+
+	// writeHeader starts the response once the first submission arrives, so
+	// that a failure to fetch them can still be reported as an error
+	started := false
+	writeHeader := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		w.Header().Set("Content-Type", format.ContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-submissions.%s"`, filename, format.Extension))
+		return writer.WriteHeader(columns)
+	}
+
+	err = h.Submissions.ExportSubmissions(r.Context(), form.ID, includeInProgress, func(submission database.Submission, responses []database.SubmissionResponse) error {
+		if err := writeHeader(); err != nil {
+			return err
+		}
+
+		row := make([]string, len(columns))
+		row[0] = submission.SubmissionKey
+		row[1] = submission.Status
+		row[2] = submission.CreatedAt.UTC().Format(time.RFC3339)
+		if submission.CompletedAt.Valid {
+			row[3] = submission.CompletedAt.Time.UTC().Format(time.RFC3339)
+		}
+
+		// Later responses to the same field overwrite earlier ones
+		for _, response := range responses {
+			if col, ok := fieldColumn[response.FieldID]; ok {
+				row[col] = response.Response
+				if multiValue[response.FieldID] {
+					row[col] = strings.Join(database.SplitResponseValues(response.Response), "; ")
+				}
+			}
+		}
+
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+		written++
+		if flusher != nil && written%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			http.Error(w, "Failed to fetch submissions", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Export of form %d failed: %v", form.ID, err)
+		return
+	}
+
+	if err := writeHeader(); err != nil {
+		log.Printf("Export of form %d failed: %v", form.ID, err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		log.Printf("Export of form %d failed: %v", form.ID, err)
+	}
+}
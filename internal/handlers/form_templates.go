@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/database"
+)
+
+// ListFormTemplatesHandler displays the user's template library
+func (h *Handler) ListFormTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	h.renderFormTemplatesPage(w, r, user, "")
+}
+
+// SaveFormTemplateHandler saves a form to the template library
+func (h *Handler) SaveFormTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Parse form ID
+	formID, err := strconv.ParseUint(r.FormValue("form_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid form ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
+		http.Error(w, "Form not found", http.StatusNotFound)
+		return
+	}
+
+	if _, ok := h.requireEventRole(w, r, form.EventID, database.RoleEditor); !ok {
+		return
+	}
+	user := auth.UserFromContext(r.Context())
+
+	d, err := h.formDefinition(r.Context(), form)
+	if err != nil {
+		http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := database.FormTemplate{
+		Name:        strings.TrimSpace(r.FormValue("name")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+		Definition:  d,
+		CreatedByID: user.ID,
+	}
+	if tmpl.Name == "" {
+		tmpl.Name = form.Title
+	}
+
+	err = h.FormTemplates.CreateTemplate(r.Context(), &tmpl)
+	if err != nil {
+		http.Error(w, "Failed to save template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/templates", http.StatusSeeOther)
+}
+
+// UseFormTemplateHandler creates a form from a template under an event
+func (h *Handler) UseFormTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Parse template and event IDs
+	templateID, err := strconv.ParseUint(r.FormValue("template_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+	eventID, err := strconv.ParseUint(r.FormValue("event_id"), 10, 64)
+	if err != nil {
+		h.renderFormTemplatesPage(w, r, user, "Choose an event to create the form for")
+		return
+	}
+
+	// Get template
+	tmpl, err := h.FormTemplates.UserTemplate(r.Context(), user.ID, uint(templateID))
+	if err != nil {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	// Check if event exists
+	event, err := h.Events.Event(r.Context(), uint(eventID))
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	if _, ok := h.requireEventRole(w, r, event.ID, database.RoleEditor); !ok {
+		return
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		title = tmpl.Definition.Title
+	}
+
+	form, err := h.Forms.CreateFormFrom(r.Context(), tmpl.Definition, event.ID, title)
+	if err != nil {
+		h.renderFormTemplatesPage(w, r, user, "Failed to create form: "+err.Error())
+		return
+	}
+
+	// Redirect to edit the new form
+	http.Redirect(w, r, "/forms/edit/"+strconv.FormatUint(uint64(form.ID), 10), http.StatusSeeOther)
+}
+
+// DeleteFormTemplateHandler removes a template from the library
+func (h *Handler) DeleteFormTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Parse template ID
+	templateID, err := strconv.ParseUint(r.FormValue("template_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get template
+	tmpl, err := h.FormTemplates.UserTemplate(r.Context(), user.ID, uint(templateID))
+	if err != nil {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	err = h.FormTemplates.DeleteTemplate(r.Context(), tmpl)
+	if err != nil {
+		http.Error(w, "Failed to delete template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/templates", http.StatusSeeOther)
+}
+
+// renderFormTemplatesPage renders the template library with the events the
+// user can create forms for
+func (h *Handler) renderFormTemplatesPage(w http.ResponseWriter, r *http.Request, user *database.User, errMsg string) {
+	templates, err := h.FormTemplates.UserTemplates(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch templates", http.StatusInternalServerError)
+		return
+	}
+
+	events, err := h.Events.EditorEvents(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch events", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		PageData
+		Templates []database.FormTemplate
+	}{
+		PageData: PageData{
+			Title:  "Form Templates",
+			Error:  errMsg,
+			Events: events,
+			User:   user,
+		},
+		Templates: templates,
+	}
+
+	h.RenderTemplate(w, r, "form_templates.html", data)
+}
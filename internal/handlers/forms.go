@@ -1,21 +1,32 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/yourusername/event-feedback/internal/auth"
 	"github.com/yourusername/event-feedback/internal/database"
-	"gorm.io/gorm"
+	"github.com/yourusername/event-feedback/internal/formdef"
+	"github.com/yourusername/event-feedback/internal/layout"
+	"github.com/yourusername/event-feedback/internal/metrics"
+	"github.com/yourusername/event-feedback/internal/notify"
+	"github.com/yourusername/event-feedback/internal/spam"
+	"github.com/yourusername/event-feedback/internal/store"
+	"github.com/yourusername/event-feedback/internal/validation"
 )
 
 // NewFormHandler displays the form to create a new feedback form
-func NewFormHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) NewFormHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract event ID from URL
 	path := strings.TrimPrefix(r.URL.Path, "/forms/new/")
 	eventID, err := strconv.ParseUint(path, 10, 64)
@@ -25,10 +36,9 @@ func NewFormHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get event
-	var event database.Event
-	result := database.DB.First(&event, eventID)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	event, err := h.Events.Event(r.Context(), uint(eventID))
+	if err != nil {
+		if err == store.ErrNotFound {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
@@ -36,16 +46,35 @@ func NewFormHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data := PageData{
-		Title: "Create New Form for " + event.Name,
-		Event: event,
+	if _, ok := h.requireEventRole(w, r, event.ID, database.RoleEditor); !ok {
+		return
+	}
+
+	// Get the user's templates to start the form from
+	user := auth.UserFromContext(r.Context())
+	templates, err := h.FormTemplates.UserTemplates(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch templates", http.StatusInternalServerError)
+		return
 	}
 
-	RenderTemplate(w, "new_form.html", data)
+	data := struct {
+		PageData
+		Templates []database.FormTemplate
+	}{
+		PageData: PageData{
+			Title: "Create New Form for " + event.Name,
+			Event: event,
+			User:  user,
+		},
+		Templates: templates,
+	}
+
+	h.RenderTemplate(w, r, "new_form.html", data)
 }
 
 // CreateFormHandler handles the form submission to create a new form
-func CreateFormHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateFormHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -77,13 +106,38 @@ func CreateFormHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if event exists
-	var event database.Event
-	result := database.DB.First(&event, eventID)
-	if result.Error != nil {
+	event, err := h.Events.Event(r.Context(), uint(eventID))
+	if err != nil {
 		http.Error(w, "Event not found", http.StatusNotFound)
 		return
 	}
 
+	if _, ok := h.requireEventRole(w, r, event.ID, database.RoleEditor); !ok {
+		return
+	}
+
+	// Start from a template if one was chosen
+	if templateIDStr := r.FormValue("template_id"); templateIDStr != "" {
+		templateID, err := strconv.ParseUint(templateIDStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid template ID", http.StatusBadRequest)
+			return
+		}
+		tmpl, err := h.FormTemplates.UserTemplate(r.Context(), auth.UserFromContext(r.Context()).ID, uint(templateID))
+		if err != nil {
+			http.Error(w, "Template not found", http.StatusNotFound)
+			return
+		}
+
+		form, err := h.Forms.CreateFormFrom(r.Context(), tmpl.Definition, event.ID, title)
+		if err != nil {
+			http.Error(w, "Failed to create form: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/forms/edit/"+strconv.FormatUint(uint64(form.ID), 10), http.StatusSeeOther)
+		return
+	}
+
 	// Parse is_multi_step
 	isMultiStep := isMultiStepStr == "on" || isMultiStepStr == "true"
 
@@ -95,9 +149,9 @@ func CreateFormHandler(w http.ResponseWriter, r *http.Request) {
 		IsPublished: false, // Forms are unpublished by default until fields are added
 	}
 
-	result = database.DB.Create(&form)
-	if result.Error != nil {
-		http.Error(w, "Failed to create form: "+result.Error.Error(), http.StatusInternalServerError)
+	err = h.Forms.CreateForm(r.Context(), &form)
+	if err != nil {
+		http.Error(w, "Failed to create form: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -105,8 +159,89 @@ func CreateFormHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/forms/edit/"+strconv.FormatUint(uint64(form.ID), 10), http.StatusSeeOther)
 }
 
+// DuplicateFormHandler copies a form with its fields and branching rules to
+// the same or another event
+func (h *Handler) DuplicateFormHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Parse form ID
+	formID, err := strconv.ParseUint(r.FormValue("form_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid form ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
+		http.Error(w, "Form not found", http.StatusNotFound)
+		return
+	}
+
+	if _, ok := h.requireEventRole(w, r, form.EventID, database.RoleEditor); !ok {
+		return
+	}
+
+	// Copy to the form's own event unless another one was chosen
+	eventID := form.EventID
+	if eventIDStr := r.FormValue("event_id"); eventIDStr != "" {
+		id, err := strconv.ParseUint(eventIDStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid event ID", http.StatusBadRequest)
+			return
+		}
+		event, err := h.Events.Event(r.Context(), uint(id))
+		if err != nil {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			return
+		}
+		if _, ok := h.requireEventRole(w, r, event.ID, database.RoleEditor); !ok {
+			return
+		}
+		eventID = event.ID
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		title = "Copy of " + form.Title
+	}
+
+	d, err := h.formDefinition(r.Context(), form)
+	if err != nil {
+		http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
+		return
+	}
+	duplicate, err := h.Forms.CreateFormFrom(r.Context(), d, eventID, title)
+	if err != nil {
+		http.Error(w, "Failed to duplicate form: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Redirect to edit the copy
+	http.Redirect(w, r, "/forms/edit/"+strconv.FormatUint(uint64(duplicate.ID), 10), http.StatusSeeOther)
+}
+
+// formDefinition returns the definition of the current draft of form
+func (h *Handler) formDefinition(ctx context.Context, form database.Form) (database.FormDefinition, error) {
+	fields, err := h.Forms.Fields(ctx, form.ID)
+	if err != nil {
+		return database.FormDefinition{}, err
+	}
+	return formdef.FromForm(form, fields), nil
+}
+
 // EditFormHandler displays the form editor
-func EditFormHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) EditFormHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract form ID from URL
 	path := strings.TrimPrefix(r.URL.Path, "/forms/edit/")
 	formID, err := strconv.ParseUint(path, 10, 64)
@@ -116,10 +251,9 @@ func EditFormHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get form
-	var form database.Form
-	result := database.DB.First(&form, formID)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
+		if err == store.ErrNotFound {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
@@ -127,34 +261,76 @@ func EditFormHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	role, ok := h.requireEventRole(w, r, form.EventID, database.RoleEditor)
+	if !ok {
+		return
+	}
+
 	// Get form fields
-	var fields []database.FormField
-	result = database.DB.Where("form_id = ?", formID).Order("step, field_order").Find(&fields)
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+	fields, err := h.Forms.Fields(r.Context(), uint(formID))
+	if err != nil {
 		http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
 		return
 	}
 
 	// Get event
-	var event database.Event
-	result = database.DB.First(&event, form.EventID)
-	if result.Error != nil {
+	event, err := h.Events.Event(r.Context(), form.EventID)
+	if err != nil {
 		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
 		return
 	}
 
-	data := PageData{
-		Title:  "Edit Form: " + form.Title,
-		Form:   form,
-		Event:  event,
-		Fields: fields,
+	// Get the events the form can be copied to
+	events, err := h.Events.EditorEvents(r.Context(), auth.UserFromContext(r.Context()).ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch events", http.StatusInternalServerError)
+		return
+	}
+
+	// Get the version respondents are shown and whether the draft differs from it
+	published, err := h.Forms.PublishedVersion(r.Context(), form)
+	if err != nil {
+		http.Error(w, "Failed to fetch published version", http.StatusInternalServerError)
+		return
+	}
+	changed, err := h.Forms.HasDraftChanges(r.Context(), form)
+	if err != nil {
+		http.Error(w, "Failed to compare with published version", http.StatusInternalServerError)
+		return
 	}
 
-	RenderTemplate(w, "edit_form.html", data)
+	// Count the responses towards the form's limit
+	completed, err := h.Submissions.CountCompleted(r.Context(), form.ID, "")
+	if err != nil {
+		http.Error(w, "Failed to count submissions", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		PageData
+		PublishedVersion *database.FormVersion
+		HasDraftChanges  bool
+		CompletedCount   int64
+	}{
+		PageData: PageData{
+			Title:  "Edit Form: " + form.Title,
+			Form:   form,
+			Event:  event,
+			Events: events,
+			Fields: fields,
+			Role:   role,
+			User:   auth.UserFromContext(r.Context()),
+		},
+		PublishedVersion: published,
+		HasDraftChanges:  changed,
+		CompletedCount:   completed,
+	}
+
+	h.RenderTemplate(w, r, "edit_form.html", data)
 }
 
 // UpdateFormHandler handles form updates, including adding/editing fields
-func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -169,7 +345,7 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get form values
 	formIDStr := r.FormValue("form_id")
-	action := r.FormValue("action") // Can be: update_form, add_field, update_field, delete_field, publish
+	action := r.FormValue("action") // Can be: update_form, add_field, update_field, delete_field, move_field, insert_step, delete_step, move_step, add_step_rule, delete_step_rule, publish
 
 	// Validate required fields
 	if formIDStr == "" {
@@ -185,13 +361,16 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if form exists
-	var form database.Form
-	result := database.DB.First(&form, formID)
-	if result.Error != nil {
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
 		http.Error(w, "Form not found", http.StatusNotFound)
 		return
 	}
 
+	if _, ok := h.requireEventRole(w, r, form.EventID, database.RoleEditor); !ok {
+		return
+	}
+
 	// Process based on action
 	switch action {
 	case "update_form":
@@ -204,18 +383,33 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 
 		form.IsMultiStep = isMultiStepStr == "on" || isMultiStepStr == "true"
 
-		result = database.DB.Save(&form)
-		if result.Error != nil {
-			http.Error(w, "Failed to update form: "+result.Error.Error(), http.StatusInternalServerError)
+		if notifyMode := r.FormValue("notify_mode"); notifyMode != "" {
+			if !notify.IsValidNotifyMode(notifyMode) {
+				http.Error(w, "Invalid notification setting", http.StatusBadRequest)
+				return
+			}
+			form.NotifyMode = notifyMode
+		}
+		sendReceiptsStr := r.FormValue("send_receipts")
+		form.SendReceipts = sendReceiptsStr == "on" || sendReceiptsStr == "true"
+
+		if err := parseFormLimits(r, &form); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = h.Forms.SaveForm(r.Context(), &form, false)
+		if err != nil {
+			http.Error(w, "Failed to update form: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
 	case "add_step":
 		// For add_step, we just need to update the form to be multi-step
 		form.IsMultiStep = true
-		result = database.DB.Save(&form)
-		if result.Error != nil {
-			http.Error(w, "Failed to add step: "+result.Error.Error(), http.StatusInternalServerError)
+		err = h.Forms.SaveForm(r.Context(), &form, false)
+		if err != nil {
+			http.Error(w, "Failed to add step: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
@@ -228,7 +422,6 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 		fieldType := r.FormValue("field_type")
 		label := r.FormValue("label")
 		placeholder := r.FormValue("placeholder")
-		options := r.FormValue("options")
 		isRequiredStr := r.FormValue("is_required")
 
 		// Validate required fields
@@ -236,6 +429,10 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Step, field type, and label are required", http.StatusBadRequest)
 			return
 		}
+		if !database.IsValidFieldType(fieldType) {
+			http.Error(w, "field_type must be one of: "+strings.Join(database.FieldTypes, ", "), http.StatusBadRequest)
+			return
+		}
 
 		// Parse step
 		step, err := strconv.Atoi(stepStr)
@@ -243,12 +440,19 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 			step = 1
 		}
 
-		// Get highest field order for this step
-		var maxOrder int
-		database.DB.Model(&database.FormField{}).
-			Where("form_id = ? AND step = ?", formID, step).
-			Select("COALESCE(MAX(field_order), 0)").
-			Scan(&maxOrder)
+		// Check the options of choice fields
+		options, err := database.NormalizeFieldOptions(fieldType, database.ParseFieldOptions(r.FormValue("options")))
+		if err != nil {
+			http.Error(w, "Invalid options: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Check the limits answers must respect
+		constraints, err := parseFieldConstraints(r, fieldType)
+		if err != nil {
+			http.Error(w, "Invalid constraints: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 
 		// Create new field
 		field := database.FormField{
@@ -258,13 +462,21 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 			Label:       label,
 			Placeholder: placeholder,
 			Options:     options,
+			Constraints: constraints,
 			IsRequired:  isRequiredStr == "on" || isRequiredStr == "true",
-			FieldOrder:  maxOrder + 1,
 		}
 
-		result = database.DB.Create(&field)
-		if result.Error != nil {
-			http.Error(w, "Failed to add field: "+result.Error.Error(), http.StatusInternalServerError)
+		// Check the condition for showing the field
+		field.ShowIf, err = h.parseShowIf(r, field)
+		if err != nil {
+			http.Error(w, "Invalid condition: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Add it to the end of its step
+		err = h.Forms.CreateField(r.Context(), &field, 0)
+		if err != nil {
+			http.Error(w, "Failed to add field: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
@@ -274,7 +486,6 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 		fieldType := r.FormValue("field_type")
 		label := r.FormValue("label")
 		placeholder := r.FormValue("placeholder")
-		options := r.FormValue("options")
 		isRequiredStr := r.FormValue("is_required")
 
 		// Validate required fields
@@ -290,15 +501,15 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Get field
-		var field database.FormField
-		result = database.DB.First(&field, fieldID)
-		if result.Error != nil {
+		// Get field, making sure it belongs to this form
+		field, err := h.Forms.Field(r.Context(), uint(fieldID))
+		if err != nil || field.FormID != form.ID {
 			http.Error(w, "Field not found", http.StatusNotFound)
 			return
 		}
 
 		// Update field properties
+		from := field.Step
 		if stepStr != "" {
 			step, err := strconv.Atoi(stepStr)
 			if err == nil && step > 0 {
@@ -306,7 +517,22 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		if fieldType != "" {
+		if fieldType != "" && fieldType != field.FieldType {
+			if !database.IsValidFieldType(fieldType) {
+				http.Error(w, "field_type must be one of: "+strings.Join(database.FieldTypes, ", "), http.StatusBadRequest)
+				return
+			}
+			// Answers are kept under the field's ID in every version, so
+			// they would be read as answers of the new type
+			answered, err := h.Submissions.FieldAnswered(r.Context(), field.ID)
+			if err != nil {
+				http.Error(w, "Failed to check field answers", http.StatusInternalServerError)
+				return
+			}
+			if answered {
+				http.Error(w, "Field type cannot be changed once the field has answers", http.StatusConflict)
+				return
+			}
 			field.FieldType = fieldType
 		}
 
@@ -315,12 +541,31 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		field.Placeholder = placeholder
-		field.Options = options
+		field.Options, err = database.NormalizeFieldOptions(field.FieldType, database.ParseFieldOptions(r.FormValue("options")))
+		if err != nil {
+			http.Error(w, "Invalid options: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		field.Constraints, err = parseFieldConstraints(r, field.FieldType)
+		if err != nil {
+			http.Error(w, "Invalid constraints: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 		field.IsRequired = isRequiredStr == "on" || isRequiredStr == "true"
+		field.ShowIf, err = h.parseShowIf(r, field)
+		if err != nil {
+			http.Error(w, "Invalid condition: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 
-		result = database.DB.Save(&field)
-		if result.Error != nil {
-			http.Error(w, "Failed to update field: "+result.Error.Error(), http.StatusInternalServerError)
+		// A field moved to another step is appended to it
+		var change layout.Change
+		if field.Step != from {
+			change = layout.MoveField(field.ID, field.Step, math.MaxInt32)
+		}
+		err = h.Forms.SaveField(r.Context(), &field, change)
+		if err != nil {
+			writeLayoutError(w, "Failed to update field", err)
 			return
 		}
 
@@ -340,22 +585,153 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Delete field
-		result = database.DB.Delete(&database.FormField{}, fieldID)
-		if result.Error != nil {
-			http.Error(w, "Failed to delete field: "+result.Error.Error(), http.StatusInternalServerError)
+		// Get field, making sure it belongs to this form
+		field, err := h.Forms.Field(r.Context(), uint(fieldID))
+		if err != nil || field.FormID != form.ID {
+			http.Error(w, "Field not found", http.StatusNotFound)
+			return
+		}
+
+		// Delete field and close the gap it leaves in its step
+		err = h.Forms.DeleteField(r.Context(), field)
+		if err != nil {
+			http.Error(w, "Failed to delete field: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	case "move_field":
+		fieldID, err := strconv.ParseUint(r.FormValue("field_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid field ID", http.StatusBadRequest)
+			return
+		}
+
+		// Get field, making sure it belongs to this form
+		field, err := h.Forms.Field(r.Context(), uint(fieldID))
+		if err != nil || field.FormID != form.ID {
+			http.Error(w, "Field not found", http.StatusNotFound)
+			return
+		}
+
+		direction := r.FormValue("direction")
+		err = h.Forms.Rearrange(r.Context(), &form, func(form *database.Form, fields []database.FormField) ([]database.FormField, error) {
+			step, position, err := moveTarget(*form, fields, field.ID, direction)
+			if err != nil {
+				return nil, err
+			}
+			return layout.MoveField(field.ID, step, position)(form, fields)
+		})
+		if err != nil {
+			writeLayoutError(w, "Failed to move field", err)
+			return
+		}
+
+	case "insert_step", "delete_step", "move_step":
+		step, err := strconv.Atoi(r.FormValue("step"))
+		if err != nil {
+			http.Error(w, "Invalid step", http.StatusBadRequest)
+			return
+		}
+		if !form.IsMultiStep {
+			http.Error(w, "Only multi-step forms have steps", http.StatusBadRequest)
+			return
+		}
+
+		var change layout.Change
+		switch action {
+		case "insert_step":
+			change = layout.InsertStep(step)
+		case "delete_step":
+			change = layout.DeleteStep(step)
+		default:
+			to, err := strconv.Atoi(r.FormValue("to"))
+			if err != nil {
+				writeLayoutError(w, "Failed to change steps", &layout.InvalidError{Reason: "invalid target step"})
+				return
+			}
+			change = layout.MoveStep(step, to)
+		}
+		err = h.Forms.Rearrange(r.Context(), &form, change)
+		if err != nil {
+			writeLayoutError(w, "Failed to change steps", err)
+			return
+		}
+
+	case "add_step_rule":
+		step, err := strconv.Atoi(r.FormValue("step"))
+		if err != nil {
+			http.Error(w, "Invalid step", http.StatusBadRequest)
+			return
+		}
+		goTo, err := strconv.Atoi(r.FormValue("go_to")) // 0 ends the form
+		if err != nil {
+			http.Error(w, "Invalid target step", http.StatusBadRequest)
+			return
+		}
+		fieldID, err := strconv.ParseUint(r.FormValue("rule_field_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid field ID", http.StatusBadRequest)
+			return
+		}
+
+		if len(form.StepRules) >= database.MaxStepRules {
+			http.Error(w, "A form can have at most "+strconv.Itoa(database.MaxStepRules)+" rules", http.StatusBadRequest)
+			return
+		}
+
+		// Get form fields to check the rule against
+		fields, err := h.Forms.Fields(r.Context(), form.ID)
+		if err != nil {
+			http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
+			return
+		}
+
+		rule, err := database.NormalizeStepRule(database.StepRule{
+			Step: step,
+			Condition: database.Condition{
+				FieldID:  uint(fieldID),
+				Operator: r.FormValue("rule_operator"),
+				Operand:  r.FormValue("rule_value"),
+			},
+			GoTo: goTo,
+		}, fields)
+		if err != nil {
+			http.Error(w, "Invalid rule: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		form.StepRules = append(form.StepRules, rule)
+		err = h.Forms.SaveForm(r.Context(), &form, false)
+		if err != nil {
+			http.Error(w, "Failed to add rule: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	case "delete_step_rule":
+		index, err := strconv.Atoi(r.FormValue("rule_index"))
+		if err != nil || index < 0 || index >= len(form.StepRules) {
+			http.Error(w, "Rule not found", http.StatusNotFound)
+			return
+		}
+
+		form.StepRules = append(form.StepRules[:index], form.StepRules[index+1:]...)
+		err = h.Forms.SaveForm(r.Context(), &form, false)
+		if err != nil {
+			http.Error(w, "Failed to delete rule: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
 	case "publish":
 		publishStr := r.FormValue("publish")
+		publish := publishStr == "on" || publishStr == "true" || publishStr == "1"
 
-		// Update publish status
-		form.IsPublished = publishStr == "on" || publishStr == "true" || publishStr == "1"
-
-		result = database.DB.Save(&form)
-		if result.Error != nil {
-			http.Error(w, "Failed to update publish status: "+result.Error.Error(), http.StatusInternalServerError)
+		// Publishing snapshots the draft into a new version if it has changed
+		if !publish {
+			form.IsPublished = false
+		}
+		err = h.Forms.SaveForm(r.Context(), &form, publish)
+		if err != nil {
+			http.Error(w, "Failed to update publish status: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
@@ -369,7 +745,7 @@ func UpdateFormHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // ViewFormHandler displays a form for a user to fill out
-func ViewFormHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ViewFormHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract form ID from URL
 	path := strings.TrimPrefix(r.URL.Path, "/forms/view/")
 	formID, err := strconv.ParseUint(path, 10, 64)
@@ -379,10 +755,9 @@ func ViewFormHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get form
-	var form database.Form
-	result := database.DB.First(&form, formID)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
+		if err == store.ErrNotFound {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
@@ -390,62 +765,77 @@ func ViewFormHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if form is published
+	// Get event
+	event, err := h.Events.Event(r.Context(), form.EventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
+		return
+	}
+
+	// Check the form's schedule and response limits, then if it is published
+	respondent, err := h.Respondents.Identify(w, r)
+	if err != nil {
+		http.Error(w, "Failed to identify respondent", http.StatusInternalServerError)
+		return
+	}
+	reason, err := formAvailability(r.Context(), h.Submissions, form, respondent)
+	if err != nil {
+		http.Error(w, "Failed to check form availability", http.StatusInternalServerError)
+		return
+	}
+	if reason != nil {
+		h.renderFormUnavailable(w, r, form, event, reason)
+		return
+	}
 	if !form.IsPublished {
 		http.Error(w, "This form is not available", http.StatusForbidden)
 		return
 	}
 
-	// Get event
-	var event database.Event
-	result = database.DB.First(&event, form.EventID)
-	if result.Error != nil {
-		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
+	// Take the respondent back to a submission they started earlier in this browser
+	draft, err := h.Submissions.DraftSubmission(r.Context(), form.ID, respondent)
+	if err == nil {
+		http.Redirect(w, r, "/submissions/continue/"+draft.SubmissionKey, http.StatusSeeOther)
 		return
 	}
-
-	// Get form fields for the first step (or all if not multi-step)
-	var fields []database.FormField
-	query := database.DB.Where("form_id = ?", formID)
-
-	if form.IsMultiStep {
-		query = query.Where("step = ?", 1)
+	if err != store.ErrNotFound {
+		http.Error(w, "Failed to fetch submission", http.StatusInternalServerError)
+		return
 	}
 
-	result = query.Order("field_order").Find(&fields)
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+	// Get the published fields for the first step (or all if not multi-step)
+	form, flow, err := h.loadFlow(r.Context(), form, form.PublishedVersionID, 0)
+	if err != nil {
 		http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
 		return
 	}
+	firstStep := flow.First()
 
-	// Create a new submission
-	submissionKey := generateSubmissionKey()
+	// The submission is only saved once the first step is sent, so that
+	// page views alone leave nothing behind
 	submission := database.Submission{
-		FormID:        uint(formID),
-		SubmissionKey: submissionKey,
+		FormID:        form.ID,
+		FormVersionID: form.PublishedVersionID,
 		Status:        "in_progress",
-		CurrentStep:   1,
-	}
-
-	result = database.DB.Create(&submission)
-	if result.Error != nil {
-		http.Error(w, "Failed to create submission: "+result.Error.Error(), http.StatusInternalServerError)
-		return
+		CurrentStep:   firstStep,
 	}
 
+	stepNumber, stepCount := flow.Position(firstStep)
 	data := PageData{
 		Title:      form.Title,
 		Form:       form,
 		Event:      event,
-		Fields:     fields,
+		Fields:     flow.StepFields(firstStep),
 		Submission: submission,
+		StepNumber: stepNumber,
+		StepCount:  stepCount,
 	}
 
-	RenderTemplate(w, "view_form.html", data)
+	h.renderFormStep(w, r, data)
 }
 
 // SubmitFormHandler handles the form submission from users
-func SubmitFormHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) SubmitFormHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -458,24 +848,19 @@ func SubmitFormHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get submission ID and form ID
-	submissionIDStr := r.FormValue("submission_id")
+	// Get submission key and form ID. Only whoever was given the key of a
+	// submission can write to it; a missing key starts a new submission.
+	submissionKey := r.FormValue("submission_key")
 	formIDStr := r.FormValue("form_id")
 	currentStepStr := r.FormValue("current_step")
 
 	// Validate required fields
-	if submissionIDStr == "" || formIDStr == "" || currentStepStr == "" {
-		http.Error(w, "Submission ID, form ID, and current step are required", http.StatusBadRequest)
-		return
-	}
-
-	// Parse IDs and step
-	submissionID, err := strconv.ParseUint(submissionIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid submission ID", http.StatusBadRequest)
+	if formIDStr == "" || currentStepStr == "" {
+		http.Error(w, "Form ID and current step are required", http.StatusBadRequest)
 		return
 	}
 
+	// Parse form ID and step
 	formID, err := strconv.ParseUint(formIDStr, 10, 64)
 	if err != nil {
 		http.Error(w, "Invalid form ID", http.StatusBadRequest)
@@ -489,172 +874,495 @@ func SubmitFormHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get form
-	var form database.Form
-	result := database.DB.First(&form, formID)
-	if result.Error != nil {
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
 		http.Error(w, "Form not found", http.StatusNotFound)
 		return
 	}
 
-	// Get submission
+	// Get submission, or start one against the version the respondent was shown
 	var submission database.Submission
-	result = database.DB.First(&submission, submissionID)
-	if result.Error != nil {
-		http.Error(w, "Submission not found", http.StatusNotFound)
-		return
+	if submissionKey != "" {
+		submission, err = h.Submissions.SubmissionByKey(r.Context(), submissionKey)
+		if err != nil {
+			http.Error(w, "Submission not found", http.StatusNotFound)
+			return
+		}
+		if submission.FormID != form.ID {
+			http.Error(w, "Submission does not belong to this form", http.StatusBadRequest)
+			return
+		}
+		if submission.Status != "in_progress" {
+			http.Error(w, "This submission has already been submitted", http.StatusConflict)
+			return
+		}
+		// Only the step the respondent was last shown can be sent
+		if currentStep != submission.CurrentStep {
+			http.Error(w, "Invalid step", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if !form.IsPublished {
+			http.Error(w, "This form is not available", http.StatusForbidden)
+			return
+		}
+		versionID := form.PublishedVersionID
+		if versionIDStr := r.FormValue("form_version_id"); versionIDStr != "" {
+			id, err := strconv.ParseUint(versionIDStr, 10, 64)
+			if err != nil {
+				http.Error(w, "Invalid form version", http.StatusBadRequest)
+				return
+			}
+			version, err := h.Forms.Version(r.Context(), form.ID, uint(id))
+			if err != nil {
+				http.Error(w, "Invalid form version", http.StatusBadRequest)
+				return
+			}
+			versionID = &version.ID
+		}
+		respondent, err := h.Respondents.Identify(w, r)
+		if err != nil {
+			http.Error(w, "Failed to identify respondent", http.StatusInternalServerError)
+			return
+		}
+		submission = database.Submission{
+			FormID:        form.ID,
+			FormVersionID: versionID,
+			RespondentKey: respondent,
+			Status:        "in_progress",
+			CurrentStep:   currentStep,
+		}
 	}
 
-	// Get fields for the current step
-	var fields []database.FormField
-	result = database.DB.Where("form_id = ? AND step = ?", formID, currentStep).
-		Order("field_order").Find(&fields)
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+	// Work out which fields the respondent was shown, given their earlier
+	// answers and the version of the form they started
+	form, flow, err := h.loadFlow(r.Context(), form, submission.FormVersionID, submission.ID)
+	if err != nil {
 		http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
 		return
 	}
+	fields := flow.StepFields(currentStep)
+
+	// New submissions start at the first step, so none can be skipped
+	if submission.ID == 0 && currentStep != flow.First() {
+		http.Error(w, "Invalid step", http.StatusBadRequest)
+		return
+	}
+
+	// Get event
+	event, err := h.Events.Event(r.Context(), form.EventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
+		return
+	}
 
-	// Process submitted fields
+	// Respondents cannot carry on once the form has closed or filled up
+	reason, err := formAvailability(r.Context(), h.Submissions, form, submission.RespondentKey)
+	if err != nil {
+		http.Error(w, "Failed to check form availability", http.StatusInternalServerError)
+		return
+	}
+	if reason != nil {
+		h.renderFormUnavailable(w, r, form, event, reason)
+		return
+	}
+
+	// Check every answer before saving any of them
+	answers := make(map[uint]string, len(fields))
+	fieldErrors := validation.Errors{}
 	for _, field := range fields {
-		fieldIDStr := strconv.FormatUint(uint64(field.ID), 10)
-		response := r.FormValue("field_" + fieldIDStr)
-
-		// For required fields, validate response
-		if field.IsRequired && response == "" {
-			data := PageData{
-				Title:      "Error",
-				Error:      "Please fill out all required fields",
-				Form:       form,
-				Fields:     fields,
-				Submission: submission,
-			}
-			RenderTemplate(w, "view_form.html", data)
-			return
+		// Store the answer as it was checked, without surrounding whitespace
+		values := validation.Normalize(r.Form["field_"+strconv.FormatUint(uint64(field.ID), 10)])
+		if database.IsMultiValueField(field.FieldType) {
+			answers[field.ID] = database.JoinResponseValues(values)
+		} else if len(values) > 0 {
+			answers[field.ID] = values[0]
 		}
+		fieldErrors.Add(validation.Answer(field, values))
+	}
 
-		// Save response
-		submissionResponse := database.SubmissionResponse{
-			SubmissionID: uint(submissionID),
-			FieldID:      field.ID,
-			Response:     response,
+	// Show the step again with the answers given so far and what is wrong with them
+	if len(fieldErrors) > 0 {
+		stepNumber, stepCount := flow.Position(currentStep)
+		data := PageData{
+			Title:       form.Title,
+			Error:       "Please correct the highlighted fields",
+			Form:        form,
+			Event:       event,
+			Fields:      fields,
+			Submission:  submission,
+			Answers:     answers,
+			FieldErrors: fieldErrors,
+			StepNumber:  stepNumber,
+			StepCount:   stepCount,
 		}
+		h.renderFormStep(w, r, data)
+		return
+	}
 
-		result = database.DB.Create(&submissionResponse)
-		if result.Error != nil {
-			http.Error(w, "Failed to save response: "+result.Error.Error(), http.StatusInternalServerError)
-			return
-		}
+	// Branching rules see the answers just given
+	for fieldID, answer := range answers {
+		flow.SetAnswer(fieldID, answer)
 	}
 
-	// Handle form navigation (next, prev, or complete)
+	// Work out where the respondent goes next
 	action := r.FormValue("action") // next, prev, or complete
-
+	step := currentStep
 	switch action {
 	case "next":
-		// Get the next step
-		nextStep := currentStep + 1
-
-		// Update submission
-		submission.CurrentStep = nextStep
-		result = database.DB.Save(&submission)
-		if result.Error != nil {
-			http.Error(w, "Failed to update submission: "+result.Error.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Get fields for the next step
-		var nextFields []database.FormField
-		result = database.DB.Where("form_id = ? AND step = ?", formID, nextStep).
-			Order("field_order").Find(&nextFields)
-		if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
-			http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
+		step = flow.Next(currentStep)
+	case "prev":
+		step = flow.Prev(currentStep)
+	case "complete":
+		// Steps the respondent would still be shown cannot be skipped
+		if flow.Next(currentStep) != 0 {
+			http.Error(w, "Invalid action", http.StatusBadRequest)
 			return
 		}
+	default:
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
 
-		// If no fields for next step, consider the form completed
-		if len(nextFields) == 0 {
-			// Mark submission as completed
-			submission.Status = "completed"
-			submission.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
-			result = database.DB.Save(&submission)
-			if result.Error != nil {
-				http.Error(w, "Failed to update submission: "+result.Error.Error(), http.StatusInternalServerError)
+	// Moving past the last step completes the submission
+	completed := action == "complete" || (action == "next" && step == 0)
+
+	if h.Spam != nil {
+		// Respondents who do not prove they are human cannot finish
+		if completed && h.Spam.Verifier != nil {
+			if err := h.Spam.Verifier.Verify(r.Context(), r); err != nil {
+				if !errors.Is(err, spam.ErrNotVerified) {
+					log.Printf("Failed to verify submission %d: %v", submission.ID, err)
+				}
+				stepNumber, stepCount := flow.Position(currentStep)
+				data := PageData{
+					Title:      form.Title,
+					Error:      "Please complete the verification before submitting",
+					Form:       form,
+					Event:      event,
+					Fields:     fields,
+					Submission: submission,
+					Answers:    answers,
+					StepNumber: stepNumber,
+					StepCount:  stepCount,
+				}
+				h.renderFormStep(w, r, data)
 				return
 			}
-
-			// Redirect to completion page
-			http.Redirect(w, r, "/submissions/view/"+submission.SubmissionKey, http.StatusSeeOther)
-			return
 		}
 
-		// Render the next step
-		data := PageData{
-			Title:      form.Title + " - Step " + strconv.Itoa(nextStep),
-			Form:       form,
-			Fields:     nextFields,
-			Submission: submission,
+		// Steps that look like spam flag the submission for moderation;
+		// going back is exempt from the fill-time check
+		if action != "prev" {
+			submission.FlagReason = addFlagReasons(submission.FlagReason, h.Spam.Check(r, time.Now()))
 		}
+	}
 
-		RenderTemplate(w, "view_form.html", data)
-
-	case "prev":
-		// Get the previous step
-		prevStep := currentStep - 1
-		if prevStep < 1 {
-			prevStep = 1
+	if completed {
+		submission.Status = "completed"
+		if submission.FlagReason != "" {
+			submission.Status = "flagged"
 		}
+		submission.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	} else {
+		submission.CurrentStep = step
+	}
 
-		// Update submission
-		submission.CurrentStep = prevStep
-		result = database.DB.Save(&submission)
-		if result.Error != nil {
-			http.Error(w, "Failed to update submission: "+result.Error.Error(), http.StatusInternalServerError)
+	// Save the step's answers together with the submission's progress,
+	// saving the submission first if this is its first step
+	started := submission.ID == 0
+	if started {
+		submission.SubmissionKey, err = generateSubmissionKey()
+		if err != nil {
+			http.Error(w, "Failed to start submission", http.StatusInternalServerError)
 			return
 		}
+	}
+	responses := make([]database.SubmissionResponse, 0, len(fields))
+	for _, field := range fields {
+		responses = append(responses, database.SubmissionResponse{
+			FieldID:  field.ID,
+			Response: answers[field.ID],
+		})
+	}
+	save := store.Step{Form: form, Submission: &submission, Responses: responses, OnStep: currentStep}
+	if completed {
+		// Drop answers to fields the respondent ended up not being shown,
+		// e.g. on a step they went back from and then skipped
+		save.Shown = flow.ShownFields()
+	}
+	var full *unavailable
+	if submission.Status == "completed" {
+		// Check the response limits again with the form locked, so
+		// that concurrent completions cannot exceed them
+		save.Check = func(submissions store.SubmissionStore) error {
+			reason, err := formAvailability(r.Context(), submissions, form, submission.RespondentKey)
+			if err != nil {
+				return err
+			}
+			if reason != nil {
+				full = reason
+				return errFormUnavailable
+			}
+			return nil
+		}
+	}
+	err = h.Submissions.SaveStep(r.Context(), save)
+	if full != nil {
+		h.renderFormUnavailable(w, r, form, event, full)
+		return
+	}
+	if err == store.ErrConflict {
+		// Another request saved this step first, e.g. a double submit
+		http.Error(w, "This step has already been submitted", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save responses: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-		// Get fields for the previous step
-		var prevFields []database.FormField
-		result = database.DB.Where("form_id = ? AND step = ?", formID, prevStep).
-			Order("field_order").Find(&prevFields)
-		if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
-			http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
-			return
+	// Count the respondent's progress through the form
+	formLabel := metrics.FormLabel(form.ID)
+	if started {
+		h.Metrics.SubmissionsStarted.Inc(formLabel)
+	}
+	switch {
+	case submission.Status == "completed":
+		h.Metrics.SubmissionsCompleted.Inc(formLabel)
+	case submission.Status == "flagged":
+		h.Metrics.SubmissionsFlagged.Inc(formLabel)
+	case action == "next":
+		h.Metrics.StepsAdvanced.Inc(formLabel)
+	}
+
+	if completed {
+		// Redirect to completion page
+		http.Redirect(w, r, "/submissions/view/"+submission.SubmissionKey, http.StatusSeeOther)
+		return
+	}
+
+	// Render the next or previous step, prefilled with answers given earlier
+	stepNumber, stepCount := flow.Position(step)
+	data := PageData{
+		Title:      form.Title + " - Step " + strconv.Itoa(stepNumber),
+		Form:       form,
+		Event:      event,
+		Fields:     flow.StepFields(step),
+		Submission: submission,
+		Answers:    flow.Answers(),
+		StepNumber: stepNumber,
+		StepCount:  stepCount,
+	}
+
+	h.renderFormStep(w, r, data)
+}
+
+// renderFormStep shows respondents a step of a form, stamped with when it
+// was shown and, on the last step, with the challenge proving they are human
+func (h *Handler) renderFormStep(w http.ResponseWriter, r *http.Request, data PageData) {
+	if h.Spam != nil {
+		now := time.Now()
+		data.Stamp = h.Spam.Stamp(now)
+		if h.Spam.Verifier != nil && data.StepNumber >= data.StepCount {
+			challenge := h.Spam.Verifier.Challenge(now)
+			data.Challenge = &challenge
 		}
+	}
+	h.RenderTemplate(w, r, "view_form.html", data)
+}
 
-		// Render the previous step
-		data := PageData{
-			Title:      form.Title + " - Step " + strconv.Itoa(prevStep),
-			Form:       form,
-			Fields:     prevFields,
-			Submission: submission,
+// addFlagReasons adds reasons a submission looks like spam to those it was
+// flagged for before, leaving out repeats
+func addFlagReasons(flagged string, reasons []string) string {
+	for _, reason := range reasons {
+		if flagged == "" {
+			flagged = reason
+		} else if !strings.Contains(flagged, reason) {
+			flagged += "; " + reason
 		}
+	}
+	return flagged
+}
 
-		RenderTemplate(w, "view_form.html", data)
+// moveTarget returns the step and position a field moves to when it is
+// moved one place up or down. Fields at the edge of a step of a multi-step
+// form move to the end of the previous step or the start of the next one.
+func moveTarget(form database.Form, fields []database.FormField, fieldID uint, direction string) (int, int, error) {
+	var field database.FormField
+	for _, f := range fields {
+		if f.ID == fieldID {
+			field = f
+		}
+	}
+	count := 0
+	for _, f := range fields {
+		if f.Step == field.Step {
+			count++
+		}
+	}
 
-	case "complete":
-		// Mark submission as completed
-		submission.Status = "completed"
-		submission.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
-		result = database.DB.Save(&submission)
-		if result.Error != nil {
-			http.Error(w, "Failed to update submission: "+result.Error.Error(), http.StatusInternalServerError)
-			return
+	switch direction {
+	case "up":
+		if field.FieldOrder > 1 || !form.IsMultiStep || field.Step == 1 {
+			return field.Step, field.FieldOrder - 1, nil
+		}
+		return field.Step - 1, math.MaxInt32, nil
+	case "down":
+		if field.FieldOrder < count || !form.IsMultiStep {
+			return field.Step, field.FieldOrder + 1, nil
 		}
+		return field.Step + 1, 1, nil
+	default:
+		return 0, 0, &layout.InvalidError{Reason: "direction must be up or down"}
+	}
+}
 
-		// Redirect to completion page
-		http.Redirect(w, r, "/submissions/view/"+submission.SubmissionKey, http.StatusSeeOther)
+// writeLayoutError writes a 400 for changes the layout rejects and a 500 for anything else
+func writeLayoutError(w http.ResponseWriter, msg string, err error) {
+	var invalid *layout.InvalidError
+	if errors.As(err, &invalid) {
+		http.Error(w, msg+": "+invalid.Reason, http.StatusBadRequest)
+		return
+	}
+	http.Error(w, msg+": "+err.Error(), http.StatusInternalServerError)
+}
 
-	default:
-		http.Error(w, "Invalid action", http.StatusBadRequest)
+// parseShowIf reads the condition for showing field entered in the field
+// editor and checks it against the other fields of the form
+func (h *Handler) parseShowIf(r *http.Request, field database.FormField) (database.Condition, error) {
+	fieldIDStr := r.FormValue("show_if_field_id")
+	if fieldIDStr == "" {
+		return database.Condition{}, nil
 	}
+	fieldID, err := strconv.ParseUint(fieldIDStr, 10, 64)
+	if err != nil {
+		return database.Condition{}, fmt.Errorf("invalid field ID")
+	}
+
+	fields, err := h.Forms.Fields(r.Context(), field.FormID)
+	if err != nil {
+		return database.Condition{}, err
+	}
+
+	return database.NormalizeShowIf(field, fields, database.Condition{
+		FieldID:  uint(fieldID),
+		Operator: r.FormValue("show_if_operator"),
+		Operand:  r.FormValue("show_if_value"),
+	})
 }
 
-// generateSubmissionKey creates a unique key for submissions
-func generateSubmissionKey() string {
-	bytes := make([]byte, 16)
-	_, err := rand.Read(bytes)
+// parseFieldConstraints reads the answer limits entered in the field editor
+// and keeps the ones that apply to fieldType
+func parseFieldConstraints(r *http.Request, fieldType string) (database.FieldConstraints, error) {
+	var c database.FieldConstraints
+	var err error
+
+	if c.Min, err = parseOptionalFloat(r.FormValue("min")); err != nil {
+		return c, fmt.Errorf("the minimum must be a number")
+	}
+	if c.Max, err = parseOptionalFloat(r.FormValue("max")); err != nil {
+		return c, fmt.Errorf("the maximum must be a number")
+	}
+	if c.MinLength, err = parseOptionalInt(r.FormValue("min_length")); err != nil {
+		return c, fmt.Errorf("the minimum length must be a whole number")
+	}
+	if c.MaxLength, err = parseOptionalInt(r.FormValue("max_length")); err != nil {
+		return c, fmt.Errorf("the maximum length must be a whole number")
+	}
+	if c.MinSelections, err = parseOptionalInt(r.FormValue("min_selections")); err != nil {
+		return c, fmt.Errorf("the minimum selections must be a whole number")
+	}
+	if c.MaxSelections, err = parseOptionalInt(r.FormValue("max_selections")); err != nil {
+		return c, fmt.Errorf("the maximum selections must be a whole number")
+	}
+	c.Pattern = r.FormValue("pattern")
+
+	return database.NormalizeFieldConstraints(fieldType, c)
+}
+
+// parseOptionalFloat parses a number that may be left blank
+func parseOptionalFloat(s string) (*float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return &n, nil
+}
+
+// parseOptionalInt parses a whole number that may be left blank
+func parseOptionalInt(s string) (*int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(s)
 	if err != nil {
-		// Fallback to timestamp if crypto/rand fails
-		return fmt.Sprintf("%d", time.Now().UnixNano())
+		return nil, err
+	}
+	return &n, nil
+}
+
+// parseFormLimits sets the schedule and response limits of a form from the
+// editor. Only transitions after now are acted on by the scheduler.
+func parseFormLimits(r *http.Request, form *database.Form) error {
+	opensAt, err := parseOptionalTime(r.FormValue("opens_at"))
+	if err != nil {
+		return errors.New("invalid opening time")
+	}
+	closesAt, err := parseOptionalTime(r.FormValue("closes_at"))
+	if err != nil {
+		return errors.New("invalid closing time")
+	}
+	maxSubmissions, err := parseOptionalInt(r.FormValue("max_submissions"))
+	if err != nil {
+		return errors.New("invalid maximum number of responses")
+	}
+	maxPerRespondent, err := parseOptionalInt(r.FormValue("max_per_respondent"))
+	if err != nil {
+		return errors.New("invalid maximum number of responses per respondent")
+	}
+
+	form.OpensAt = opensAt
+	form.ClosesAt = closesAt
+	form.MaxSubmissions = 0
+	if maxSubmissions != nil {
+		form.MaxSubmissions = *maxSubmissions
+	}
+	form.MaxPerRespondent = 0
+	if maxPerRespondent != nil {
+		form.MaxPerRespondent = *maxPerRespondent
+	}
+	if err := database.CheckLimits(*form); err != nil {
+		return err
+	}
+	form.ScheduleState = form.ScheduleAt(time.Now())
+	return nil
+}
+
+// parseOptionalTime parses a datetime-local value in the server's time zone,
+// returning an invalid time for an empty value
+func parseOptionalTime(s string) (sql.NullTime, error) {
+	if s == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04", s, time.Local)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+// generateSubmissionKey creates a unique key for submissions. Keys give
+// access to what they identify, so there is no fallback if crypto/rand fails
+func generateSubmissionKey() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
 	}
-	return hex.EncodeToString(bytes)
+	return hex.EncodeToString(bytes), nil
 }
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// stepFixture is a two-step form with a submission started on its first
+// step, and another published form of the same event
+type stepFixture struct {
+	draft  database.Submission
+	second database.FormField
+	other  database.Form
+}
+
+// newStepFixture turns the test server's form into a two-step form
+func newStepFixture(s *testServer) stepFixture {
+	s.t.Helper()
+	ctx := context.Background()
+	var f stepFixture
+
+	s.form.IsMultiStep = true
+	s.must(s.store.SaveForm(ctx, &s.form, false))
+	f.second = database.FormField{FormID: s.form.ID, Step: 2, FieldType: "text", Label: "Anything else"}
+	s.must(s.store.CreateField(ctx, &f.second, 0))
+	s.must(s.store.SaveForm(ctx, &s.form, true))
+	f.draft = s.createSubmission("in_progress", "Started")
+
+	f.other = database.Form{EventID: s.event.ID, Title: "Other Form", IsPublished: true}
+	s.must(s.store.CreateForm(ctx, &f.other))
+	return f
+}
+
+// formatID formats a record ID for request values
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func TestSubmitFormRejectsTamperedSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   func(f stepFixture) url.Values
+		wantCode int
+		// saved is set when the draft is expected to move on
+		saved bool
+	}{
+		{
+			name: "next step",
+			values: func(f stepFixture) url.Values {
+				return url.Values{"submission_key": {f.draft.SubmissionKey}, "form_id": {"{form}"}, "current_step": {"1"}, "action": {"next"}, "field_{field}": {"Fine"}}
+			},
+			wantCode: http.StatusOK,
+			saved:    true,
+		},
+		{
+			name: "wrong form",
+			values: func(f stepFixture) url.Values {
+				return url.Values{"submission_key": {f.draft.SubmissionKey}, "form_id": {formatID(f.other.ID)}, "current_step": {"1"}, "action": {"next"}, "field_{field}": {"Fine"}}
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "completed submission",
+			values: func(f stepFixture) url.Values {
+				return url.Values{"submission_key": {"{key}"}, "form_id": {"{form}"}, "current_step": {"1"}, "action": {"complete"}, "field_{field}": {"Changed my mind"}}
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "unknown key",
+			values: func(f stepFixture) url.Values {
+				return url.Values{"submission_key": {"not-a-key"}, "form_id": {"{form}"}, "current_step": {"1"}, "action": {"next"}, "field_{field}": {"Fine"}}
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "foreign form version",
+			values: func(f stepFixture) url.Values {
+				return url.Values{"form_id": {"{form}"}, "form_version_id": {formatID(*f.other.PublishedVersionID)}, "current_step": {"1"}, "action": {"next"}, "field_{field}": {"Fine"}}
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "step mismatch",
+			values: func(f stepFixture) url.Values {
+				return url.Values{"submission_key": {f.draft.SubmissionKey}, "form_id": {"{form}"}, "current_step": {"2"}, "action": {"next"}, "field_" + formatID(f.second.ID): {"Skipped ahead"}}
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "completing early",
+			values: func(f stepFixture) url.Values {
+				return url.Values{"submission_key": {f.draft.SubmissionKey}, "form_id": {"{form}"}, "current_step": {"1"}, "action": {"complete"}, "field_{field}": {"Fine"}}
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, kind := range testStores {
+		for _, tt := range tests {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				s := newTestServer(t, kind)
+				f := newStepFixture(s)
+				ctx := context.Background()
+
+				rec := s.do(http.MethodPost, "/forms/submit/", tt.values(f), "", true)
+				if rec.Code != tt.wantCode {
+					t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+				}
+
+				// Rejected steps leave every submission as it was
+				draft, err := s.store.Submission(ctx, f.draft.ID)
+				s.must(err)
+				if saved := draft.CurrentStep != f.draft.CurrentStep; saved != tt.saved {
+					t.Errorf("draft is on step %d, want it saved: %t", draft.CurrentStep, tt.saved)
+				}
+				completed, err := s.store.Submission(ctx, s.submission.ID)
+				s.must(err)
+				if !completed.UpdatedAt.Equal(s.submission.UpdatedAt) {
+					t.Errorf("completed submission was changed")
+				}
+				if count, _ := s.store.CountCompleted(ctx, s.form.ID, ""); count != 1 {
+					t.Errorf("got %d completed submissions, want 1", count)
+				}
+				if count, _ := s.store.CountCompleted(ctx, f.other.ID, ""); count != 0 {
+					t.Errorf("got %d submissions to the other form, want 0", count)
+				}
+			})
+		}
+	}
+}
+
+func TestSubmitFormCompletesOnLastStep(t *testing.T) {
+	for _, kind := range testStores {
+		t.Run(kind, func(t *testing.T) {
+			s := newTestServer(t, kind)
+			f := newStepFixture(s)
+
+			// Move on to the last step, then complete the submission there
+			rec := s.do(http.MethodPost, "/forms/submit/", url.Values{"submission_key": {f.draft.SubmissionKey}, "form_id": {"{form}"}, "current_step": {"1"}, "action": {"next"}, "field_{field}": {"Fine"}}, "", true)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got status %d for step 1, want 200", rec.Code)
+			}
+			rec = s.do(http.MethodPost, "/forms/submit/", url.Values{"submission_key": {f.draft.SubmissionKey}, "form_id": {"{form}"}, "current_step": {"2"}, "action": {"complete"}, "field_" + formatID(f.second.ID): {"Nothing"}}, "", true)
+			if rec.Code != http.StatusSeeOther {
+				t.Fatalf("got status %d for step 2, want 303: %s", rec.Code, rec.Body.String())
+			}
+
+			submission, err := s.store.Submission(context.Background(), f.draft.ID)
+			s.must(err)
+			if submission.Status != "completed" {
+				t.Errorf("got status %q, want completed", submission.Status)
+			}
+		})
+	}
+}
+
+func TestSaveStepRejectsStaleSteps(t *testing.T) {
+	for _, kind := range testStores {
+		t.Run(kind, func(t *testing.T) {
+			s := newTestServer(t, kind)
+			ctx := context.Background()
+
+			// Both requests read the draft on step 1; only the first may save it
+			for i, want := range []error{nil, store.ErrConflict} {
+				submission := s.draft
+				submission.CurrentStep = 2
+				responses := []database.SubmissionResponse{{FieldID: s.field.ID, Response: "Answer " + strconv.Itoa(i)}}
+				err := s.store.SaveStep(ctx, store.Step{Form: s.form, Submission: &submission, Responses: responses, OnStep: 1})
+				if err != want {
+					t.Fatalf("save %d: got %v, want %v", i, err, want)
+				}
+			}
+
+			answers, err := s.store.Responses(ctx, s.draft.ID)
+			s.must(err)
+			if len(answers) != 1 || answers[0].Response != "Answer 0" {
+				t.Errorf("got answers %+v, want only the first save's", answers)
+			}
+		})
+	}
+}
+
+func TestSaveStepDeletesHiddenAnswers(t *testing.T) {
+	for _, kind := range testStores {
+		t.Run(kind, func(t *testing.T) {
+			s := newTestServer(t, kind)
+			f := newStepFixture(s)
+			ctx := context.Background()
+
+			// The second step was answered, then skipped after the first answer changed
+			submission := f.draft
+			submission.CurrentStep = 2
+			responses := []database.SubmissionResponse{{FieldID: s.field.ID, Response: "Fine"}, {FieldID: f.second.ID, Response: "Hidden"}}
+			s.must(s.store.SaveStep(ctx, store.Step{Form: s.form, Submission: &submission, Responses: responses, OnStep: 1}))
+			submission.Status = "completed"
+			responses = []database.SubmissionResponse{{FieldID: s.field.ID, Response: "Changed"}}
+			shown := map[uint]bool{s.field.ID: true}
+			s.must(s.store.SaveStep(ctx, store.Step{Form: s.form, Submission: &submission, Responses: responses, OnStep: 2, Shown: shown}))
+
+			answers, err := s.store.Responses(ctx, f.draft.ID)
+			s.must(err)
+			if len(answers) != 1 || answers[0].FieldID != s.field.ID || answers[0].Response != "Changed" {
+				t.Errorf("got answers %+v, want only the shown field's", answers)
+			}
+		})
+	}
+}
+
+func TestSubmitFormCompletesOnce(t *testing.T) {
+	s := newTestServer(t, "gorm")
+	s.form.NotifyMode = database.NotifyEach
+	s.must(s.store.SaveForm(context.Background(), &s.form, false))
+
+	// Send the same completion several times at once, as a double click
+	// might. Holding the write lock lets every request read the draft before
+	// any of them can save it.
+	lock := s.db.Begin()
+	s.must(lock.Error)
+	const requests = 4
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := s.do(http.MethodPost, "/forms/submit/", url.Values{"submission_key": {"{draft}"}, "form_id": {"{form}"}, "current_step": {"1"}, "action": {"complete"}, "field_{field}": {"Done"}}, "", true)
+			codes <- rec.Code
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	s.must(lock.Rollback().Error)
+	wg.Wait()
+	close(codes)
+
+	completed := 0
+	for code := range codes {
+		switch code {
+		case http.StatusSeeOther:
+			completed++
+		case http.StatusConflict:
+		default:
+			t.Errorf("got status %d, want 303 or 409", code)
+		}
+	}
+	if completed != 1 {
+		t.Errorf("%d requests completed the submission, want 1", completed)
+	}
+
+	// Only one notification is queued for the owner
+	var emails int64
+	s.must(s.db.Model(&database.OutboxEmail{}).Count(&emails).Error)
+	if emails != 1 {
+		t.Errorf("got %d queued emails, want 1", emails)
+	}
+}
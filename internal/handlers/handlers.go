@@ -1,60 +1,125 @@
 package handlers
 
 import (
-	"database/sql"
 	"html/template"
 	"net/http"
 	"path/filepath"
 
-	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/metrics"
+	"github.com/yourusername/event-feedback/internal/respondent"
+	"github.com/yourusername/event-feedback/internal/spam"
+	"github.com/yourusername/event-feedback/internal/store"
 	"github.com/yourusername/event-feedback/internal/utils"
-	"gorm.io/gorm"
 )
 
-var (
+// Handler serves the web pages and the JSON API. The stores it keeps
+// everything in are given to it, so tests can serve it from memory.
+type Handler struct {
+	// Users holds organizer accounts and their login sessions
+	Users store.UserStore
+	// Events, Members, Forms and Submissions read and write the core records
+	Events      store.EventStore
+	Members     store.MemberStore
+	Forms       store.FormStore
+	Submissions store.SubmissionStore
+	// FormTemplates holds the form templates users save
+	FormTemplates store.TemplateStore
+	// Webhooks holds the webhooks of events and their deliveries
+	Webhooks store.WebhookStore
+	// Health reports whether the stores can be used, for /readyz
+	Health store.HealthStore
+	// Spam checks submitted form steps for spam; nil turns the checks off
+	Spam *spam.Guard
+	// Respondents gives out and recognizes the cookies telling respondents apart
+	Respondents *respondent.Cookies
+	// Metrics counts respondents' progress through forms and is served at /metrics
+	Metrics *metrics.Server
 	// Templates holds all parsed templates
 	Templates map[string]*template.Template
-	// DB is the database connection
-	DB *gorm.DB
-)
-
-// RegisterHandlers registers all HTTP handlers
-func RegisterHandlers(mux *http.ServeMux, db *sql.DB) {
-	// Use the GORM DB instance directly
-	DB = database.DB
+}
 
-	// Parse templates
-	parseTemplates()
+// New returns a handler keeping everything in s and rendering the templates
+// in templateDir, with the spam checks on and metrics of its own
+func New(s store.Store, templateDir string) *Handler {
+	return &Handler{
+		Users:         s,
+		Events:        s,
+		Members:       s,
+		Forms:         s,
+		Submissions:   s,
+		FormTemplates: s,
+		Webhooks:      s,
+		Health:        s,
+		Spam:          spam.NewGuard(nil),
+		Respondents:   respondent.New(nil),
+		Metrics:       metrics.NewServer(),
+		Templates:     parseTemplates(templateDir),
+	}
+}
 
+// RegisterHandlers registers all HTTP handlers
+func (h *Handler) RegisterHandlers(mux *http.ServeMux) {
 	// Home page
-	mux.HandleFunc("/", HomeHandler)
+	mux.HandleFunc("/", h.HomeHandler)
+
+	// Account routes
+	mux.HandleFunc("/login", h.LoginHandler)
+	mux.HandleFunc("/logout", h.LogoutHandler)
+	mux.HandleFunc("/signup", h.SignupHandler)
 
 	// Event related routes
-	mux.HandleFunc("/events", ListEventsHandler)
-	mux.HandleFunc("/events/new", NewEventHandler)
-	mux.HandleFunc("/events/create", CreateEventHandler)
-	mux.HandleFunc("/events/view/", ViewEventHandler)
+	mux.HandleFunc("/events", h.ListEventsHandler)
+	mux.HandleFunc("/events/new", h.NewEventHandler)
+	mux.HandleFunc("/events/create", h.CreateEventHandler)
+	mux.HandleFunc("/events/view/", h.ViewEventHandler)
+	mux.HandleFunc("/events/duplicate", h.DuplicateEventHandler)
+	mux.HandleFunc("/events/members/", h.EventMembersHandler)
+	mux.HandleFunc("/events/members/update", h.UpdateEventMembersHandler)
+	mux.HandleFunc("/events/webhooks/", h.EventWebhooksHandler)
+	mux.HandleFunc("/events/webhooks/update", h.UpdateEventWebhooksHandler)
 
 	// Form related routes
-	mux.HandleFunc("/forms/new/", NewFormHandler)
-	mux.HandleFunc("/forms/create", CreateFormHandler)
-	mux.HandleFunc("/forms/edit/", EditFormHandler)
-	mux.HandleFunc("/forms/update", UpdateFormHandler)
-	mux.HandleFunc("/forms/view/", ViewFormHandler)
-	mux.HandleFunc("/forms/submit/", SubmitFormHandler)
+	mux.HandleFunc("/forms/new/", h.NewFormHandler)
+	mux.HandleFunc("/forms/create", h.CreateFormHandler)
+	mux.HandleFunc("/forms/edit/", h.EditFormHandler)
+	mux.HandleFunc("/forms/update", h.UpdateFormHandler)
+	mux.HandleFunc("/forms/duplicate", h.DuplicateFormHandler)
+	mux.HandleFunc("/forms/view/", h.ViewFormHandler)
+	mux.HandleFunc("/forms/submit/", h.SubmitFormHandler)
+	mux.HandleFunc("/forms/submissions/", h.ListSubmissionsHandler)
+	mux.HandleFunc("/forms/export/", h.ExportSubmissionsHandler)
+	mux.HandleFunc("/forms/results/", h.FormResultsHandler)
+	mux.HandleFunc("/forms/moderation/", h.ModerationHandler)
+	mux.HandleFunc("/forms/moderation/update", h.UpdateModerationHandler)
+
+	// Form template routes
+	mux.HandleFunc("/templates", h.ListFormTemplatesHandler)
+	mux.HandleFunc("/templates/save", h.SaveFormTemplateHandler)
+	mux.HandleFunc("/templates/use", h.UseFormTemplateHandler)
+	mux.HandleFunc("/templates/delete", h.DeleteFormTemplateHandler)
 
 	// Submission related routes
-	mux.HandleFunc("/submissions/view/", ViewSubmissionHandler)
-	mux.HandleFunc("/submissions/continue/", ContinueSubmissionHandler)
+	mux.HandleFunc("/submissions/view/", h.ViewSubmissionHandler)
+	mux.HandleFunc("/submissions/continue/", h.ContinueSubmissionHandler)
+
+	// JSON API routes
+	h.registerAPIHandlers(mux)
+
+	// Health checks for load balancers and container orchestrators
+	mux.HandleFunc("/healthz", h.HealthzHandler)
+	mux.HandleFunc("/readyz", h.ReadyzHandler)
+
+	// Metrics for Prometheus to scrape
+	mux.Handle("/metrics", h.Metrics)
 }
 
-// parseTemplates parses all HTML templates
-func parseTemplates() {
-	Templates = make(map[string]*template.Template)
+// parseTemplates parses all HTML templates in templateDir
+func parseTemplates(templateDir string) map[string]*template.Template {
+	templates := make(map[string]*template.Template)
 
-	// Define the layout and template directory
-	layoutFile := filepath.Join("internal", "templates", "layout.html")
-	templateDir := filepath.Join("internal", "templates")
+	// Define the layout
+	layoutFile := filepath.Join(templateDir, "layout.html")
 
 	// Get template functions
 	funcMap := utils.TemplateFuncs()
@@ -79,18 +144,25 @@ func parseTemplates() {
 
 		// Store with the content template name
 		fileName := filepath.Base(templateFile)
-		Templates[fileName] = tmpl
+		templates[fileName] = tmpl
 	}
+	return templates
 }
 
 // RenderTemplate renders a template with the given data
-func RenderTemplate(w http.ResponseWriter, tmpl string, data interface{}) {
-	template, ok := Templates[tmpl]
+func (h *Handler) RenderTemplate(w http.ResponseWriter, r *http.Request, tmpl string, data interface{}) {
+	template, ok := h.Templates[tmpl]
 	if !ok {
 		http.Error(w, "Template not found: "+tmpl, http.StatusInternalServerError)
 		return
 	}
 
+	// Make the logged-in user available to the layout
+	if page, ok := data.(PageData); ok && page.User == nil {
+		page.User = auth.UserFromContext(r.Context())
+		data = page
+	}
+
 	// Set content type
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/formdef"
+	"github.com/yourusername/event-feedback/internal/middleware"
+	"github.com/yourusername/event-feedback/internal/store"
+	"gorm.io/gorm"
+)
+
+// testPassword is the password of the users of a test server
+const testPassword = "correct horse battery"
+
+// testPasswordHash is hashed once, as hashing is slow on purpose
+var testPasswordHash, _ = auth.HashPassword(testPassword)
+
+// testStores are the stores the handlers are tested against
+var testStores = []string{"memory", "gorm"}
+
+// testServer serves the whole application from a store holding an event with
+// a published form, and has its owner signed in
+type testServer struct {
+	t       *testing.T
+	store   store.Store
+	db      *gorm.DB // the database of a gorm store, nil otherwise
+	app     *Handler
+	handler http.Handler
+	session *http.Cookie
+
+	owner      database.User
+	other      database.User
+	event      database.Event
+	form       database.Form
+	field      database.FormField
+	submission database.Submission
+	flagged    database.Submission
+	draft      database.Submission
+	webhook    database.Webhook
+	delivery   database.WebhookDelivery
+	template   database.FormTemplate
+}
+
+// newTestServer returns a test server with fresh records in a store of the
+// given kind: "memory", or "gorm" on an SQLite database file
+func newTestServer(t *testing.T, kind string) *testServer {
+	t.Helper()
+	ctx := context.Background()
+	s := &testServer{t: t}
+	switch kind {
+	case "memory":
+		s.store = store.NewMemory()
+	case "gorm":
+		db, err := database.Open(database.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "test.db")})
+		s.must(err)
+		sqlDB, err := db.DB()
+		s.must(err)
+		t.Cleanup(func() { sqlDB.Close() })
+		s.must(database.MigrateUp(db))
+		s.store, s.db = store.NewGorm(db), db
+	default:
+		t.Fatalf("unknown store %q", kind)
+	}
+
+	s.owner = s.createUser("Olive Owner", "olive@example.com")
+	s.other = s.createUser("Otto Other", "otto@example.com")
+
+	s.event = database.Event{Name: "Go Meetup", Description: "Monthly", Date: time.Now().AddDate(0, 1, 0)}
+	s.must(s.store.CreateEvent(ctx, &s.event, s.owner.ID))
+
+	// The form is published with a single required field
+	s.form = database.Form{EventID: s.event.ID, Title: "Session Feedback"}
+	s.must(s.store.CreateForm(ctx, &s.form))
+	s.field = database.FormField{FormID: s.form.ID, Step: 1, FieldType: "text", Label: "Comments", IsRequired: true}
+	s.must(s.store.CreateField(ctx, &s.field, 0))
+	s.form.IsPublished = true
+	s.must(s.store.SaveForm(ctx, &s.form, true))
+
+	s.submission = s.createSubmission("completed", "Great talk")
+	s.flagged = s.createSubmission("flagged", "Buy cheap watches")
+	s.flagged.FlagReason = "links"
+	s.must(s.store.SaveSubmission(ctx, &s.flagged, nil))
+	s.draft = s.createSubmission("in_progress", "So far so good")
+
+	s.webhook = database.Webhook{EventID: s.event.ID, URL: "https://hooks.example.com/feedback", Secret: "secret", IsActive: true}
+	s.must(s.store.CreateWebhook(ctx, &s.webhook))
+	s.delivery = database.WebhookDelivery{WebhookID: s.webhook.ID, Topic: "submission.completed", Payload: "{}", Status: database.DeliveryDead}
+	s.addDelivery(&s.delivery)
+
+	s.template = database.FormTemplate{Name: "Feedback", CreatedByID: s.owner.ID, Definition: formdef.FromForm(s.form, []database.FormField{s.field})}
+	s.must(s.store.CreateTemplate(ctx, &s.template))
+
+	s.app = New(s.store, "../templates")
+	s.app.Spam = nil
+	mux := http.NewServeMux()
+	s.app.RegisterHandlers(mux)
+	s.handler = middleware.Authenticate(s.store, mux)
+	s.session = s.login(s.owner)
+	return s
+}
+
+// must fails the test on a setup error
+func (s *testServer) must(err error) {
+	s.t.Helper()
+	if err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// createUser stores a user with the test password
+func (s *testServer) createUser(name, email string) database.User {
+	s.t.Helper()
+	user := database.User{Name: name, Email: email, PasswordHash: testPasswordHash}
+	s.must(s.store.CreateUser(context.Background(), &user))
+	return user
+}
+
+// createSubmission stores a submission to the form with an answer to its field
+func (s *testServer) createSubmission(status, answer string) database.Submission {
+	s.t.Helper()
+	submission := database.Submission{
+		FormID:        s.form.ID,
+		FormVersionID: s.form.PublishedVersionID,
+		SubmissionKey: s.newKey(),
+		RespondentKey: s.newKey(),
+		Status:        status,
+		CurrentStep:   1,
+	}
+	if status != "in_progress" {
+		submission.CompletedAt.Time, submission.CompletedAt.Valid = time.Now(), true
+	}
+	responses := []database.SubmissionResponse{{FieldID: s.field.ID, Response: answer}}
+	s.must(s.store.SaveSubmission(context.Background(), &submission, responses))
+	return submission
+}
+
+// addDelivery stores a webhook delivery as it is, as only the delivery worker
+// creates them
+func (s *testServer) addDelivery(delivery *database.WebhookDelivery) {
+	s.t.Helper()
+	if mem, ok := s.store.(*store.Memory); ok {
+		mem.AddDelivery(delivery)
+		return
+	}
+	s.must(s.db.Create(delivery).Error)
+}
+
+// newKey returns a new submission key
+func (s *testServer) newKey() string {
+	s.t.Helper()
+	key, err := generateSubmissionKey()
+	s.must(err)
+	return key
+}
+
+// login starts a session for user and returns its cookie
+func (s *testServer) login(user database.User) *http.Cookie {
+	s.t.Helper()
+	rec := httptest.NewRecorder()
+	s.must(auth.StartSession(rec, httptest.NewRequest(http.MethodGet, "/", nil), s.store, &user))
+	return rec.Result().Cookies()[0]
+}
+
+// expand replaces the placeholders in text with the IDs of the test records
+func (s *testServer) expand(text string) string {
+	id := func(n uint) string { return strconv.FormatUint(uint64(n), 10) }
+	return strings.NewReplacer(
+		"{event}", id(s.event.ID),
+		"{form}", id(s.form.ID),
+		"{version}", id(*s.form.PublishedVersionID),
+		"{field}", id(s.field.ID),
+		"{submission}", id(s.submission.ID),
+		"{flagged}", id(s.flagged.ID),
+		"{key}", s.submission.SubmissionKey,
+		"{draft}", s.draft.SubmissionKey,
+		"{webhook}", id(s.webhook.ID),
+		"{delivery}", id(s.delivery.ID),
+		"{template}", id(s.template.ID),
+		"{other}", s.other.Email,
+	).Replace(text)
+}
+
+// do serves a request, sending values as a form or body as JSON, with the
+// owner signed in unless anonymous is set
+func (s *testServer) do(method, path string, values url.Values, body string, anonymous bool) *httptest.ResponseRecorder {
+	var req *http.Request
+	switch {
+	case values != nil:
+		expanded := url.Values{}
+		for name, list := range values {
+			for _, value := range list {
+				expanded.Add(s.expand(name), s.expand(value))
+			}
+		}
+		req = httptest.NewRequest(method, s.expand(path), strings.NewReader(expanded.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	case body != "":
+		req = httptest.NewRequest(method, s.expand(path), strings.NewReader(s.expand(body)))
+		req.Header.Set("Content-Type", "application/json")
+	default:
+		req = httptest.NewRequest(method, s.expand(path), nil)
+	}
+	if !anonymous {
+		req.AddCookie(s.session)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRoutes(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		values    url.Values
+		body      string
+		anonymous bool
+		wantCode  int
+		wantBody  string
+		// check, if set, inspects the store after the request
+		check func(t *testing.T, s *testServer)
+	}{
+		// Pages anyone can see
+		{name: "home", method: "GET", path: "/", anonymous: true, wantCode: http.StatusOK, wantBody: "Session Feedback"},
+		{name: "unknown page", method: "GET", path: "/nowhere", anonymous: true, wantCode: http.StatusNotFound},
+		{name: "healthz", method: "GET", path: "/healthz", anonymous: true, wantCode: http.StatusOK},
+		{name: "readyz", method: "GET", path: "/readyz", anonymous: true, wantCode: http.StatusOK},
+		{name: "metrics", method: "GET", path: "/metrics", anonymous: true, wantCode: http.StatusOK},
+
+		// Accounts
+		{name: "login page", method: "GET", path: "/login", anonymous: true, wantCode: http.StatusOK},
+		{name: "login", method: "POST", path: "/login", values: url.Values{"email": {"olive@example.com"}, "password": {testPassword}}, anonymous: true, wantCode: http.StatusSeeOther},
+		{name: "login with wrong password", method: "POST", path: "/login", values: url.Values{"email": {"olive@example.com"}, "password": {"wrong"}}, anonymous: true, wantCode: http.StatusOK, wantBody: "Invalid"},
+		{name: "logout", method: "POST", path: "/logout", wantCode: http.StatusSeeOther},
+		{name: "signup page", method: "GET", path: "/signup", anonymous: true, wantCode: http.StatusOK},
+		{name: "signup", method: "POST", path: "/signup", values: url.Values{"name": {"Nina New"}, "email": {"nina@example.com"}, "password": {testPassword}, "confirm_password": {testPassword}}, anonymous: true, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if _, err := s.store.UserByEmail(context.Background(), "nina@example.com"); err != nil {
+					t.Errorf("user not created: %v", err)
+				}
+			}},
+		{name: "signup with taken email", method: "POST", path: "/signup", values: url.Values{"name": {"Olive"}, "email": {"olive@example.com"}, "password": {testPassword}, "confirm_password": {testPassword}}, anonymous: true, wantCode: http.StatusOK, wantBody: "already"},
+
+		// Events
+		{name: "events", method: "GET", path: "/events", wantCode: http.StatusOK, wantBody: "Go Meetup"},
+		{name: "events signed out", method: "GET", path: "/events", anonymous: true, wantCode: http.StatusSeeOther},
+		{name: "new event page", method: "GET", path: "/events/new", wantCode: http.StatusOK},
+		{name: "create event", method: "POST", path: "/events/create", values: url.Values{"name": {"Rust Meetup"}, "description": {"Weekly"}, "date": {"2030-01-02"}}, wantCode: http.StatusSeeOther},
+		{name: "view event", method: "GET", path: "/events/view/{event}", wantCode: http.StatusOK, wantBody: "Session Feedback"},
+		{name: "view missing event", method: "GET", path: "/events/view/999", wantCode: http.StatusNotFound},
+		{name: "duplicate event", method: "POST", path: "/events/duplicate", values: url.Values{"event_id": {"{event}"}, "name": {"Go Meetup II"}, "date": {"2030-02-03"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				events, _ := s.store.MemberEvents(context.Background(), s.owner.ID)
+				if len(events) != 2 {
+					t.Fatalf("got %d events, want 2", len(events))
+				}
+				for _, event := range events {
+					if forms, _ := s.store.EventForms(context.Background(), event.ID); len(forms) != 1 {
+						t.Errorf("event %q has %d forms, want 1", event.Name, len(forms))
+					}
+				}
+			}},
+
+		// Members
+		{name: "members", method: "GET", path: "/events/members/{event}", wantCode: http.StatusOK, wantBody: "olive@example.com"},
+		{name: "add member", method: "POST", path: "/events/members/update", values: url.Values{"event_id": {"{event}"}, "action": {"add"}, "email": {"{other}"}, "role": {"viewer"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if role, _ := s.store.EventRole(context.Background(), s.other.ID, s.event.ID); role != database.RoleViewer {
+					t.Errorf("got role %q, want viewer", role)
+				}
+			}},
+		{name: "add unknown member", method: "POST", path: "/events/members/update", values: url.Values{"event_id": {"{event}"}, "action": {"add"}, "email": {"nobody@example.com"}, "role": {"viewer"}}, wantCode: http.StatusOK, wantBody: "No organizer account"},
+
+		// Webhooks
+		{name: "webhooks", method: "GET", path: "/events/webhooks/{event}", wantCode: http.StatusOK, wantBody: "hooks.example.com"},
+		{name: "add webhook", method: "POST", path: "/events/webhooks/update", values: url.Values{"event_id": {"{event}"}, "action": {"add"}, "url": {"https://other.example.com/"}, "form_id": {"{form}"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if hooks, _ := s.store.EventWebhooks(context.Background(), s.event.ID); len(hooks) != 2 {
+					t.Errorf("got %d webhooks, want 2", len(hooks))
+				}
+			}},
+		{name: "add webhook to private address", method: "POST", path: "/events/webhooks/update", values: url.Values{"event_id": {"{event}"}, "action": {"add"}, "url": {"http://127.0.0.1/"}}, wantCode: http.StatusOK},
+		{name: "retry delivery", method: "POST", path: "/events/webhooks/update", values: url.Values{"event_id": {"{event}"}, "action": {"retry"}, "delivery_id": {"{delivery}"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				deliveries, _ := s.store.Deliveries(context.Background(), s.event.ID, database.DeliveryPending, 10)
+				if len(deliveries) != 1 {
+					t.Errorf("got %d pending deliveries, want 1", len(deliveries))
+				}
+			}},
+		{name: "retry missing delivery", method: "POST", path: "/events/webhooks/update", values: url.Values{"event_id": {"{event}"}, "action": {"retry"}, "delivery_id": {"999"}}, wantCode: http.StatusNotFound},
+
+		// Forms
+		{name: "new form page", method: "GET", path: "/forms/new/{event}", wantCode: http.StatusOK},
+		{name: "create form", method: "POST", path: "/forms/create", values: url.Values{"event_id": {"{event}"}, "title": {"Venue Survey"}}, wantCode: http.StatusSeeOther},
+		{name: "create form from template", method: "POST", path: "/forms/create", values: url.Values{"event_id": {"{event}"}, "title": {"From Template"}, "template_id": {"{template}"}}, wantCode: http.StatusSeeOther},
+		{name: "edit form", method: "GET", path: "/forms/edit/{form}", wantCode: http.StatusOK, wantBody: "Comments"},
+		{name: "edit form signed out", method: "GET", path: "/forms/edit/{form}", anonymous: true, wantCode: http.StatusSeeOther},
+		{name: "update form", method: "POST", path: "/forms/update", values: url.Values{"form_id": {"{form}"}, "action": {"update_form"}, "title": {"Renamed"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if form, _ := s.store.Form(context.Background(), s.form.ID); form.Title != "Renamed" {
+					t.Errorf("got title %q, want Renamed", form.Title)
+				}
+			}},
+		{name: "add field", method: "POST", path: "/forms/update", values: url.Values{"form_id": {"{form}"}, "action": {"add_field"}, "step": {"1"}, "field_type": {"number"}, "label": {"Rating"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if fields, _ := s.store.Fields(context.Background(), s.form.ID); len(fields) != 2 || fields[1].Label != "Rating" {
+					t.Errorf("got fields %+v", fields)
+				}
+			}},
+		{name: "add field of unknown type", method: "POST", path: "/forms/update", values: url.Values{"form_id": {"{form}"}, "action": {"add_field"}, "step": {"1"}, "field_type": {"slider"}, "label": {"Rating"}}, wantCode: http.StatusBadRequest, wantBody: "field_type must be one of"},
+		{name: "update field", method: "POST", path: "/forms/update", values: url.Values{"form_id": {"{form}"}, "action": {"update_field"}, "field_id": {"{field}"}, "step": {"1"}, "field_type": {"text"}, "label": {"Thoughts"}}, wantCode: http.StatusSeeOther},
+		{name: "update type of answered field", method: "POST", path: "/forms/update", values: url.Values{"form_id": {"{form}"}, "action": {"update_field"}, "field_id": {"{field}"}, "step": {"1"}, "field_type": {"checkbox"}, "label": {"Thoughts"}}, wantCode: http.StatusConflict},
+		{name: "update field to unknown type", method: "POST", path: "/forms/update", values: url.Values{"form_id": {"{form}"}, "action": {"update_field"}, "field_id": {"{field}"}, "field_type": {"slider"}}, wantCode: http.StatusBadRequest, wantBody: "field_type must be one of"},
+		{name: "delete field", method: "POST", path: "/forms/update", values: url.Values{"form_id": {"{form}"}, "action": {"delete_field"}, "field_id": {"{field}"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if fields, _ := s.store.Fields(context.Background(), s.form.ID); len(fields) != 0 {
+					t.Errorf("got %d fields, want 0", len(fields))
+				}
+			}},
+		{name: "unpublish form", method: "POST", path: "/forms/update", values: url.Values{"form_id": {"{form}"}, "action": {"publish"}, "publish": {"false"}}, wantCode: http.StatusSeeOther},
+		{name: "duplicate form", method: "POST", path: "/forms/duplicate", values: url.Values{"form_id": {"{form}"}, "event_id": {"{event}"}, "title": {"Copy"}}, wantCode: http.StatusSeeOther},
+		{name: "view form", method: "GET", path: "/forms/view/{form}", anonymous: true, wantCode: http.StatusOK, wantBody: "Comments"},
+		{name: "view missing form", method: "GET", path: "/forms/view/999", anonymous: true, wantCode: http.StatusNotFound},
+		{name: "submit form", method: "POST", path: "/forms/submit/", values: url.Values{"form_id": {"{form}"}, "current_step": {"1"}, "action": {"complete"}, "field_{field}": {"Loved it"}}, anonymous: true, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if count, _ := s.store.CountCompleted(context.Background(), s.form.ID, ""); count != 2 {
+					t.Errorf("got %d completed submissions, want 2", count)
+				}
+			}},
+		{name: "submit form without answer", method: "POST", path: "/forms/submit/", values: url.Values{"form_id": {"{form}"}, "current_step": {"1"}, "action": {"complete"}}, anonymous: true, wantCode: http.StatusOK, wantBody: "Please correct"},
+		{name: "submissions", method: "GET", path: "/forms/submissions/{form}", wantCode: http.StatusOK, wantBody: "{key}"},
+		{name: "export", method: "GET", path: "/forms/export/{form}", wantCode: http.StatusOK, wantBody: "Great talk"},
+		{name: "results", method: "GET", path: "/forms/results/{form}", wantCode: http.StatusOK, wantBody: "Comments"},
+		{name: "moderation", method: "GET", path: "/forms/moderation/{form}", wantCode: http.StatusOK, wantBody: "links"},
+		{name: "approve submission", method: "POST", path: "/forms/moderation/update", values: url.Values{"submission_id": {"{flagged}"}, "action": {"approve"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if submission, _ := s.store.Submission(context.Background(), s.flagged.ID); submission.Status != "completed" {
+					t.Errorf("got status %q, want completed", submission.Status)
+				}
+			}},
+		{name: "reject submission", method: "POST", path: "/forms/moderation/update", values: url.Values{"submission_id": {"{flagged}"}, "action": {"reject"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if _, err := s.store.Submission(context.Background(), s.flagged.ID); err != store.ErrNotFound {
+					t.Errorf("got %v, want the submission deleted", err)
+				}
+			}},
+
+		// Form templates
+		{name: "templates", method: "GET", path: "/templates", wantCode: http.StatusOK, wantBody: "Feedback"},
+		{name: "save template", method: "POST", path: "/templates/save", values: url.Values{"form_id": {"{form}"}, "name": {"Mine"}}, wantCode: http.StatusSeeOther},
+		{name: "use template", method: "POST", path: "/templates/use", values: url.Values{"template_id": {"{template}"}, "event_id": {"{event}"}, "title": {"Reused"}}, wantCode: http.StatusSeeOther},
+		{name: "delete template", method: "POST", path: "/templates/delete", values: url.Values{"template_id": {"{template}"}}, wantCode: http.StatusSeeOther,
+			check: func(t *testing.T, s *testServer) {
+				if _, err := s.store.UserTemplate(context.Background(), s.owner.ID, s.template.ID); err != store.ErrNotFound {
+					t.Errorf("got %v, want the template deleted", err)
+				}
+			}},
+
+		// Respondents' submissions
+		{name: "view submission", method: "GET", path: "/submissions/view/{key}", anonymous: true, wantCode: http.StatusOK, wantBody: "Great talk"},
+		{name: "view missing submission", method: "GET", path: "/submissions/view/nope", anonymous: true, wantCode: http.StatusNotFound},
+		{name: "continue submission", method: "GET", path: "/submissions/continue/{draft}", anonymous: true, wantCode: http.StatusOK, wantBody: "So far so good"},
+
+		// JSON API
+		{name: "api events", method: "GET", path: "/api/v1/events", wantCode: http.StatusOK, wantBody: `"name":"Go Meetup"`},
+		{name: "api events signed out", method: "GET", path: "/api/v1/events", anonymous: true, wantCode: http.StatusUnauthorized},
+		{name: "api search events", method: "GET", path: "/api/v1/events?q=rust", wantCode: http.StatusOK, wantBody: `"total":0`},
+		{name: "api create event", method: "POST", path: "/api/v1/events", body: `{"name":"API Event","date":"2030-03-04"}`, wantCode: http.StatusCreated},
+		{name: "api event", method: "GET", path: "/api/v1/events/{event}", wantCode: http.StatusOK, wantBody: `"name":"Go Meetup"`},
+		{name: "api missing event", method: "GET", path: "/api/v1/events/999", wantCode: http.StatusNotFound},
+		{name: "api update event", method: "PATCH", path: "/api/v1/events/{event}", body: `{"name":"Renamed"}`, wantCode: http.StatusOK, wantBody: `"name":"Renamed"`},
+		{name: "api delete event", method: "DELETE", path: "/api/v1/events/{event}", wantCode: http.StatusNoContent,
+			check: func(t *testing.T, s *testServer) {
+				if _, err := s.store.Form(context.Background(), s.form.ID); err != store.ErrNotFound {
+					t.Errorf("got %v, want the form deleted with its event", err)
+				}
+			}},
+		{name: "api forms", method: "GET", path: "/api/v1/forms?event_id={event}&is_published=true", wantCode: http.StatusOK, wantBody: `"total":1`},
+		{name: "api create form", method: "POST", path: "/api/v1/forms", body: `{"event_id":{event},"title":"API Form","is_published":true}`, wantCode: http.StatusCreated, wantBody: `"is_published":true`},
+		{name: "api create form signed out", method: "POST", path: "/api/v1/forms", body: `{"event_id":999,"title":"API Form"}`, anonymous: true, wantCode: http.StatusUnauthorized},
+		{name: "api form", method: "GET", path: "/api/v1/forms/{form}", wantCode: http.StatusOK, wantBody: `"label":"Comments"`},
+		{name: "api update form", method: "PATCH", path: "/api/v1/forms/{form}", body: `{"title":"Renamed"}`, wantCode: http.StatusOK, wantBody: `"title":"Renamed"`},
+		{name: "api delete form", method: "DELETE", path: "/api/v1/forms/{form}", wantCode: http.StatusNoContent},
+		{name: "api form results", method: "GET", path: "/api/v1/forms/{form}/results", wantCode: http.StatusOK, wantBody: `"completed_submissions":1`},
+		{name: "api form definition", method: "GET", path: "/api/v1/forms/{form}/definition", wantCode: http.StatusOK, wantBody: `"comments"`},
+		{name: "api form layout", method: "PUT", path: "/api/v1/forms/{form}/layout", body: `{"steps":[[{field}]]}`, wantCode: http.StatusOK},
+		{name: "api form layout missing field", method: "PUT", path: "/api/v1/forms/{form}/layout", body: `{"steps":[]}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "api form versions", method: "GET", path: "/api/v1/forms/{form}/versions", wantCode: http.StatusOK, wantBody: `"number":1`},
+		{name: "api import form", method: "POST", path: "/api/v1/events/{event}/forms/import", body: `{"title":"Imported","fields":[{"ref":"name","step":1,"field_type":"text","label":"Name"}]}`, wantCode: http.StatusCreated},
+		{name: "api fields", method: "GET", path: "/api/v1/fields?form_id={form}&step=1", wantCode: http.StatusOK, wantBody: `"total":1`},
+		{name: "api create field", method: "POST", path: "/api/v1/fields", body: `{"form_id":{form},"field_type":"number","label":"Rating","field_order":1}`, wantCode: http.StatusCreated, wantBody: `"field_order":1`},
+		{name: "api create field signed out", method: "POST", path: "/api/v1/fields", body: `{"form_id":999,"field_type":"text","label":"Name"}`, anonymous: true, wantCode: http.StatusUnauthorized},
+		{name: "api field", method: "GET", path: "/api/v1/fields/{field}", wantCode: http.StatusOK, wantBody: `"label":"Comments"`},
+		{name: "api update field", method: "PATCH", path: "/api/v1/fields/{field}", body: `{"label":"Thoughts"}`, wantCode: http.StatusOK, wantBody: `"label":"Thoughts"`},
+		{name: "api update type of answered field", method: "PATCH", path: "/api/v1/fields/{field}", body: `{"field_type":"number"}`, wantCode: http.StatusConflict},
+		{name: "api delete field", method: "DELETE", path: "/api/v1/fields/{field}", wantCode: http.StatusNoContent},
+		{name: "api submissions", method: "GET", path: "/api/v1/submissions?form_id={form}&status=completed", wantCode: http.StatusOK, wantBody: `"total":1`},
+		{name: "api create submission", method: "POST", path: "/api/v1/submissions", body: `{"form_id":{form},"responses":[{"field_id":{field},"response":"Nice"}]}`, wantCode: http.StatusCreated, wantBody: `"response":"Nice"`},
+		{name: "api create submission signed out", method: "POST", path: "/api/v1/submissions", body: `{"form_id":999}`, anonymous: true, wantCode: http.StatusUnauthorized},
+		{name: "api create submission with foreign field", method: "POST", path: "/api/v1/submissions", body: `{"form_id":{form},"responses":[{"field_id":999,"response":"Nice"}]}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "api submission", method: "GET", path: "/api/v1/submissions/{submission}", wantCode: http.StatusOK, wantBody: `"response":"Great talk"`},
+		{name: "api update submission", method: "PATCH", path: "/api/v1/submissions/{submission}", body: `{"responses":[{"field_id":{field},"response":"Even better"}]}`, wantCode: http.StatusOK, wantBody: `"response":"Even better"`},
+		{name: "api delete submission", method: "DELETE", path: "/api/v1/submissions/{submission}", wantCode: http.StatusNoContent},
+		{name: "api unknown resource", method: "GET", path: "/api/v1/nothing", wantCode: http.StatusNotFound},
+	}
+
+	for _, kind := range testStores {
+		for _, tt := range tests {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				s := newTestServer(t, kind)
+				rec := s.do(tt.method, tt.path, tt.values, tt.body, tt.anonymous)
+				if rec.Code != tt.wantCode {
+					t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+				}
+				if want := s.expand(tt.wantBody); !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body does not contain %q: %s", want, rec.Body.String())
+				}
+				if tt.check != nil {
+					tt.check(t, s)
+				}
+			})
+		}
+	}
+}
+
+func TestRoutesCheckEventRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string // role of the other user on the event, if any
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{name: "outsider views event", method: "GET", path: "/events/view/{event}", wantCode: http.StatusForbidden},
+		{name: "viewer views event", role: database.RoleViewer, method: "GET", path: "/events/view/{event}", wantCode: http.StatusOK},
+		{name: "viewer edits form", role: database.RoleViewer, method: "GET", path: "/forms/edit/{form}", wantCode: http.StatusForbidden},
+		{name: "editor edits form", role: database.RoleEditor, method: "GET", path: "/forms/edit/{form}", wantCode: http.StatusOK},
+		{name: "editor manages members", role: database.RoleEditor, method: "GET", path: "/events/members/{event}", wantCode: http.StatusForbidden},
+		{name: "outsider reads form over api", method: "GET", path: "/api/v1/forms/{form}", wantCode: http.StatusNotFound},
+		{name: "viewer reads form over api", role: database.RoleViewer, method: "GET", path: "/api/v1/forms/{form}", wantCode: http.StatusOK},
+		{name: "viewer updates form over api", role: database.RoleViewer, method: "PATCH", path: "/api/v1/forms/{form}", body: `{"title":"Mine"}`, wantCode: http.StatusForbidden},
+		{name: "outsider lists forms over api", method: "GET", path: "/api/v1/forms", wantCode: http.StatusOK},
+	}
+
+	for _, kind := range testStores {
+		for _, tt := range tests {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				s := newTestServer(t, kind)
+				if tt.role != "" {
+					member := database.EventMember{EventID: s.event.ID, UserID: s.other.ID, Role: tt.role}
+					s.must(s.store.CreateMember(context.Background(), &member))
+				}
+				s.session = s.login(s.other)
+				rec := s.do(tt.method, tt.path, nil, tt.body, false)
+				if rec.Code != tt.wantCode {
+					t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// readyTimeout bounds how long the readiness check waits for the database
+const readyTimeout = 2 * time.Second
+
+// HealthzHandler reports that the server is alive. It does not touch the
+// database, so an unreachable database does not get the server restarted.
+func (h *Handler) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprintln(w, "ok")
+}
+
+// ReadyzHandler reports whether the server can take traffic: the database
+// answers and every migration has been applied. Otherwise it responds with
+// 503 Service Unavailable and the reason.
+func (h *Handler) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	if err := h.Health.Ready(ctx); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
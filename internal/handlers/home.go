@@ -3,47 +3,55 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/yourusername/event-feedback/internal/auth"
 	"github.com/yourusername/event-feedback/internal/database"
-	"gorm.io/gorm"
+	"github.com/yourusername/event-feedback/internal/spam"
 )
 
 // PageData holds common data for all pages
 type PageData struct {
-	Title      string
-	Error      string
-	Success    string
-	Events     []database.Event
-	Event      database.Event
-	Form       database.Form
-	Forms      []database.Form
-	Fields     []database.FormField
-	Submission database.Submission
+	Title       string
+	Error       string
+	Success     string
+	Events      []database.Event
+	Event       database.Event
+	Form        database.Form
+	Forms       []database.Form
+	Fields      []database.FormField
+	Submission  database.Submission
+	Submissions []database.Submission
+	User        *database.User
+	Role        string
+	Members     []database.EventMember
+	Answers     map[uint]string // answers to prefill, keyed by field ID
+	FieldErrors map[uint]string // validation messages, keyed by field ID
+	StepNumber  int             // position of the shown step among the steps the respondent goes through
+	StepCount   int             // number of steps the respondent goes through
+	Stamp       string          // signed time the form step was shown at
+	Challenge   *spam.Challenge // proof the respondent is human asked for on the last step
 }
 
 // HomeHandler handles the home page
-func HomeHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) HomeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
 		return
 	}
 
-	// Get recent events
+	// Get the logged-in organizer's recent events
 	var events []database.Event
-	result := database.DB.Order("created_at desc").Limit(5).Find(&events)
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
-		http.Error(w, "Failed to fetch events", http.StatusInternalServerError)
-		return
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		var err error
+		events, err = h.Events.RecentEvents(r.Context(), user.ID, 5)
+		if err != nil {
+			http.Error(w, "Failed to fetch events", http.StatusInternalServerError)
+			return
+		}
 	}
 
 	// Get published forms with their events
-	var forms []database.Form
-	result = database.DB.
-		Where("is_published = ?", true).
-		Order("created_at desc").
-		Limit(10).
-		Preload("Event").
-		Find(&forms)
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+	forms, err := h.Forms.PublishedForms(r.Context(), 10)
+	if err != nil {
 		http.Error(w, "Failed to fetch forms", http.StatusInternalServerError)
 		return
 	}
@@ -54,5 +62,5 @@ func HomeHandler(w http.ResponseWriter, r *http.Request) {
 		Forms:  forms,
 	}
 
-	RenderTemplate(w, "home.html", data)
+	h.RenderTemplate(w, r, "home.html", data)
 }
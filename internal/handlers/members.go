@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// EventMembersHandler displays the people who have access to an event
+func (h *Handler) EventMembersHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract event ID from URL
+	path := strings.TrimPrefix(r.URL.Path, "/events/members/")
+	eventID, err := strconv.ParseUint(path, 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get event
+	event, err := h.Events.Event(r.Context(), uint(eventID))
+	if err != nil {
+		if err == store.ErrNotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	role, ok := h.requireEventRole(w, r, event.ID, database.RoleOwner)
+	if !ok {
+		return
+	}
+
+	h.renderMembersPage(w, r, event, role, "")
+}
+
+// UpdateEventMembersHandler adds, changes and removes event members
+func (h *Handler) UpdateEventMembersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Parse event ID
+	eventID, err := strconv.ParseUint(r.FormValue("event_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	// Check if event exists
+	event, err := h.Events.Event(r.Context(), uint(eventID))
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	role, ok := h.requireEventRole(w, r, event.ID, database.RoleOwner)
+	if !ok {
+		return
+	}
+
+	action := r.FormValue("action") // Can be: add, update, remove
+	newRole := r.FormValue("role")
+
+	switch action {
+	case "add":
+		email := auth.NormalizeEmail(r.FormValue("email"))
+		if !auth.IsValidRole(newRole) {
+			h.renderMembersPage(w, r, event, role, "Please choose a valid role")
+			return
+		}
+
+		// Find the account being added
+		user, err := h.Users.UserByEmail(r.Context(), email)
+		if err != nil {
+			if err != store.ErrNotFound {
+				http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
+				return
+			}
+			h.renderMembersPage(w, r, event, role, "No organizer account exists for "+email)
+			return
+		}
+
+		current, err := h.Events.EventRole(r.Context(), user.ID, event.ID)
+		if err != nil {
+			http.Error(w, "Failed to check membership", http.StatusInternalServerError)
+			return
+		}
+		if current != "" {
+			h.renderMembersPage(w, r, event, role, user.Email+" is already a member of this event")
+			return
+		}
+
+		member := database.EventMember{
+			EventID: event.ID,
+			UserID:  user.ID,
+			Role:    newRole,
+		}
+		err = h.Members.CreateMember(r.Context(), &member)
+		if err != nil {
+			http.Error(w, "Failed to add member: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	case "update", "remove":
+		memberID, err := strconv.ParseUint(r.FormValue("member_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid member ID", http.StatusBadRequest)
+			return
+		}
+
+		member, err := h.Members.Member(r.Context(), event.ID, uint(memberID))
+		if err != nil {
+			http.Error(w, "Member not found", http.StatusNotFound)
+			return
+		}
+
+		if action == "update" && !auth.IsValidRole(newRole) {
+			h.renderMembersPage(w, r, event, role, "Please choose a valid role")
+			return
+		}
+
+		// Every event must keep at least one owner
+		if member.Role == database.RoleOwner && (action == "remove" || newRole != database.RoleOwner) {
+			owners, err := h.Members.OwnerCount(r.Context(), event.ID)
+			if err != nil {
+				http.Error(w, "Failed to count owners", http.StatusInternalServerError)
+				return
+			}
+			if owners <= 1 {
+				h.renderMembersPage(w, r, event, role, "An event must have at least one owner")
+				return
+			}
+		}
+
+		if action == "update" {
+			member.Role = newRole
+			err = h.Members.SaveMember(r.Context(), &member)
+		} else {
+			err = h.Members.DeleteMember(r.Context(), member)
+		}
+		if err != nil {
+			http.Error(w, "Failed to update member: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/events/members/"+strconv.FormatUint(uint64(event.ID), 10), http.StatusSeeOther)
+}
+
+// renderMembersPage renders the member list for an event
+func (h *Handler) renderMembersPage(w http.ResponseWriter, r *http.Request, event database.Event, role, errMsg string) {
+	members, err := h.Members.Members(r.Context(), event.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch members", http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title:   "Members: " + event.Name,
+		Error:   errMsg,
+		Event:   event,
+		Role:    role,
+		Members: members,
+	}
+
+	h.RenderTemplate(w, r, "event_members.html", data)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/metrics"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// ModerationHandler lists the submissions of a form flagged as likely spam
+func (h *Handler) ModerationHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract form ID from URL
+	path := strings.TrimPrefix(r.URL.Path, "/forms/moderation/")
+	formID, err := strconv.ParseUint(path, 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
+		if err == store.ErrNotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	role, ok := h.requireEventRole(w, r, form.EventID, database.RoleEditor)
+	if !ok {
+		return
+	}
+
+	// Get event
+	event, err := h.Events.Event(r.Context(), form.EventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
+		return
+	}
+
+	// Get the flagged submissions, oldest first
+	submissions, err := h.Submissions.FlaggedSubmissions(r.Context(), form.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch submissions", http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title:       "Moderation: " + form.Title,
+		Form:        form,
+		Event:       event,
+		Submissions: submissions,
+		Role:        role,
+		User:        auth.UserFromContext(r.Context()),
+	}
+
+	h.RenderTemplate(w, r, "form_moderation.html", data)
+}
+
+// UpdateModerationHandler approves or rejects a flagged submission. Approved
+// submissions count as completed from then on; rejected ones are deleted.
+func (h *Handler) UpdateModerationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Parse submission ID
+	submissionID, err := strconv.ParseUint(r.FormValue("submission_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get the flagged submission and its form
+	submission, err := h.Submissions.Submission(r.Context(), uint(submissionID))
+	if err != nil || submission.Status != "flagged" {
+		http.Error(w, "Submission not found", http.StatusNotFound)
+		return
+	}
+	form, err := h.Forms.Form(r.Context(), submission.FormID)
+	if err != nil {
+		http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
+		return
+	}
+
+	if _, ok := h.requireEventRole(w, r, form.EventID, database.RoleEditor); !ok {
+		return
+	}
+
+	switch r.FormValue("action") {
+	case "approve":
+		err = h.Submissions.ApproveSubmission(r.Context(), form, &submission)
+		if err != nil {
+			http.Error(w, "Failed to approve submission: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.Metrics.SubmissionsCompleted.Inc(metrics.FormLabel(form.ID))
+
+	case "reject":
+		err = h.Submissions.DeleteSubmission(r.Context(), submission)
+		if err != nil {
+			http.Error(w, "Failed to reject submission: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
+
+	// Redirect back to the moderation queue
+	http.Redirect(w, r, "/forms/moderation/"+strconv.FormatUint(uint64(form.ID), 10), http.StatusSeeOther)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/results"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// FormResultsHandler displays the aggregated results of a form
+func (h *Handler) FormResultsHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract form ID from URL
+	path := strings.TrimPrefix(r.URL.Path, "/forms/results/")
+	formID, err := strconv.ParseUint(path, 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
+		if err == store.ErrNotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	role, ok := h.requireEventRole(w, r, form.EventID, database.RoleViewer)
+	if !ok {
+		return
+	}
+
+	// Get event
+	event, err := h.Events.Event(r.Context(), form.EventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
+		return
+	}
+
+	// Get the fields answered under any version
+	fields, err := h.Forms.ReportFields(r.Context(), form)
+	if err != nil {
+		http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
+		return
+	}
+
+	formResults, err := h.Submissions.Results(r.Context(), form, fields)
+	if err != nil {
+		log.Printf("Failed to compute results for form %d: %v", form.ID, err)
+		http.Error(w, "Failed to compute results", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		PageData
+		Results *results.FormResults
+	}{
+		PageData: PageData{
+			Title: "Results: " + form.Title,
+			Form:  form,
+			Event: event,
+			Role:  role,
+			User:  auth.UserFromContext(r.Context()),
+		},
+		Results: formResults,
+	}
+
+	h.RenderTemplate(w, r, "form_results.html", data)
+}
+
+// APIFormResultsHandler returns the aggregated results of a form
+func (h *Handler) APIFormResultsHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/results")
+	id, err := strconv.ParseUint(strings.TrimPrefix(path, "/api/v1/forms/"), 10, 64)
+	if err != nil || id == 0 {
+		writeAPIError(w, http.StatusNotFound, "Form not found")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(id))
+	if err != nil {
+		writeLookupError(w, err, "Form")
+		return
+	}
+
+	if !h.apiRequireEventRole(w, r, form.EventID, database.RoleViewer) {
+		return
+	}
+
+	// Get the fields answered under any version
+	fields, err := h.Forms.ReportFields(r.Context(), form)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to fetch form fields")
+		return
+	}
+
+	formResults, err := h.Submissions.Results(r.Context(), form, fields)
+	if err != nil {
+		log.Printf("Failed to compute results for form %d: %v", form.ID, err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to compute results")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, itemResponse{Data: formResults})
+}
@@ -1,15 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/branching"
 	"github.com/yourusername/event-feedback/internal/database"
-	"gorm.io/gorm"
+	"github.com/yourusername/event-feedback/internal/store"
 )
 
 // ViewSubmissionHandler displays a submitted form
-func ViewSubmissionHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ViewSubmissionHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract submission key from URL
 	submissionKey := r.URL.Path[len("/submissions/view/"):]
 	if submissionKey == "" {
@@ -18,10 +22,9 @@ func ViewSubmissionHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get submission
-	var submission database.Submission
-	result := database.DB.Where("submission_key = ?", submissionKey).First(&submission)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	submission, err := h.Submissions.SubmissionByKey(r.Context(), submissionKey)
+	if err != nil {
+		if err == store.ErrNotFound {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, "Failed to fetch submission", http.StatusInternalServerError)
@@ -30,38 +33,22 @@ func ViewSubmissionHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get form
-	var form database.Form
-	result = database.DB.First(&form, submission.FormID)
-	if result.Error != nil {
+	form, err := h.Forms.Form(r.Context(), submission.FormID)
+	if err != nil {
 		http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
 		return
 	}
 
 	// Get event
-	var event database.Event
-	result = database.DB.First(&event, form.EventID)
-	if result.Error != nil {
+	event, err := h.Events.Event(r.Context(), form.EventID)
+	if err != nil {
 		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
 		return
 	}
 
-	// Get all responses with field information
-	type ResponseWithField struct {
-		database.SubmissionResponse
-		Step      int
-		FieldType string
-		Label     string
-	}
-
-	var responses []ResponseWithField
-	result = database.DB.Model(&database.SubmissionResponse{}).
-		Select("submission_responses.*, form_fields.step, form_fields.field_type, form_fields.label").
-		Joins("LEFT JOIN form_fields ON form_fields.id = submission_responses.field_id").
-		Where("submission_responses.submission_id = ?", submission.ID).
-		Order("form_fields.step, form_fields.field_order").
-		Scan(&responses)
-
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+	// Get all responses with the fields as the respondent saw them
+	answers, err := h.Submissions.Answers(r.Context(), submission)
+	if err != nil {
 		http.Error(w, "Failed to fetch responses", http.StatusInternalServerError)
 		return
 	}
@@ -77,29 +64,38 @@ func ViewSubmissionHandler(w http.ResponseWriter, r *http.Request) {
 	type ResponseData struct {
 		Label     string
 		Value     string
+		Values    []string // labels of the options chosen in a multi-value field
 		Step      int
 		FieldType string
 	}
 
-	responseData := make([]ResponseData, 0, len(responses))
-	for _, resp := range responses {
-		responseData = append(responseData, ResponseData{
-			Label:     resp.Label,
-			Value:     resp.Response,
-			Step:      resp.Step,
-			FieldType: resp.FieldType,
-		})
+	responseData := make([]ResponseData, 0, len(answers))
+	for _, answer := range answers {
+		field := answer.Field
+		data := ResponseData{
+			Label:     field.Label,
+			Value:     field.Options.Label(answer.Response),
+			Step:      field.Step,
+			FieldType: field.FieldType,
+		}
+		if database.IsMultiValueField(field.FieldType) {
+			for _, value := range database.SplitResponseValues(answer.Response) {
+				data.Values = append(data.Values, field.Options.Label(value))
+			}
+		}
+		responseData = append(responseData, data)
 	}
 
 	// Add responses to page data using a template variable
-	tmpl := Templates["view_submission.html"]
+	tmpl := h.Templates["view_submission.html"]
 	if tmpl == nil {
 		http.Error(w, "Template not found", http.StatusInternalServerError)
 		return
 	}
 
 	// Execute template with responses
-	err := tmpl.ExecuteTemplate(w, "layout", struct {
+	data.User = auth.UserFromContext(r.Context())
+	err = tmpl.ExecuteTemplate(w, "layout", struct {
 		PageData
 		Responses []ResponseData
 	}{
@@ -113,7 +109,7 @@ func ViewSubmissionHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // ContinueSubmissionHandler allows users to continue an in-progress submission
-func ContinueSubmissionHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ContinueSubmissionHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract submission key from URL
 	submissionKey := r.URL.Path[len("/submissions/continue/"):]
 	if submissionKey == "" {
@@ -122,10 +118,9 @@ func ContinueSubmissionHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get submission
-	var submission database.Submission
-	result := database.DB.Where("submission_key = ?", submissionKey).First(&submission)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	submission, err := h.Submissions.SubmissionByKey(r.Context(), submissionKey)
+	if err != nil {
+		if err == store.ErrNotFound {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, "Failed to fetch submission", http.StatusInternalServerError)
@@ -133,63 +128,178 @@ func ContinueSubmissionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if submission is already completed
-	if submission.Status == "completed" {
+	// Check if submission is already finished
+	if submission.Status != "in_progress" {
 		http.Redirect(w, r, "/submissions/view/"+submissionKey, http.StatusSeeOther)
 		return
 	}
 
 	// Get form
-	var form database.Form
-	result = database.DB.First(&form, submission.FormID)
-	if result.Error != nil {
+	form, err := h.Forms.Form(r.Context(), submission.FormID)
+	if err != nil {
 		http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
 		return
 	}
 
-	// Check if form is still published
+	// Get event
+	event, err := h.Events.Event(r.Context(), form.EventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
+		return
+	}
+
+	// Check the form's schedule and response limits, then if it is still published
+	reason, err := formAvailability(r.Context(), h.Submissions, form, submission.RespondentKey)
+	if err != nil {
+		http.Error(w, "Failed to check form availability", http.StatusInternalServerError)
+		return
+	}
+	if reason != nil {
+		h.renderFormUnavailable(w, r, form, event, reason)
+		return
+	}
 	if !form.IsPublished {
 		http.Error(w, "This form is no longer available", http.StatusForbidden)
 		return
 	}
 
+	// Get the fields of the current step and previous responses to prefill the form
+	form, flow, err := h.loadFlow(r.Context(), form, submission.FormVersionID, submission.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch responses", http.StatusInternalServerError)
+		return
+	}
+
+	stepNumber, stepCount := flow.Position(submission.CurrentStep)
+	data := PageData{
+		Title:      form.Title + " - Continue from Step " + strconv.Itoa(stepNumber),
+		Form:       form,
+		Event:      event,
+		Fields:     flow.StepFields(submission.CurrentStep),
+		Submission: submission,
+		Answers:    flow.Answers(),
+		StepNumber: stepNumber,
+		StepCount:  stepCount,
+	}
+
+	h.renderFormStep(w, r, data)
+}
+
+// loadFlow loads form and every field of it as of the version with ID
+// versionID, and the answers a submission has given so far, so that the
+// form's branching rules can be evaluated. A submissionID of 0 stands for a
+// submission that has not answered anything yet.
+func (h *Handler) loadFlow(ctx context.Context, form database.Form, versionID *uint, submissionID uint) (database.Form, *branching.Flow, error) {
+	form, fields, err := h.Forms.FormAsOf(ctx, form, versionID)
+	if err != nil {
+		return form, nil, err
+	}
+
+	answers := map[uint]string{}
+	if submissionID != 0 {
+		if answers, err = h.loadAnswers(ctx, submissionID, fields); err != nil {
+			return form, nil, err
+		}
+	}
+	return form, branching.New(form, fields, answers), nil
+}
+
+// loadAnswers returns the stored answers of a submission to the given fields, keyed by field ID
+func (h *Handler) loadAnswers(ctx context.Context, submissionID uint, fields []database.FormField) (map[uint]string, error) {
+	responses, err := h.Submissions.Responses(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	byField := make(map[uint]string, len(responses))
+	for _, response := range responses {
+		byField[response.FieldID] = response.Response
+	}
+	answers := make(map[uint]string, len(fields))
+	for _, field := range fields {
+		if response, ok := byField[field.ID]; ok {
+			answers[field.ID] = response
+		}
+	}
+	return answers, nil
+}
+
+// submissionsPerPage is the number of submissions listed per page
+const submissionsPerPage = 50
+
+// ListSubmissionsHandler lists the submissions received by a form
+func (h *Handler) ListSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract form ID from URL
+	path := strings.TrimPrefix(r.URL.Path, "/forms/submissions/")
+	formID, err := strconv.ParseUint(path, 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get form
+	form, err := h.Forms.Form(r.Context(), uint(formID))
+	if err != nil {
+		if err == store.ErrNotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "Failed to fetch form", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	role, ok := h.requireEventRole(w, r, form.EventID, database.RoleViewer)
+	if !ok {
+		return
+	}
+
 	// Get event
-	var event database.Event
-	result = database.DB.First(&event, form.EventID)
-	if result.Error != nil {
+	event, err := h.Events.Event(r.Context(), form.EventID)
+	if err != nil {
 		http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
 		return
 	}
 
-	// Get form fields for the current step
-	var fields []database.FormField
-	result = database.DB.Where("form_id = ? AND step = ?", form.ID, submission.CurrentStep).
-		Order("field_order").Find(&fields)
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
-		http.Error(w, "Failed to fetch form fields", http.StatusInternalServerError)
-		return
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
 	}
 
-	// Get previous responses to prefill the form
-	for i := range fields {
-		var response database.SubmissionResponse
-		result = database.DB.Where("submission_id = ? AND field_id = ?", submission.ID, fields[i].ID).
-			First(&response)
+	// Get one page of submissions, newest first
+	submissions, err := h.Submissions.FormSubmissions(r.Context(), form.ID, (page-1)*submissionsPerPage, submissionsPerPage+1)
+	if err != nil {
+		http.Error(w, "Failed to fetch submissions", http.StatusInternalServerError)
+		return
+	}
 
-		if result.Error == nil {
-			// Create a custom attribute to hold the previous response
-			// This will be used in the template to prefill the form fields
-			fields[i].Placeholder = response.Response
-		}
+	// One extra row was fetched to find out whether there is a next page
+	hasNext := len(submissions) > submissionsPerPage
+	if hasNext {
+		submissions = submissions[:submissionsPerPage]
 	}
 
-	data := PageData{
-		Title:      form.Title + " - Continue from Step " + strconv.Itoa(submission.CurrentStep),
-		Form:       form,
-		Event:      event,
-		Fields:     fields,
-		Submission: submission,
+	data := struct {
+		PageData
+		Page     int
+		PrevPage int
+		NextPage int
+	}{
+		PageData: PageData{
+			Title:       "Submissions: " + form.Title,
+			Form:        form,
+			Event:       event,
+			Submissions: submissions,
+			Role:        role,
+			User:        auth.UserFromContext(r.Context()),
+		},
+		Page: page,
+	}
+	if page > 1 {
+		data.PrevPage = page - 1
+	}
+	if hasNext {
+		data.NextPage = page + 1
 	}
 
-	RenderTemplate(w, "view_form.html", data)
+	h.RenderTemplate(w, r, "form_submissions.html", data)
 }
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/store"
+	"github.com/yourusername/event-feedback/internal/webhooks"
+)
+
+// recentDeliveries is the number of deliveries shown in the delivery log
+const recentDeliveries = 50
+
+// EventWebhooksHandler displays the webhooks of an event and their delivery log
+func (h *Handler) EventWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract event ID from URL
+	path := strings.TrimPrefix(r.URL.Path, "/events/webhooks/")
+	eventID, err := strconv.ParseUint(path, 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get event
+	event, err := h.Events.Event(r.Context(), uint(eventID))
+	if err != nil {
+		if err == store.ErrNotFound {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "Failed to fetch event", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	role, ok := h.requireEventRole(w, r, event.ID, database.RoleOwner)
+	if !ok {
+		return
+	}
+
+	h.renderWebhooksPage(w, r, event, role, "")
+}
+
+// UpdateEventWebhooksHandler adds, switches on or off and removes webhooks,
+// and retries dead deliveries
+func (h *Handler) UpdateEventWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse form
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	// Parse event ID
+	eventID, err := strconv.ParseUint(r.FormValue("event_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	// Check if event exists
+	event, err := h.Events.Event(r.Context(), uint(eventID))
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	role, ok := h.requireEventRole(w, r, event.ID, database.RoleOwner)
+	if !ok {
+		return
+	}
+
+	action := r.FormValue("action") // Can be: add, toggle, remove, retry
+
+	switch action {
+	case "add":
+		url := strings.TrimSpace(r.FormValue("url"))
+		if err := webhooks.ValidateURL(url); err != nil {
+			h.renderWebhooksPage(w, r, event, role, "Invalid URL: "+err.Error())
+			return
+		}
+
+		hook := database.Webhook{EventID: event.ID, URL: url, IsActive: true}
+
+		// Limit the webhook to one form if one was chosen
+		if formIDStr := r.FormValue("form_id"); formIDStr != "" {
+			formID, err := strconv.ParseUint(formIDStr, 10, 64)
+			if err != nil {
+				http.Error(w, "Invalid form ID", http.StatusBadRequest)
+				return
+			}
+			form, err := h.Forms.Form(r.Context(), uint(formID))
+			if err != nil || form.EventID != event.ID {
+				http.Error(w, "Form not found", http.StatusNotFound)
+				return
+			}
+			hook.FormID = &form.ID
+		}
+
+		hook.Secret, err = webhooks.NewSecret()
+		if err != nil {
+			http.Error(w, "Failed to generate secret", http.StatusInternalServerError)
+			return
+		}
+
+		err = h.Webhooks.CreateWebhook(r.Context(), &hook)
+		if err != nil {
+			http.Error(w, "Failed to add webhook: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	case "toggle", "remove":
+		webhookID, err := strconv.ParseUint(r.FormValue("webhook_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+			return
+		}
+
+		hook, err := h.Webhooks.Webhook(r.Context(), event.ID, uint(webhookID))
+		if err != nil {
+			http.Error(w, "Webhook not found", http.StatusNotFound)
+			return
+		}
+
+		if action == "toggle" {
+			hook.IsActive = !hook.IsActive
+			err = h.Webhooks.SaveWebhook(r.Context(), &hook)
+		} else {
+			err = h.Webhooks.DeleteWebhook(r.Context(), hook)
+		}
+		if err != nil {
+			http.Error(w, "Failed to update webhook: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	case "retry":
+		deliveryID, err := strconv.ParseUint(r.FormValue("delivery_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid delivery ID", http.StatusBadRequest)
+			return
+		}
+
+		// Only dead deliveries to one of the event's webhooks are retried
+		err = h.Webhooks.RetryDelivery(r.Context(), event.ID, uint(deliveryID))
+		if err == store.ErrNotFound {
+			http.Error(w, "Delivery not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to retry delivery: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/events/webhooks/"+strconv.FormatUint(uint64(event.ID), 10), http.StatusSeeOther)
+}
+
+// renderWebhooksPage renders the webhooks and delivery log of an event
+func (h *Handler) renderWebhooksPage(w http.ResponseWriter, r *http.Request, event database.Event, role, errMsg string) {
+	hooks, err := h.Webhooks.EventWebhooks(r.Context(), event.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	// Get forms to offer as webhook scopes
+	forms, err := h.Forms.EventForms(r.Context(), event.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch forms", http.StatusInternalServerError)
+		return
+	}
+	sort.SliceStable(forms, func(i, j int) bool {
+		return forms[i].Title < forms[j].Title
+	})
+
+	// Get deliveries, including those of removed webhooks
+	recent, err := h.Webhooks.Deliveries(r.Context(), event.ID, "", recentDeliveries)
+	if err != nil {
+		http.Error(w, "Failed to fetch deliveries", http.StatusInternalServerError)
+		return
+	}
+	dead, err := h.Webhooks.Deliveries(r.Context(), event.ID, database.DeliveryDead, recentDeliveries)
+	if err != nil {
+		http.Error(w, "Failed to fetch deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		PageData
+		Webhooks    []database.Webhook
+		Deliveries  []database.WebhookDelivery
+		DeadLetters []database.WebhookDelivery
+	}{
+		PageData: PageData{
+			Title: "Webhooks: " + event.Name,
+			Error: errMsg,
+			Event: event,
+			Forms: forms,
+			Role:  role,
+			User:  auth.UserFromContext(r.Context()),
+		},
+		Webhooks:    hooks,
+		Deliveries:  recent,
+		DeadLetters: dead,
+	}
+
+	h.RenderTemplate(w, r, "event_webhooks.html", data)
+}
@@ -0,0 +1,69 @@
+// Package janitor purges abandoned submissions. A submission that is still in
+// progress and has not been saved for longer than the draft TTL is deleted
+// together with its answers, whether it was started in the browser or
+// through the API.
+package janitor
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+// DefaultTTL is how long in-progress submissions are kept unless configured otherwise
+const DefaultTTL = 7 * 24 * time.Hour
+
+// Purge deletes the in-progress submissions last saved before cutoff and
+// their answers, returning how many submissions were deleted
+func Purge(db *gorm.DB, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		abandoned := tx.Unscoped().Model(&database.Submission{}).Select("id").
+			Where("status = ? AND updated_at < ?", "in_progress", cutoff)
+
+		err := tx.Unscoped().Where("submission_id IN (?)", abandoned).Delete(&database.SubmissionResponse{}).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("status = ? AND updated_at < ?", "in_progress", cutoff).Delete(&database.Submission{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+// Worker periodically purges abandoned submissions
+type Worker struct {
+	DB       *gorm.DB
+	Interval time.Duration
+	TTL      time.Duration
+}
+
+// NewWorker returns a worker purging submissions older than ttl every hour
+func NewWorker(db *gorm.DB, ttl time.Duration) *Worker {
+	return &Worker{DB: db, Interval: time.Hour, TTL: ttl}
+}
+
+// Run works until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := Purge(w.DB, time.Now().Add(-w.TTL))
+		if err != nil {
+			log.Printf("Failed to purge abandoned submissions: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d abandoned submissions", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Package layout arranges the fields of a form: their order within a step,
+// moving them between steps, and inserting, deleting and reordering the steps
+// of multi-step forms. Every change numbers FieldOrder from 1 without gaps
+// within each step it touches, and a change that would leave a field
+// depending on a later field, or a step rule jumping backwards, is rejected.
+// Changes only compute the new layout; the stores save it.
+package layout
+
+import (
+	"fmt"
+
+	"github.com/yourusername/event-feedback/internal/database"
+)
+
+// InvalidError reports a change that cannot be made
+type InvalidError struct {
+	Reason string
+}
+
+func (e *InvalidError) Error() string { return e.Reason }
+
+// invalid returns an InvalidError with a formatted reason
+func invalid(format string, args ...interface{}) error {
+	return &InvalidError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// A Change rearranges the fields of a form. It is given the fields in step
+// and field order and returns them with their new step and field order;
+// fields it leaves out are deleted. It may change the step rules of form.
+type Change func(form *database.Form, fields []database.FormField) ([]database.FormField, error)
+
+// LastStep returns the highest step that has fields, or 1 for a form without fields
+func LastStep(fields []database.FormField) int {
+	last := 1
+	for _, field := range fields {
+		if field.Step > last {
+			last = field.Step
+		}
+	}
+	return last
+}
+
+// MoveField moves a field to the given 1-based position on step, shifting the
+// fields after it. Positions past the end of the step append the field.
+func MoveField(fieldID uint, step, position int) Change {
+	return func(form *database.Form, fields []database.FormField) ([]database.FormField, error) {
+		if step < 1 {
+			return nil, invalid("the step must be at least 1")
+		}
+		if !form.IsMultiStep && step != 1 {
+			return nil, invalid("single-page forms only have one step")
+		}
+
+		moving := -1
+		var target []int
+		for i := range fields {
+			if fields[i].ID == fieldID {
+				moving = i
+			} else if fields[i].Step == step {
+				target = append(target, i)
+			}
+		}
+		if moving < 0 {
+			return nil, invalid("field %d does not belong to this form", fieldID)
+		}
+		from := fields[moving].Step
+
+		if position < 1 {
+			position = 1
+		}
+		if position > len(target)+1 {
+			position = len(target) + 1
+		}
+		target = append(target[:position-1], append([]int{moving}, target[position-1:]...)...)
+
+		place(fields, target, step)
+		if from != step {
+			renumber(fields, from)
+			return fields, check(*form, fields)
+		}
+		return fields, nil
+	}
+}
+
+// Arrange sets the whole layout of a form at once. steps lists the IDs of the
+// fields of each step in order, starting with step 1, and must include every
+// field of the form exactly once. Steps may be left empty.
+func Arrange(steps [][]uint) Change {
+	return func(form *database.Form, fields []database.FormField) ([]database.FormField, error) {
+		if len(steps) == 0 {
+			return nil, invalid("the layout needs at least one step")
+		}
+		if !form.IsMultiStep && len(steps) > 1 {
+			return nil, invalid("single-page forms only have one step")
+		}
+
+		byID := make(map[uint]int, len(fields))
+		for i, field := range fields {
+			byID[field.ID] = i
+		}
+
+		placed := map[uint]bool{}
+		for i, ids := range steps {
+			list := make([]int, 0, len(ids))
+			for _, id := range ids {
+				index, ok := byID[id]
+				if !ok {
+					return nil, invalid("field %d does not belong to this form", id)
+				}
+				if placed[id] {
+					return nil, invalid("field %d is listed more than once", id)
+				}
+				placed[id] = true
+				list = append(list, index)
+			}
+			place(fields, list, i+1)
+		}
+		if len(placed) != len(fields) {
+			return nil, invalid("the layout must list every field of the form")
+		}
+		return fields, check(*form, fields)
+	}
+}
+
+// Renumber numbers the fields of the given steps of a form from 1 in their
+// current order, closing gaps left by deleted or moved fields
+func Renumber(steps ...int) Change {
+	return func(form *database.Form, fields []database.FormField) ([]database.FormField, error) {
+		renumber(fields, steps...)
+		return fields, nil
+	}
+}
+
+// InsertStep inserts an empty step before step, moving it and the steps after
+// it down by one
+func InsertStep(step int) Change {
+	return func(form *database.Form, fields []database.FormField) ([]database.FormField, error) {
+		last := LastStep(fields)
+		if step < 1 || step > last+1 {
+			return nil, invalid("step %d does not exist", step)
+		}
+
+		mapping := map[int]int{}
+		for s := 1; s <= last; s++ {
+			mapping[s] = s
+			if s >= step {
+				mapping[s] = s + 1
+			}
+		}
+		return renumberSteps(form, fields, mapping)
+	}
+}
+
+// DeleteStep deletes a step together with its fields and step rules, moving
+// the steps after it up by one. Rules that went to the deleted step go to the
+// step that takes its place.
+func DeleteStep(step int) Change {
+	return func(form *database.Form, fields []database.FormField) ([]database.FormField, error) {
+		last := LastStep(fields)
+		if step < 1 || step > last {
+			return nil, invalid("step %d does not exist", step)
+		}
+
+		mapping := map[int]int{}
+		for s := 1; s <= last; s++ {
+			switch {
+			case s < step:
+				mapping[s] = s
+			case s > step:
+				mapping[s] = s - 1
+			}
+		}
+		return renumberSteps(form, fields, mapping)
+	}
+}
+
+// MoveStep moves step from to position to, shifting the steps in between
+func MoveStep(from, to int) Change {
+	return func(form *database.Form, fields []database.FormField) ([]database.FormField, error) {
+		last := LastStep(fields)
+		if from < 1 || from > last {
+			return nil, invalid("step %d does not exist", from)
+		}
+		if to < 1 || to > last {
+			return nil, invalid("step %d does not exist", to)
+		}
+
+		mapping := map[int]int{}
+		for s := 1; s <= last; s++ {
+			switch {
+			case s == from:
+				mapping[s] = to
+			case from < to && s > from && s <= to:
+				mapping[s] = s - 1
+			case to < from && s >= to && s < from:
+				mapping[s] = s + 1
+			default:
+				mapping[s] = s
+			}
+		}
+		return renumberSteps(form, fields, mapping)
+	}
+}
+
+// renumberSteps moves the fields and step rules of each step to the step
+// mapping gives it. Steps missing from mapping are deleted.
+func renumberSteps(form *database.Form, fields []database.FormField, mapping map[int]int) ([]database.FormField, error) {
+	kept := make([]database.FormField, 0, len(fields))
+	for _, field := range fields {
+		if step, ok := mapping[field.Step]; ok {
+			field.Step = step
+			kept = append(kept, field)
+		}
+	}
+
+	// Steps that are deleted or past the last one lead on to the next step that is kept
+	last := 0
+	for s := range mapping {
+		if s > last {
+			last = s
+		}
+	}
+	target := func(step int) int {
+		for s := step; s <= last; s++ {
+			if mapped, ok := mapping[s]; ok {
+				return mapped
+			}
+		}
+		return 0
+	}
+
+	rules := database.StepRules{}
+	for _, rule := range form.StepRules {
+		step, ok := mapping[rule.Step]
+		if !ok {
+			continue
+		}
+		rule.Step = step
+		if rule.GoTo != 0 {
+			rule.GoTo = target(rule.GoTo)
+		}
+		rules = append(rules, rule)
+	}
+	form.StepRules = rules
+
+	return kept, check(*form, kept)
+}
+
+// place numbers the fields at the given indexes from 1 on step
+func place(fields []database.FormField, indexes []int, step int) {
+	for i, index := range indexes {
+		fields[index].Step = step
+		fields[index].FieldOrder = i + 1
+	}
+}
+
+// renumber numbers the fields of the given steps from 1 in their current order
+func renumber(fields []database.FormField, steps ...int) {
+	for _, step := range steps {
+		var indexes []int
+		for i, field := range fields {
+			if field.Step == step {
+				indexes = append(indexes, i)
+			}
+		}
+		place(fields, indexes, step)
+	}
+}
+
+// check makes sure that fields only depend on fields of earlier steps and
+// that step rules only jump forward and test fields up to their own step.
+// Conditions on deleted fields are ignored, as they have no effect.
+func check(form database.Form, fields []database.FormField) error {
+	byID := make(map[uint]database.FormField, len(fields))
+	for _, field := range fields {
+		byID[field.ID] = field
+	}
+
+	for _, field := range fields {
+		target, ok := byID[field.ShowIf.FieldID]
+		if !field.ShowIf.IsSet() || !ok {
+			continue
+		}
+		if target.Step >= field.Step {
+			return invalid("%q is shown depending on %q, which would no longer be on an earlier step", field.Label, target.Label)
+		}
+	}
+
+	for _, rule := range form.StepRules {
+		if rule.GoTo != 0 && rule.GoTo <= rule.Step {
+			return invalid("the rule after step %d would go back to step %d", rule.Step, rule.GoTo)
+		}
+		target, ok := byID[rule.Condition.FieldID]
+		if ok && target.Step > rule.Step {
+			return invalid("the rule after step %d would test %q, which would come after it", rule.Step, target.Label)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+// Package metrics counts and times what the server does and serves the
+// results in the Prometheus text exposition format, for Prometheus or any
+// compatible scraper to collect from /metrics.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds metrics and writes them out in the order they were added
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// metric is anything the registry can write out
+type metric interface {
+	write(buf *bytes.Buffer)
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// add registers m under name, panicking if the name is taken
+func (r *Registry) add(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.names[name] {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.names[name] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name, help, "counter", labels}, series: make(map[string]*counterSeries)}
+	r.add(name, c)
+	return c
+}
+
+// NewHistogram registers a histogram with the given upper bounds of its
+// buckets, in increasing order, and label names
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not in increasing order", name))
+	}
+	h := &Histogram{desc: desc{name, help, "histogram", labels}, buckets: buckets, series: make(map[string]*histogramSeries)}
+	r.add(name, h)
+	return h
+}
+
+// NewGaugeFunc registers a gauge whose value fn returns when it is collected
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.add(name, &funcMetric{desc: desc{name: name, help: help, kind: "gauge"}, fn: fn})
+}
+
+// NewCounterFunc registers a counter whose value fn returns when it is collected
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.add(name, &funcMetric{desc: desc{name: name, help: help, kind: "counter"}, fn: fn})
+}
+
+// ServeHTTP writes every metric in the text exposition format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	metrics := r.metrics
+	r.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		m.write(&buf)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(buf.Bytes())
+}
+
+// desc describes a metric
+type desc struct {
+	name   string
+	help   string
+	kind   string // counter, gauge or histogram
+	labels []string
+}
+
+// writeHeader writes the HELP and TYPE lines of the metric
+func (d desc) writeHeader(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", d.name, helpEscaper.Replace(d.help), d.name, d.kind)
+}
+
+// key returns the key of a series, checking it has a value for every label
+func (d desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", d.name, len(d.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// writeSample writes one sample line of the metric, with an extra label
+// after the metric's own ones if extraName is not empty
+func (d desc) writeSample(buf *bytes.Buffer, suffix string, labelValues []string, extraName, extraValue string, value float64) {
+	buf.WriteString(d.name)
+	buf.WriteString(suffix)
+
+	if len(labelValues) > 0 || extraName != "" {
+		buf.WriteByte('{')
+		for i, value := range labelValues {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeLabel(buf, d.labels[i], value)
+		}
+		if extraName != "" {
+			if len(labelValues) > 0 {
+				buf.WriteByte(',')
+			}
+			writeLabel(buf, extraName, extraValue)
+		}
+		buf.WriteByte('}')
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(formatValue(value))
+	buf.WriteByte('\n')
+}
+
+// helpEscaper escapes help texts as the exposition format requires
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writeLabel writes name="value"
+func writeLabel(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(`="`)
+	buf.WriteString(labelEscaper.Replace(value))
+	buf.WriteByte('"')
+}
+
+// formatValue formats a sample value, spelling infinity as Prometheus does
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Counter is a value that only goes up, kept for every combination of label values
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+// counterSeries is the value of a counter for one combination of label values
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc adds 1 to the counter for the given label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds value, which cannot be negative, to the counter for the given label values
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		panic(fmt.Sprintf("metrics: %s cannot decrease", c.name))
+	}
+	key := c.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labelValues: append([]string(nil), labelValues...)}
+		c.series[key] = s
+	}
+	s.value += value
+}
+
+// write implements metric
+func (c *Counter) write(buf *bytes.Buffer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Write the series in order, so the output is stable
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	c.writeHeader(buf)
+	for _, key := range keys {
+		s := c.series[key]
+		c.writeSample(buf, "", s.labelValues, "", "", s.value)
+	}
+}
+
+// Histogram counts observed values, such as request durations, in buckets,
+// kept for every combination of label values
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+// histogramSeries is a histogram for one combination of label values
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // observations in each bucket, not cumulative
+	sum         float64
+	count       uint64
+}
+
+// Observe records value for the given label values
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += value
+	s.count++
+}
+
+// write implements metric
+func (h *Histogram) write(buf *bytes.Buffer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Write the series in order, so the output is stable
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h.writeHeader(buf)
+	for _, key := range keys {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			h.writeSample(buf, "_bucket", s.labelValues, "le", formatValue(bound), float64(cumulative))
+		}
+		h.writeSample(buf, "_bucket", s.labelValues, "le", "+Inf", float64(s.count))
+		h.writeSample(buf, "_sum", s.labelValues, "", "", s.sum)
+		h.writeSample(buf, "_count", s.labelValues, "", "", float64(s.count))
+	}
+}
+
+// funcMetric is a gauge or counter without labels whose value is read when
+// it is collected
+type funcMetric struct {
+	desc
+	fn func() float64
+}
+
+// write implements metric
+func (f *funcMetric) write(buf *bytes.Buffer) {
+	f.writeHeader(buf)
+	f.writeSample(buf, "", nil, "", "", f.fn())
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+)
+
+// DurationBuckets are the upper bounds, in seconds, of the request duration buckets
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Server holds the metrics the server records. Requests are labelled by the
+// route pattern that served them rather than their path, so IDs in paths do
+// not create a series each.
+type Server struct {
+	*Registry
+
+	Requests        *Counter   // labelled method, route and status
+	RequestDuration *Histogram // labelled method and route
+
+	// The respondent funnel, labelled form_id
+	SubmissionsStarted   *Counter
+	StepsAdvanced        *Counter
+	SubmissionsCompleted *Counter
+	SubmissionsFlagged   *Counter
+}
+
+// NewServer returns the server's metrics in a registry of their own
+func NewServer() *Server {
+	r := NewRegistry()
+	return &Server{
+		Registry: r,
+
+		Requests: r.NewCounter("http_requests_total",
+			"HTTP requests served, by route pattern.", "method", "route", "status"),
+		RequestDuration: r.NewHistogram("http_request_duration_seconds",
+			"Time taken to serve HTTP requests, by route pattern.", DurationBuckets, "method", "route"),
+
+		SubmissionsStarted: r.NewCounter("feedback_submissions_started_total",
+			"Submissions respondents started by sending the first step of a form.", "form_id"),
+		StepsAdvanced: r.NewCounter("feedback_steps_advanced_total",
+			"Steps respondents moved on from to the next step of a form.", "form_id"),
+		SubmissionsCompleted: r.NewCounter("feedback_submissions_completed_total",
+			"Submissions completed by respondents, or approved by moderators after being flagged.", "form_id"),
+		SubmissionsFlagged: r.NewCounter("feedback_submissions_flagged_total",
+			"Submissions held for moderation as likely spam when respondents finished them.", "form_id"),
+	}
+}
+
+// FormLabel returns the form_id label value of a form
+func FormLabel(formID uint) string {
+	return strconv.FormatUint(uint64(formID), 10)
+}
+
+// RegisterDBStats adds gauges and counters of db's connection pool, read
+// from db.Stats() whenever the metrics are collected
+func (s *Server) RegisterDBStats(db *sql.DB) {
+	stats := []struct {
+		name, help string
+		counter    bool
+		value      func(stats sql.DBStats) float64
+	}{
+		{"db_max_open_connections", "Most connections the pool opens.", false,
+			func(stats sql.DBStats) float64 { return float64(stats.MaxOpenConnections) }},
+		{"db_open_connections", "Connections open, in use or idle.", false,
+			func(stats sql.DBStats) float64 { return float64(stats.OpenConnections) }},
+		{"db_in_use_connections", "Connections in use.", false,
+			func(stats sql.DBStats) float64 { return float64(stats.InUse) }},
+		{"db_idle_connections", "Idle connections.", false,
+			func(stats sql.DBStats) float64 { return float64(stats.Idle) }},
+		{"db_wait_count_total", "Times a query waited for a free connection.", true,
+			func(stats sql.DBStats) float64 { return float64(stats.WaitCount) }},
+		{"db_wait_duration_seconds_total", "Time spent waiting for a free connection.", true,
+			func(stats sql.DBStats) float64 { return stats.WaitDuration.Seconds() }},
+		{"db_max_idle_closed_total", "Connections closed because the pool had too many idle ones.", true,
+			func(stats sql.DBStats) float64 { return float64(stats.MaxIdleClosed) }},
+		{"db_max_idle_time_closed_total", "Connections closed for being idle too long.", true,
+			func(stats sql.DBStats) float64 { return float64(stats.MaxIdleTimeClosed) }},
+		{"db_max_lifetime_closed_total", "Connections closed for reaching their maximum lifetime.", true,
+			func(stats sql.DBStats) float64 { return float64(stats.MaxLifetimeClosed) }},
+	}
+	for _, stat := range stats {
+		value := stat.value
+		collect := func() float64 { return value(db.Stats()) }
+		if stat.counter {
+			s.NewCounterFunc(stat.name, stat.help, collect)
+		} else {
+			s.NewGaugeFunc(stat.name, stat.help, collect)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/yourusername/event-feedback/internal/auth"
+	"github.com/yourusername/event-feedback/internal/store"
+)
+
+// Authenticate identifies the logged-in user from the session cookie, or from
+// HTTP basic auth for scripted API clients, looking them up in users, and
+// stores them in the request context
+func Authenticate(users store.UserStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.UserFromSession(r, users)
+		if err != nil {
+			log.Printf("Failed to load session: %v", err)
+		}
+
+		if user == nil {
+			if email, password, ok := r.BasicAuth(); ok {
+				user, err = auth.Authenticate(r.Context(), users, email, password)
+				if err != nil {
+					w.Header().Set("WWW-Authenticate", `Basic realm="event-feedback"`)
+					http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+					return
+				}
+			}
+		}
+
+		if user != nil {
+			r = r.WithContext(auth.WithUser(r.Context(), user))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
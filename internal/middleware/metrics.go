@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/metrics"
+)
+
+// Measure counts and times requests in m, labelled by the pattern of the
+// route in routes that serves them
+func Measure(m *metrics.Server, routes *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Label by pattern rather than path, so that IDs in paths do not
+		// create a series each
+		_, route := routes.Handler(r)
+		if route == "" {
+			route = "unmatched" // e.g. redirects to the clean form of a path
+		}
+
+		lrw := &loggingResponseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+		next.ServeHTTP(lrw, r)
+
+		method := metricMethod(r.Method)
+		m.Requests.Inc(method, route, strconv.Itoa(lrw.statusCode))
+		m.RequestDuration.Observe(time.Since(start).Seconds(), method, route)
+	})
+}
+
+// metricMethod returns method if it is a standard HTTP method, or "other",
+// so clients cannot create series with made-up methods
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "other"
+}
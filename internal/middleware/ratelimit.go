@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/respondent"
+)
+
+// Rate is how many requests are allowed per minute, with bursts of up to Burst
+type Rate struct {
+	PerMinute int
+	Burst     int
+}
+
+// RateLimiter limits how often the public form pages are requested, using a
+// token bucket for each respondent, client IP and form. Respondents are told
+// apart by the signed cookie the form pages give their browser, so that
+// people sharing an IP address, such as attendees on venue Wi-Fi, do not
+// share a limit; the per-IP limit is looser and stops clients dropping the
+// cookie. Behind a reverse proxy, TrustProxy must be set, as otherwise every
+// request comes from the proxy's IP address.
+type RateLimiter struct {
+	PerRespondent Rate
+	PerIP         Rate
+	PerForm       Rate
+	// Respondents recognizes respondent cookies; without it, respondents are
+	// only limited by their IP address
+	Respondents *respondent.Cookies
+	// TrustProxy takes the client IP from the last X-Forwarded-For entry,
+	// the one the reverse proxy in front of the server added
+	TrustProxy bool
+
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastSweep   time.Time
+	proxyWarned sync.Once
+}
+
+// bucket holds the tokens left for one respondent, client IP or form
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter returns a rate limiter allowing 30 requests a minute from a
+// respondent, 300 a minute from an IP address and 3000 a minute to a form
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		PerRespondent: Rate{PerMinute: 30, Burst: 10},
+		PerIP:         Rate{PerMinute: 300, Burst: 100},
+		PerForm:       Rate{PerMinute: 3000, Burst: 500},
+		buckets:       make(map[string]*bucket),
+	}
+}
+
+// RateLimitConfig configures a rate limiter. Limits are requests per minute,
+// and a limit of 0 turns that limit off.
+type RateLimitConfig struct {
+	PerRespondent int  `yaml:"per_respondent"`
+	PerIP         int  `yaml:"per_ip"`
+	PerForm       int  `yaml:"per_form"`
+	TrustProxy    bool `yaml:"trust_proxy"` // must be set behind a reverse proxy
+}
+
+// RateLimiterFromConfig returns a rate limiter with the limits in cfg,
+// keeping the default bursts unless they exceed a limit
+func RateLimiterFromConfig(cfg RateLimitConfig) (*RateLimiter, error) {
+	limiter := NewRateLimiter()
+	limiter.TrustProxy = cfg.TrustProxy
+
+	rates := []struct {
+		name      string
+		perMinute int
+		rate      *Rate
+	}{
+		{"per respondent", cfg.PerRespondent, &limiter.PerRespondent},
+		{"per IP", cfg.PerIP, &limiter.PerIP},
+		{"per form", cfg.PerForm, &limiter.PerForm},
+	}
+	for _, r := range rates {
+		if r.perMinute < 0 {
+			return nil, fmt.Errorf("rate limit %s cannot be negative, got %d", r.name, r.perMinute)
+		}
+		r.rate.PerMinute = r.perMinute
+		if r.rate.Burst > r.perMinute {
+			r.rate.Burst = r.perMinute
+		}
+	}
+	return limiter, nil
+}
+
+// Limit rejects requests to the public form pages that exceed the limits
+// with 429 Too Many Requests. Other requests pass through untouched.
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		formID, ok := publicFormID(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The IP address goes first, so that clients it limits cannot make
+		// buckets for respondents, and only cookies the server gave out
+		// get a bucket of their own
+		now := time.Now()
+		key, isRespondent := "", false
+		if l.Respondents != nil {
+			key, isRespondent = l.Respondents.Key(r)
+		}
+		if !l.allow("ip:"+l.clientIP(r), l.PerIP, now) ||
+			(isRespondent && !l.allow("respondent:"+key, l.PerRespondent, now)) ||
+			(formID != "" && !l.allow("form:"+formID, l.PerForm, now)) {
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, "Too many requests, please try again later", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from the bucket for key, reporting whether there was one
+func (l *RateLimiter) allow(key string, rate Rate, now time.Time) bool {
+	if rate.PerMinute == 0 {
+		return true
+	}
+	burst := float64(rate.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updated: now}
+		l.buckets[key] = b
+	}
+	refill := now.Sub(b.updated).Minutes() * float64(rate.PerMinute)
+	b.tokens = math.Min(burst, b.tokens+refill)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep forgets buckets that have not been used for a while, as they are
+// full again by then
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < 10*time.Minute {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) > 10*time.Minute {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// clientIP returns the IP address of the client that sent r. Behind a proxy
+// it is the last X-Forwarded-For entry, as clients can send the header with
+// entries of their own, which proxies append to.
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	forwarded := strings.Join(r.Header.Values("X-Forwarded-For"), ",")
+	switch {
+	case forwarded == "":
+	case l.TrustProxy:
+		if last := strings.TrimSpace(forwarded[strings.LastIndex(forwarded, ",")+1:]); last != "" {
+			return last
+		}
+	default:
+		l.proxyWarned.Do(func() {
+			log.Printf("Requests arrive through a proxy but TRUST_PROXY is off, so clients behind it share one rate limit")
+		})
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// publicFormID reports whether r is for a page respondents fill in forms
+// on, and the ID of the form if the request names one
+func publicFormID(r *http.Request) (string, bool) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/forms/view/"):
+		return strings.TrimPrefix(r.URL.Path, "/forms/view/"), true
+	case strings.HasPrefix(r.URL.Path, "/forms/submit/") && r.Method == http.MethodPost:
+		return r.PostFormValue("form_id"), true
+	case strings.HasPrefix(r.URL.Path, "/submissions/continue/"):
+		return "", true
+	}
+	return "", false
+}
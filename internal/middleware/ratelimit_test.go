@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/event-feedback/internal/respondent"
+)
+
+// formRequest returns a request for a public form page from remoteAddr
+func formRequest(remoteAddr string, cookie *http.Cookie) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/forms/view/1", nil)
+	r.RemoteAddr = remoteAddr
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+func TestRateLimiterBucketsOnlyIssuedCookies(t *testing.T) {
+	limiter := NewRateLimiter()
+	limiter.Respondents = respondent.New(nil)
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// A cookie the server gave out gets a bucket of its own
+	rec := httptest.NewRecorder()
+	if _, err := limiter.Respondents.Identify(rec, formRequest("192.0.2.1:1234", nil)); err != nil {
+		t.Fatal(err)
+	}
+	issued := rec.Result().Cookies()[0]
+	key, _ := limiter.Respondents.Key(formRequest("192.0.2.1:1234", issued))
+	handler.ServeHTTP(httptest.NewRecorder(), formRequest("192.0.2.1:1234", issued))
+	if _, ok := limiter.buckets["respondent:"+key]; !ok {
+		t.Errorf("no bucket for an issued cookie")
+	}
+
+	// Made up cookies do not, and once the IP address is limited nothing does
+	for i := 0; i < limiter.PerIP.Burst*2; i++ {
+		cookie := &http.Cookie{Name: respondent.CookieName, Value: "forged" + string(rune('a'+i%26))}
+		handler.ServeHTTP(httptest.NewRecorder(), formRequest("192.0.2.2:1234", cookie))
+	}
+	if len(limiter.buckets) != 4 {
+		t.Errorf("got %d buckets, want one each for 2 IP addresses, the respondent and the form", len(limiter.buckets))
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, formRequest("192.0.2.2:1234", issued))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d from a limited IP address, want 429", rec.Code)
+	}
+}
+
+func TestRateLimiterClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		forwarded  []string
+		want       string
+	}{
+		{name: "direct", want: "192.0.2.1"},
+		{name: "untrusted proxy", forwarded: []string{"198.51.100.7"}, want: "192.0.2.1"},
+		{name: "trusted proxy", trustProxy: true, forwarded: []string{"198.51.100.7"}, want: "198.51.100.7"},
+		{name: "client sent its own entry", trustProxy: true, forwarded: []string{"203.0.113.9, 198.51.100.7"}, want: "198.51.100.7"},
+		{name: "several headers", trustProxy: true, forwarded: []string{"203.0.113.9", "198.51.100.7"}, want: "198.51.100.7"},
+		{name: "empty last entry", trustProxy: true, forwarded: []string{"203.0.113.9, "}, want: "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter()
+			limiter.TrustProxy = tt.trustProxy
+			r := formRequest("192.0.2.1:1234", nil)
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if got := limiter.clientIP(r); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
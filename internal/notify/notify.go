@@ -0,0 +1,253 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+// BaseURL is the address the application is reachable at, which links in
+// emails point to. The server sets it from its configuration at startup.
+var BaseURL = "http://localhost:8080"
+
+// NotifyModes lists the valid values of Form.NotifyMode
+var NotifyModes = []string{database.NotifyNone, database.NotifyEach, database.NotifyDigest}
+
+// IsValidNotifyMode reports whether mode is a valid notification mode
+func IsValidNotifyMode(mode string) bool {
+	for _, m := range NotifyModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// SubmissionCompleted queues the emails a completed submission triggers: a
+// notification to the event owners if the form notifies on each submission,
+// and a receipt to the respondent if the form sends receipts. It must be
+// called in the transaction that completes the submission.
+func SubmissionCompleted(tx *gorm.DB, form database.Form, submission database.Submission) error {
+	if form.NotifyMode != database.NotifyEach && !form.SendReceipts {
+		return nil
+	}
+
+	var event database.Event
+	if err := tx.First(&event, form.EventID).Error; err != nil {
+		return err
+	}
+
+	answers, err := submissionAnswers(tx, submission)
+	if err != nil {
+		return err
+	}
+	link := baseURL() + "/submissions/view/" + submission.SubmissionKey
+
+	if form.NotifyMode == database.NotifyEach {
+		owners, err := ownerEmails(tx, form.EventID)
+		if err != nil {
+			return err
+		}
+
+		var body strings.Builder
+		fmt.Fprintf(&body, "A new response to %q for %s was submitted.\n\n", form.Title, event.Name)
+		for _, answer := range answers {
+			fmt.Fprintf(&body, "%s: %s\n", answer.label, answer.value)
+		}
+		fmt.Fprintf(&body, "\nView the response: %s\n", link)
+		fmt.Fprintf(&body, "All responses: %s/forms/submissions/%d\n", baseURL(), form.ID)
+
+		for _, owner := range owners {
+			msg := Message{To: owner, Subject: "New response: " + form.Title, Body: body.String()}
+			if err := Enqueue(tx, msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	if form.SendReceipts {
+		// Receipts go to the first email address the respondent gave
+		for _, answer := range answers {
+			if answer.fieldType != "email" || answer.value == "" {
+				continue
+			}
+
+			var body strings.Builder
+			fmt.Fprintf(&body, "Thank you for your response to %q for %s.\n\n", form.Title, event.Name)
+			fmt.Fprintf(&body, "You can review your answers at any time:\n%s\n", link)
+
+			msg := Message{To: answer.value, Subject: "Your response: " + form.Title, Body: body.String()}
+			return Enqueue(tx, msg)
+		}
+	}
+	return nil
+}
+
+// QueueDigests queues a summary email to the event owners of every digest
+// form whose last digest is at least interval old and that received completed
+// submissions since
+func QueueDigests(db *gorm.DB, interval time.Duration) error {
+	var forms []database.Form
+	if err := db.Where("notify_mode = ?", database.NotifyDigest).Find(&forms).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, form := range forms {
+		since := form.CreatedAt
+		if form.LastDigestAt.Valid {
+			since = form.LastDigestAt.Time
+		}
+		if now.Sub(since) < interval {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Move the digest window first, so that a digest is only queued by
+			// whoever wins if several workers run at once
+			claim := tx.Model(&database.Form{}).Where("id = ?", form.ID)
+			if form.LastDigestAt.Valid {
+				claim = claim.Where("last_digest_at = ?", form.LastDigestAt.Time)
+			} else {
+				claim = claim.Where("last_digest_at IS NULL")
+			}
+			result := claim.Update("last_digest_at", now)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			var count int64
+			err := tx.Model(&database.Submission{}).
+				Where("form_id = ? AND status = ? AND completed_at > ? AND completed_at <= ?", form.ID, "completed", since, now).
+				Count(&count).Error
+			if err != nil || count == 0 {
+				return err
+			}
+			return queueDigest(tx, form, count, since)
+		})
+		if err != nil {
+			return fmt.Errorf("digest for form %d: %w", form.ID, err)
+		}
+	}
+	return nil
+}
+
+// queueDigest queues the digest of form to the event owners
+func queueDigest(tx *gorm.DB, form database.Form, count int64, since time.Time) error {
+	var event database.Event
+	if err := tx.First(&event, form.EventID).Error; err != nil {
+		return err
+	}
+	owners, err := ownerEmails(tx, form.EventID)
+	if err != nil {
+		return err
+	}
+
+	noun := "responses"
+	if count == 1 {
+		noun = "response"
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "%q for %s received %d new %s since %s.\n\n", form.Title, event.Name, count, noun, since.Format("Jan 2, 2006 15:04"))
+	fmt.Fprintf(&body, "All responses: %s/forms/submissions/%d\n", baseURL(), form.ID)
+	fmt.Fprintf(&body, "Results: %s/forms/results/%d\n", baseURL(), form.ID)
+
+	for _, owner := range owners {
+		msg := Message{To: owner, Subject: fmt.Sprintf("%d new %s: %s", count, noun, form.Title), Body: body.String()}
+		if err := Enqueue(tx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScheduleChanged queues an email to the event owners telling them that a
+// scheduled form has opened or closed
+func ScheduleChanged(tx *gorm.DB, form database.Form, state string) error {
+	var event database.Event
+	if err := tx.First(&event, form.EventID).Error; err != nil {
+		return err
+	}
+	owners, err := ownerEmails(tx, form.EventID)
+	if err != nil {
+		return err
+	}
+
+	var subject string
+	var body strings.Builder
+	switch state {
+	case database.ScheduleOpen:
+		subject = "Form opened: " + form.Title
+		fmt.Fprintf(&body, "%q for %s is now open for responses", form.Title, event.Name)
+		if form.ClosesAt.Valid {
+			fmt.Fprintf(&body, " until %s", form.ClosesAt.Time.Format("Jan 2, 2006 15:04"))
+		}
+		fmt.Fprintf(&body, ".\n\nForm: %s/forms/view/%d\n", baseURL(), form.ID)
+	case database.ScheduleClosed:
+		subject = "Form closed: " + form.Title
+		fmt.Fprintf(&body, "%q for %s has closed and no longer accepts responses.\n\n", form.Title, event.Name)
+		fmt.Fprintf(&body, "Results: %s/forms/results/%d\n", baseURL(), form.ID)
+	default:
+		return nil
+	}
+
+	for _, owner := range owners {
+		if err := Enqueue(tx, Message{To: owner, Subject: subject, Body: body.String()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// answer is a submitted answer formatted for an email
+type answer struct {
+	label     string
+	fieldType string
+	value     string
+}
+
+// submissionAnswers returns the answers of a submission in form order, with
+// option values replaced by their labels
+func submissionAnswers(tx *gorm.DB, submission database.Submission) ([]answer, error) {
+	submitted, err := database.SubmissionAnswers(tx, submission)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([]answer, 0, len(submitted))
+	for _, resp := range submitted {
+		field := resp.Field
+		values := []string{resp.Response}
+		if database.IsMultiValueField(field.FieldType) {
+			values = database.SplitResponseValues(resp.Response)
+		}
+		for i, value := range values {
+			values[i] = field.Options.Label(value)
+		}
+		answers = append(answers, answer{
+			label:     field.Label,
+			fieldType: field.FieldType,
+			value:     strings.Join(values, "; "),
+		})
+	}
+	return answers, nil
+}
+
+// ownerEmails returns the email addresses of the owners of an event
+func ownerEmails(tx *gorm.DB, eventID uint) ([]string, error) {
+	var emails []string
+	err := tx.Model(&database.User{}).
+		Joins("JOIN event_members ON event_members.user_id = users.id AND event_members.deleted_at IS NULL").
+		Where("event_members.event_id = ? AND event_members.role = ?", eventID, database.RoleOwner).
+		Order("users.id").
+		Pluck("users.email", &emails).Error
+	return emails, err
+}
+
+// baseURL returns BaseURL without a trailing slash, for links in emails
+func baseURL() string {
+	return strings.TrimSuffix(BaseURL, "/")
+}
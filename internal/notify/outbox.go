@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxAttempts is how often delivery of an email is tried before it is marked failed
+	MaxAttempts = 8
+	// sendTimeout bounds a single delivery attempt
+	sendTimeout = 30 * time.Second
+	// deliveryBatch is the largest number of emails delivered per run
+	deliveryBatch = 50
+)
+
+// Enqueue adds an email to the outbox, to be delivered by a Worker. Pass the
+// transaction that makes the change the email is about, so that the email is
+// only sent if the change is committed.
+func Enqueue(tx *gorm.DB, msg Message) error {
+	return tx.Create(&database.OutboxEmail{
+		To:            msg.To,
+		Subject:       msg.Subject,
+		Body:          msg.Body,
+		Status:        database.EmailPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// Deliver tries to send the pending emails that are due, and returns how many
+// were sent. Failed attempts are retried later with exponential backoff.
+func Deliver(ctx context.Context, db *gorm.DB, mailer Mailer) (int, error) {
+	var emails []database.OutboxEmail
+	err := db.Where("status = ? AND next_attempt_at <= ?", database.EmailPending, time.Now()).
+		Order("next_attempt_at, id").Limit(deliveryBatch).Find(&emails).Error
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, email := range emails {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		// Claim the email by counting the attempt, so that other workers skip it
+		// until the attempt has had time to finish
+		result := db.Model(&database.OutboxEmail{}).
+			Where("id = ? AND status = ? AND attempts = ?", email.ID, database.EmailPending, email.Attempts).
+			Updates(map[string]interface{}{
+				"attempts":        email.Attempts + 1,
+				"next_attempt_at": time.Now().Add(backoff(email.Attempts + 1)),
+			})
+		if result.Error != nil {
+			return sent, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		email.Attempts++
+
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := mailer.Send(sendCtx, Message{To: email.To, Subject: email.Subject, Body: email.Body})
+		cancel()
+
+		updates := map[string]interface{}{}
+		if err == nil {
+			updates["status"] = database.EmailSent
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+			sent++
+		} else {
+			log.Printf("Failed to send email %d to %s (attempt %d): %v", email.ID, email.To, email.Attempts, err)
+			updates["last_error"] = err.Error()
+			if email.Attempts >= MaxAttempts {
+				updates["status"] = database.EmailFailed
+			}
+		}
+		if err := db.Model(&email).Updates(updates).Error; err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// backoff returns how long to wait before the attempt after the given one:
+// one minute after the first, doubling up to six hours
+func backoff(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < 6*time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+	return delay
+}
+
+// Worker periodically queues digests and delivers the outbox
+type Worker struct {
+	DB             *gorm.DB
+	Mailer         Mailer
+	Interval       time.Duration // how often the outbox is checked
+	DigestInterval time.Duration // how often digest forms send a summary
+}
+
+// DefaultDigestInterval is how often digest forms send a summary unless configured otherwise
+const DefaultDigestInterval = 24 * time.Hour
+
+// NewWorker returns a worker checking the outbox every 30 seconds and sending
+// digests once a day
+func NewWorker(db *gorm.DB, mailer Mailer) *Worker {
+	return &Worker{
+		DB:             db,
+		Mailer:         mailer,
+		Interval:       30 * time.Second,
+		DigestInterval: DefaultDigestInterval,
+	}
+}
+
+// Run works until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce queues the digests that are due and delivers the outbox once
+func (w *Worker) RunOnce(ctx context.Context) {
+	if err := QueueDigests(w.DB, w.DigestInterval); err != nil {
+		log.Printf("Failed to queue digests: %v", err)
+	}
+	if _, err := Deliver(ctx, w.DB, w.Mailer); err != nil && ctx.Err() == nil {
+		log.Printf("Failed to deliver emails: %v", err)
+	}
+}
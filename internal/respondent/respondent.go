@@ -0,0 +1,80 @@
+// Package respondent tells respondents' browsers apart by a cookie holding a
+// random key, so that forms can limit how often one person answers them and
+// rate limits can tell people sharing an IP address apart. The cookie is
+// signed, so only keys the server gave out are recognized.
+package respondent
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CookieName names the cookie holding the respondent's key
+const CookieName = "respondent"
+
+// Cookies gives out and recognizes respondent cookies
+type Cookies struct {
+	Secret []byte // signs the cookies
+}
+
+// New returns cookies signed with secret, or with a random secret if it is
+// empty, in which case cookies given out before a restart are not recognized
+func New(secret []byte) *Cookies {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Sprintf("respondent: failed to generate secret: %v", err))
+		}
+	}
+	return &Cookies{Secret: secret}
+}
+
+// Key returns the key of the respondent who sent r, if their browser has a
+// cookie the server gave out
+func (c *Cookies) Key(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(CookieName)
+	if err != nil {
+		return "", false
+	}
+	key, signature, found := strings.Cut(cookie.Value, ".")
+	if !found || key == "" || !hmac.Equal([]byte(signature), []byte(c.sign(key))) {
+		return "", false
+	}
+	return key, true
+}
+
+// Identify returns the key of the respondent who sent r, giving their
+// browser a new one if it has none the server gave out
+func (c *Cookies) Identify(w http.ResponseWriter, r *http.Request) (string, error) {
+	if key, ok := c.Key(r); ok {
+		return key, nil
+	}
+
+	// The key identifies the respondent's drafts, so it must not be guessable
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate respondent key: %w", err)
+	}
+	key := hex.EncodeToString(bytes)
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    key + "." + c.sign(key),
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return key, nil
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of key
+func (c *Cookies) sign(key string) string {
+	mac := hmac.New(sha256.New, c.Secret)
+	mac.Write([]byte(key))
+	return hex.EncodeToString(mac.Sum(nil))
+}
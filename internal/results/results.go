@@ -0,0 +1,412 @@
+// Package results aggregates the responses a form has received. All counting
+// and statistics are done by the database; Go code only shapes the results.
+package results
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+const (
+	// HistogramBuckets is the number of buckets used for number field histograms
+	HistogramBuckets = 10
+	// RecentAnswersLimit is the number of text answers listed per field
+	RecentAnswersLimit = 10
+)
+
+// numericPattern matches responses that can be cast to a number
+const numericPattern = `^\s*-?[0-9]+(\.[0-9]+)?\s*$`
+
+// sqliteNumeric is the condition numericPattern is checked with on SQLite,
+// which has no regular expressions: without its minus sign, the trimmed
+// response is digits with at most one dot, neither first nor last
+var sqliteNumeric = func() string {
+	digits := "(CASE WHEN TRIM(r.response) LIKE '-%' THEN substr(TRIM(r.response), 2) ELSE TRIM(r.response) END)"
+	return digits + " <> '' AND " +
+		digits + " NOT GLOB '*[^0-9.]*' AND " +
+		digits + " NOT GLOB '*.*.*' AND " +
+		digits + " NOT GLOB '.*' AND " +
+		digits + " NOT GLOB '*.'"
+}()
+
+// isPostgres reports whether db is a PostgreSQL database. The statistics
+// PostgreSQL computes with functions of its own are computed with portable
+// SQL on SQLite.
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
+
+// FormResults holds the aggregated results of a form
+type FormResults struct {
+	FormID               uint          `json:"form_id"`
+	TotalSubmissions     int64         `json:"total_submissions"`
+	CompletedSubmissions int64         `json:"completed_submissions"`
+	CompletionRate       float64       `json:"completion_rate"`
+	Steps                []StepResult  `json:"steps,omitempty"`
+	Fields               []FieldResult `json:"fields"`
+}
+
+// StepResult describes how many respondents reached and left a step of a multi-step form
+type StepResult struct {
+	Step       int     `json:"step"`
+	Reached    int64   `json:"reached"`
+	DroppedOff int64   `json:"dropped_off"`
+	DropOff    float64 `json:"drop_off_rate"`
+}
+
+// FieldResult holds the aggregated answers to a single field
+type FieldResult struct {
+	FieldID       uint          `json:"field_id"`
+	Label         string        `json:"label"`
+	FieldType     string        `json:"field_type"`
+	Step          int           `json:"step"`
+	ResponseCount int64         `json:"response_count"`
+	Options       []OptionCount `json:"options,omitempty"`
+	Number        *NumberStats  `json:"number,omitempty"`
+	RecentAnswers []TextAnswer  `json:"recent_answers,omitempty"`
+}
+
+// OptionCount is the number of times an option was chosen
+type OptionCount struct {
+	Value   string  `json:"value"`
+	Label   string  `json:"label"`
+	Count   int64   `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// NumberStats summarises the answers to a number field
+type NumberStats struct {
+	Count     int64             `json:"count"`
+	Mean      float64           `json:"mean"`
+	Median    float64           `json:"median"`
+	Min       float64           `json:"min"`
+	Max       float64           `json:"max"`
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// HistogramBucket counts the number answers in the range [From, To)
+type HistogramBucket struct {
+	From    float64 `json:"from"`
+	To      float64 `json:"to"`
+	Count   int64   `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// TextAnswer is a single free-text answer
+type TextAnswer struct {
+	Response    string    `json:"response"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// ForForm aggregates the responses to form. fields must be the form's fields in display order.
+func ForForm(db *gorm.DB, form database.Form, fields []database.FormField) (*FormResults, error) {
+	res := &FormResults{FormID: form.ID, Fields: []FieldResult{}}
+
+	// Count submissions by status, leaving out those awaiting moderation
+	err := db.Model(&database.Submission{}).
+		Where("form_id = ? AND status <> ?", form.ID, "flagged").
+		Select("COUNT(*), COUNT(CASE WHEN status = 'completed' THEN 1 END)").
+		Row().
+		Scan(&res.TotalSubmissions, &res.CompletedSubmissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count submissions: %w", err)
+	}
+	if res.TotalSubmissions > 0 {
+		res.CompletionRate = percent(res.CompletedSubmissions, res.TotalSubmissions)
+	}
+
+	if form.IsMultiStep {
+		res.Steps, err = stepResults(db, form, fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range fields {
+		fieldRes, err := fieldResult(db, field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate field %d: %w", field.ID, err)
+		}
+		res.Fields = append(res.Fields, fieldRes)
+	}
+
+	return res, nil
+}
+
+// completedResponses selects the non-empty answers to a field from completed submissions
+func completedResponses(db *gorm.DB, fieldID uint) *gorm.DB {
+	return db.Table("submission_responses AS r").
+		Joins("JOIN submissions AS s ON s.id = r.submission_id AND s.deleted_at IS NULL").
+		Where("r.field_id = ? AND r.deleted_at IS NULL AND s.status = ? AND r.response <> ''", fieldID, "completed")
+}
+
+// stepResults counts how many submissions reached each step and how many stopped there
+func stepResults(db *gorm.DB, form database.Form, fields []database.FormField) ([]StepResult, error) {
+	totalSteps := 1
+	for _, field := range fields {
+		if field.Step > totalSteps {
+			totalSteps = field.Step
+		}
+	}
+
+	// Count submissions by the step they are on, treating completed ones as past the last step
+	rows, err := db.Model(&database.Submission{}).
+		Where("form_id = ? AND status <> ?", form.ID, "flagged").
+		Select("CASE WHEN status = 'completed' THEN ? ELSE current_step END AS step, COUNT(*)", totalSteps+1).
+		Group("step").
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count submissions by step: %w", err)
+	}
+	defer rows.Close()
+
+	atStep := make(map[int]int64)
+	for rows.Next() {
+		var step int
+		var count int64
+		if err := rows.Scan(&step, &count); err != nil {
+			return nil, err
+		}
+		if step > totalSteps+1 {
+			step = totalSteps + 1
+		}
+		atStep[step] += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A submission has reached every step up to the one it is on
+	steps := make([]StepResult, totalSteps)
+	var reached int64
+	for step := totalSteps + 1; step >= 1; step-- {
+		reached += atStep[step]
+		if step <= totalSteps {
+			steps[step-1] = StepResult{
+				Step:       step,
+				Reached:    reached,
+				DroppedOff: atStep[step],
+				DropOff:    percent(atStep[step], reached),
+			}
+		}
+	}
+	return steps, nil
+}
+
+// fieldResult aggregates the answers to one field according to its type
+func fieldResult(db *gorm.DB, field database.FormField) (FieldResult, error) {
+	res := FieldResult{
+		FieldID:   field.ID,
+		Label:     field.Label,
+		FieldType: field.FieldType,
+		Step:      field.Step,
+	}
+
+	err := completedResponses(db, field.ID).
+		Select("COUNT(DISTINCT r.submission_id)").
+		Row().
+		Scan(&res.ResponseCount)
+	if err != nil {
+		return res, err
+	}
+
+	switch field.FieldType {
+	case "select", "radio", "checkbox":
+		res.Options, err = optionCounts(db, field, res.ResponseCount)
+	case "number":
+		res.Number, err = numberStats(db, field)
+	case "text", "textarea":
+		res.RecentAnswers, err = recentAnswers(db, field)
+	}
+	return res, err
+}
+
+// optionCounts counts how often each option of a choice field was chosen.
+// Percentages are of the submissions that answered the field, so for
+// checkboxes they can add up to more than 100.
+func optionCounts(db *gorm.DB, field database.FormField, respondents int64) ([]OptionCount, error) {
+	values := completedResponses(db, field.ID).Select("r.response AS value")
+	switch {
+	case !database.IsMultiValueField(field.FieldType):
+	case isPostgres(db):
+		// Multi-value answers are JSON arrays holding every selected option
+		values = completedResponses(db, field.ID).
+			Select("jsonb_array_elements_text(CAST(r.response AS jsonb)) AS value").
+			Where("r.response LIKE ?", "[%")
+	default:
+		// json_each fails on anything but JSON, so older answers that are
+		// not arrays are replaced by an empty one
+		values = completedResponses(db, field.ID).
+			Joins("JOIN json_each(CASE WHEN r.response LIKE '[%' AND json_valid(r.response) THEN r.response ELSE '[]' END) AS j").
+			Select("j.value AS value")
+	}
+
+	rows, err := db.Table("(?) AS v", values).
+		Select("value, COUNT(*) AS count").
+		Group("value").
+		Order("count DESC, value").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counted := make(map[string]int64)
+	var order []string
+	for rows.Next() {
+		var value string
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, err
+		}
+		counted[value] = count
+		order = append(order, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// List the configured options first, including ones nobody chose,
+	// followed by any other values found in stored answers
+	options := []OptionCount{}
+	seen := make(map[string]bool)
+	for _, option := range field.Options {
+		seen[option.Value] = true
+		options = append(options, OptionCount{
+			Value:   option.Value,
+			Label:   option.Label,
+			Count:   counted[option.Value],
+			Percent: percent(counted[option.Value], respondents),
+		})
+	}
+	for _, value := range order {
+		if seen[value] {
+			continue
+		}
+		options = append(options, OptionCount{
+			Value:   value,
+			Label:   value,
+			Count:   counted[value],
+			Percent: percent(counted[value], respondents),
+		})
+	}
+	return options, nil
+}
+
+// numberStats computes the mean, median and a histogram of a number field's answers
+func numberStats(db *gorm.DB, field database.FormField) (*NumberStats, error) {
+	values := completedResponses(db, field.ID).
+		Select("CAST(TRIM(r.response) AS double precision) AS v").
+		Where("r.response ~ ?", numericPattern)
+	if !isPostgres(db) {
+		values = completedResponses(db, field.ID).
+			Select("CAST(TRIM(r.response) AS REAL) AS v").
+			Where(sqliteNumeric)
+	}
+
+	stats := &NumberStats{Histogram: []HistogramBucket{}}
+	var mean, min, max sql.NullFloat64
+	err := db.Table("(?) AS t", values).
+		Select("COUNT(*), AVG(v), MIN(v), MAX(v)").
+		Row().
+		Scan(&stats.Count, &mean, &min, &max)
+	if err != nil {
+		return nil, err
+	}
+	if stats.Count == 0 {
+		return stats, nil
+	}
+	stats.Mean, stats.Min, stats.Max = mean.Float64, min.Float64, max.Float64
+
+	stats.Median, err = median(db, values, stats.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	// All answers are the same, so there is only one bucket
+	if stats.Min == stats.Max {
+		stats.Histogram = append(stats.Histogram, HistogramBucket{
+			From:    stats.Min,
+			To:      stats.Max,
+			Count:   stats.Count,
+			Percent: 100,
+		})
+		return stats, nil
+	}
+
+	// width_bucket puts the maximum value in an overflow bucket, so fold it
+	// into the last one. SQLite has no width_bucket, but casting a positive
+	// number to an integer rounds it down.
+	buckets := db.Table("(?) AS t", values).
+		Select("LEAST(width_bucket(v, ?, ?, ?), ?) AS bucket, COUNT(*)", stats.Min, stats.Max, HistogramBuckets, HistogramBuckets)
+	if !isPostgres(db) {
+		buckets = db.Table("(?) AS t", values).
+			Select("MIN(CAST((v - ?) * ? / ? AS INTEGER) + 1, ?) AS bucket, COUNT(*)", stats.Min, HistogramBuckets, stats.Max-stats.Min, HistogramBuckets)
+	}
+	rows, err := buckets.Group("bucket").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int64)
+	for rows.Next() {
+		var bucket int
+		var count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		counts[bucket] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	width := (stats.Max - stats.Min) / HistogramBuckets
+	for i := 1; i <= HistogramBuckets; i++ {
+		stats.Histogram = append(stats.Histogram, HistogramBucket{
+			From:    stats.Min + float64(i-1)*width,
+			To:      stats.Min + float64(i)*width,
+			Count:   counts[i],
+			Percent: percent(counts[i], stats.Count),
+		})
+	}
+	return stats, nil
+}
+
+// median returns the median of the count values selected as v, the middle
+// one or the mean of the middle two
+func median(db *gorm.DB, values *gorm.DB, count int64) (float64, error) {
+	middle := db.Table("(?) AS t", values).
+		Select("v").
+		Order("v").
+		Offset(int((count - 1) / 2)).
+		Limit(int(2 - count%2))
+
+	var m sql.NullFloat64
+	err := db.Table("(?) AS m", middle).Select("AVG(v)").Row().Scan(&m)
+	return m.Float64, err
+}
+
+// recentAnswers returns the latest answers to a free-text field
+func recentAnswers(db *gorm.DB, field database.FormField) ([]TextAnswer, error) {
+	answers := []TextAnswer{}
+	err := completedResponses(db, field.ID).
+		Select("r.response AS response, s.completed_at AS submitted_at").
+		Order("s.completed_at DESC, r.id DESC").
+		Limit(RecentAnswersLimit).
+		Scan(&answers).Error
+	return answers, err
+}
+
+// percent returns part as a percentage of total, rounded to one decimal place
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(int64(float64(part)*1000/float64(total)+0.5)) / 10
+}
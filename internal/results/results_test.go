@@ -0,0 +1,271 @@
+package results
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+// fixture is a form in a new SQLite database with every migration applied
+type fixture struct {
+	t      *testing.T
+	db     *gorm.DB
+	form   database.Form
+	fields int
+	subs   int
+}
+
+func newFixture(t *testing.T, multiStep bool) *fixture {
+	t.Helper()
+	db, err := database.Open(database.Config{Driver: "sqlite", Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := database.MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fixture{t: t, db: db}
+	event := database.Event{Name: "Conference", Date: time.Now()}
+	f.must(db.Create(&event).Error)
+	f.form = database.Form{EventID: event.ID, Slug: "feedback", Title: "Feedback", IsMultiStep: multiStep}
+	f.must(db.Create(&f.form).Error)
+	return f
+}
+
+func (f *fixture) must(err error) {
+	f.t.Helper()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// field adds a field to the form
+func (f *fixture) field(fieldType string, step int, options ...string) database.FormField {
+	f.t.Helper()
+	f.fields++
+	field := database.FormField{
+		FormID:     f.form.ID,
+		Ref:        fmt.Sprintf("field_%d", f.fields),
+		Step:       step,
+		FieldType:  fieldType,
+		Label:      fieldType,
+		Options:    database.FieldOptions{},
+		FieldOrder: f.fields,
+	}
+	for _, option := range options {
+		field.Options = append(field.Options, database.FieldOption{Value: option, Label: option})
+	}
+	f.must(f.db.Create(&field).Error)
+	return field
+}
+
+// submit adds a submission with the given status and answers, keyed by field ID
+func (f *fixture) submit(status string, step int, answers map[uint]string) {
+	f.t.Helper()
+	f.subs++
+	submission := database.Submission{
+		FormID:        f.form.ID,
+		SubmissionKey: fmt.Sprintf("key-%d", f.subs),
+		Status:        status,
+		CurrentStep:   step,
+	}
+	if status == "completed" {
+		submission.CompletedAt = sql.NullTime{Time: time.Now().Add(time.Duration(f.subs) * time.Second), Valid: true}
+	}
+	f.must(f.db.Create(&submission).Error)
+	for fieldID, answer := range answers {
+		f.must(f.db.Create(&database.SubmissionResponse{SubmissionID: submission.ID, FieldID: fieldID, Response: answer}).Error)
+	}
+}
+
+func TestForFormOnSQLite(t *testing.T) {
+	f := newFixture(t, true)
+	text := f.field("text", 1)
+	radio := f.field("radio", 1, "yes", "no")
+	number := f.field("number", 2)
+	fields := []database.FormField{text, radio, number}
+
+	f.submit("completed", 2, map[uint]string{text.ID: "Great", radio.ID: "yes", number.ID: "4"})
+	f.submit("completed", 2, map[uint]string{text.ID: "Fine", radio.ID: "no", number.ID: "2"})
+	f.submit("in_progress", 2, map[uint]string{text.ID: "Unfinished", radio.ID: "yes"})
+	f.submit("in_progress", 1, nil)
+	f.submit("flagged", 2, map[uint]string{text.ID: "Spam", radio.ID: "yes", number.ID: "100"})
+
+	res, err := ForForm(f.db, f.form, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.TotalSubmissions != 4 || res.CompletedSubmissions != 2 || res.CompletionRate != 50 {
+		t.Errorf("got %d submissions, %d completed, rate %v; want 4, 2, 50", res.TotalSubmissions, res.CompletedSubmissions, res.CompletionRate)
+	}
+	wantSteps := []StepResult{
+		{Step: 1, Reached: 4, DroppedOff: 1, DropOff: 25},
+		{Step: 2, Reached: 3, DroppedOff: 1, DropOff: 33.3},
+	}
+	if fmt.Sprint(res.Steps) != fmt.Sprint(wantSteps) {
+		t.Errorf("got steps %+v, want %+v", res.Steps, wantSteps)
+	}
+
+	if len(res.Fields) != 3 {
+		t.Fatalf("got %d fields, want 3", len(res.Fields))
+	}
+	if answers := res.Fields[0].RecentAnswers; len(answers) != 2 || answers[0].Response != "Fine" || answers[1].Response != "Great" {
+		t.Errorf("got text answers %+v, want Fine then Great", answers)
+	}
+	wantOptions := []OptionCount{{Value: "yes", Label: "yes", Count: 1, Percent: 50}, {Value: "no", Label: "no", Count: 1, Percent: 50}}
+	if fmt.Sprint(res.Fields[1].Options) != fmt.Sprint(wantOptions) {
+		t.Errorf("got options %+v, want %+v", res.Fields[1].Options, wantOptions)
+	}
+	if stats := res.Fields[2].Number; stats == nil || stats.Count != 2 || stats.Mean != 3 || stats.Median != 3 {
+		t.Errorf("got number stats %+v, want 2 answers with mean and median 3", stats)
+	}
+}
+
+func TestNumberStatsOnSQLite(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []string
+		count   int64
+		mean    float64
+		median  float64
+		min     float64
+		max     float64
+		buckets []int64 // answers counted in each histogram bucket
+	}{
+		{
+			name:    "odd count",
+			answers: []string{"1", "3", "2"},
+			count:   3, mean: 2, median: 2, min: 1, max: 3,
+			buckets: []int64{1, 0, 0, 0, 0, 1, 0, 0, 0, 1},
+		},
+		{
+			name:    "even count",
+			answers: []string{"10", "1", "3", "2"},
+			count:   4, mean: 4, median: 2.5, min: 1, max: 10,
+			buckets: []int64{1, 1, 1, 0, 0, 0, 0, 0, 0, 1},
+		},
+		{
+			name:    "decimals",
+			answers: []string{"0.5", "1.5"},
+			count:   2, mean: 1, median: 1, min: 0.5, max: 1.5,
+			buckets: []int64{1, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		},
+		{
+			name:    "non-numeric answers",
+			answers: []string{"4", "abc", "1.2.3", "-", "5.", ".5", "1e3", "12a", " 6 ", "-1"},
+			count:   3, mean: 3, median: 4, min: -1, max: 6,
+			buckets: []int64{1, 0, 0, 0, 0, 0, 0, 1, 0, 1},
+		},
+		{
+			name:    "same answers",
+			answers: []string{"7", "7"},
+			count:   2, mean: 7, median: 7, min: 7, max: 7,
+			buckets: []int64{2},
+		},
+		{
+			name:    "no numbers",
+			answers: []string{"none", "n/a"},
+			buckets: []int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, false)
+			field := f.field("number", 1)
+			for _, answer := range tt.answers {
+				f.submit("completed", 1, map[uint]string{field.ID: answer})
+			}
+
+			stats, err := numberStats(f.db, field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Count != tt.count || stats.Mean != tt.mean || stats.Median != tt.median || stats.Min != tt.min || stats.Max != tt.max {
+				t.Errorf("got count %d, mean %v, median %v, min %v, max %v; want %d, %v, %v, %v, %v",
+					stats.Count, stats.Mean, stats.Median, stats.Min, stats.Max, tt.count, tt.mean, tt.median, tt.min, tt.max)
+			}
+			buckets := []int64{}
+			for _, bucket := range stats.Histogram {
+				buckets = append(buckets, bucket.Count)
+			}
+			if fmt.Sprint(buckets) != fmt.Sprint(tt.buckets) {
+				t.Errorf("got histogram %v, want %v", buckets, tt.buckets)
+			}
+		})
+	}
+}
+
+func TestOptionCountsOnSQLite(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		answers   []string
+		want      []OptionCount
+	}{
+		{
+			name:      "single value",
+			fieldType: "radio",
+			answers:   []string{"yes", "no", "yes", "other"},
+			want: []OptionCount{
+				{Value: "yes", Label: "yes", Count: 2, Percent: 50},
+				{Value: "no", Label: "no", Count: 1, Percent: 25},
+				{Value: "maybe", Label: "maybe", Count: 0, Percent: 0},
+				{Value: "other", Label: "other", Count: 1, Percent: 25},
+			},
+		},
+		{
+			name:      "multi-value",
+			fieldType: "checkbox",
+			answers:   []string{`["yes","no"]`, `["yes"]`, `["other"]`},
+			want: []OptionCount{
+				{Value: "yes", Label: "yes", Count: 2, Percent: 66.7},
+				{Value: "no", Label: "no", Count: 1, Percent: 33.3},
+				{Value: "maybe", Label: "maybe", Count: 0, Percent: 0},
+				{Value: "other", Label: "other", Count: 1, Percent: 33.3},
+			},
+		},
+		{
+			name:      "multi-value answers that are not arrays",
+			fieldType: "checkbox",
+			answers:   []string{`["maybe"]`, "yes", "[broken"},
+			want: []OptionCount{
+				{Value: "yes", Label: "yes", Count: 0, Percent: 0},
+				{Value: "no", Label: "no", Count: 0, Percent: 0},
+				{Value: "maybe", Label: "maybe", Count: 1, Percent: 33.3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, false)
+			field := f.field(tt.fieldType, 1, "yes", "no", "maybe")
+			for _, answer := range tt.answers {
+				f.submit("completed", 1, map[uint]string{field.ID: answer})
+			}
+
+			res, err := fieldResult(f.db, field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.ResponseCount != int64(len(tt.answers)) {
+				t.Errorf("got %d responses, want %d", res.ResponseCount, len(tt.answers))
+			}
+			if fmt.Sprint(res.Options) != fmt.Sprint(tt.want) {
+				t.Errorf("got options %+v, want %+v", res.Options, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Package schedule opens and closes forms at the times they are scheduled
+// for. When a form opens it is published; when it closes it is unpublished.
+// Either way the event owners are emailed and webhooks are triggered.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/notify"
+	"github.com/yourusername/event-feedback/internal/webhooks"
+	"gorm.io/gorm"
+)
+
+// Apply acts on every form whose schedule state changed since it was last
+// applied and returns how many forms opened or closed. Only the change is
+// acted on, so a form that is published or unpublished by hand stays that
+// way until its next open or close time.
+func Apply(db *gorm.DB, now time.Time) (int, error) {
+	var forms []database.Form
+	err := db.Where("opens_at IS NOT NULL OR closes_at IS NOT NULL OR schedule_state <> ?", database.ScheduleNone).
+		Find(&forms).Error
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, form := range forms {
+		state := form.ScheduleAt(now)
+		if state == form.ScheduleState {
+			continue
+		}
+
+		acted := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Record the new state first, so that a change is only acted on
+			// by whoever wins if several schedulers run at once
+			result := tx.Model(&database.Form{}).
+				Where("id = ? AND schedule_state = ?", form.ID, form.ScheduleState).
+				Update("schedule_state", state)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			form.ScheduleState = state
+
+			switch state {
+			case database.ScheduleOpen:
+				if _, _, err := database.PublishForm(tx, &form); err != nil {
+					return err
+				}
+			case database.ScheduleClosed:
+				form.IsPublished = false
+				if err := tx.Model(&form).Update("is_published", false).Error; err != nil {
+					return err
+				}
+			default:
+				return nil
+			}
+
+			acted = true
+			if err := webhooks.FormPublished(tx, form); err != nil {
+				return err
+			}
+			return notify.ScheduleChanged(tx, form, state)
+		})
+		if err != nil {
+			return changed, fmt.Errorf("schedule of form %d: %w", form.ID, err)
+		}
+		if acted {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// Worker periodically applies form schedules
+type Worker struct {
+	DB       *gorm.DB
+	Interval time.Duration
+}
+
+// NewWorker returns a worker checking the schedules every minute
+func NewWorker(db *gorm.DB) *Worker {
+	return &Worker{DB: db, Interval: time.Minute}
+}
+
+// Run works until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := Apply(w.DB, time.Now()); err != nil {
+			log.Printf("Failed to apply form schedules: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package spam tells likely spam apart from genuine form responses. Each
+// form page carries a signed stamp of when it was shown and a honeypot field
+// people never see; a step sent back too quickly, without its stamp or with
+// the honeypot filled in is suspicious. Suspicious submissions are not
+// rejected but flagged for moderation. A Verifier can additionally make
+// respondents solve a CAPTCHA or proof-of-work puzzle before they finish.
+package spam
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the form inputs the checks use
+const (
+	HoneypotField = "website"
+	StampField    = "form_stamp"
+)
+
+// DefaultMinFillTime is how long a step takes at least unless configured otherwise
+const DefaultMinFillTime = 3 * time.Second
+
+// Guard checks submitted form steps for spam
+type Guard struct {
+	Secret      []byte        // signs the stamps and proof-of-work puzzles
+	MinFillTime time.Duration // steps sent back faster than this are suspicious
+	Verifier    Verifier      // nil if respondents need not verify they are human
+}
+
+// NewGuard returns a guard without a verifier, signing with secret or with
+// a random secret if it is empty
+func NewGuard(secret []byte) *Guard {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Sprintf("spam: failed to generate secret: %v", err))
+		}
+	}
+	return &Guard{Secret: secret, MinFillTime: DefaultMinFillTime}
+}
+
+// Config configures the spam checks and the verifier
+type Config struct {
+	// Secret signs the stamps and puzzles, and respondent cookies; without
+	// it, those given out before a restart are not recognized
+	Secret      string        `yaml:"secret"`
+	MinFillTime time.Duration `yaml:"min_fill_time"` // steps sent back faster than this are suspicious
+
+	// Verifier respondents must pass before they finish a form: none, pow,
+	// hcaptcha, recaptcha or turnstile
+	CaptchaProvider string `yaml:"captcha_provider"`
+	CaptchaSiteKey  string `yaml:"captcha_site_key"`
+	CaptchaSecret   string `yaml:"captcha_secret"`
+	PowDifficulty   int    `yaml:"pow_difficulty"` // leading zero bits proof-of-work hashes need
+}
+
+// GuardFromConfig returns a guard configured by cfg
+func GuardFromConfig(cfg Config) (*Guard, error) {
+	if cfg.MinFillTime < 0 {
+		return nil, fmt.Errorf("minimum fill time cannot be negative, got %s", cfg.MinFillTime)
+	}
+	guard := NewGuard([]byte(cfg.Secret))
+	guard.MinFillTime = cfg.MinFillTime
+
+	verifier, err := newVerifier(cfg, guard.Secret)
+	if err != nil {
+		return nil, err
+	}
+	guard.Verifier = verifier
+	return guard, nil
+}
+
+// Stamp returns a signed record of a form page being shown at now
+func (g *Guard) Stamp(now time.Time) string {
+	value := strconv.FormatInt(now.Unix(), 10)
+	return value + "." + g.sign(value)
+}
+
+// Check returns why a submitted step looks like spam, or nothing if it does not
+func (g *Guard) Check(r *http.Request, now time.Time) []string {
+	var reasons []string
+	if r.PostFormValue(HoneypotField) != "" {
+		reasons = append(reasons, "hidden field filled in")
+	}
+
+	shown, ok := g.parseStamp(r.PostFormValue(StampField))
+	switch {
+	case !ok:
+		reasons = append(reasons, "missing or invalid page stamp")
+	case now.Sub(shown) < g.MinFillTime:
+		reasons = append(reasons, fmt.Sprintf("step sent back after %s", now.Sub(shown).Round(time.Second)))
+	}
+	return reasons
+}
+
+// parseStamp returns the time a stamp records, if its signature is valid
+func (g *Guard) parseStamp(stamp string) (time.Time, bool) {
+	value, signature, found := strings.Cut(stamp, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(g.sign(value))) {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0), true
+}
+
+// sign returns the hex-encoded HMAC of value
+func (g *Guard) sign(value string) string {
+	return sign(g.Secret, value)
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of value under secret
+func sign(secret []byte, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,232 @@
+package spam
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotVerified is returned by verifiers when a respondent did not prove
+// they are human
+var ErrNotVerified = errors.New("the verification was not completed")
+
+// Challenge is what the last step of a form shows respondents to verify
+// they are human
+type Challenge struct {
+	Provider    string // pow, hcaptcha, recaptcha or turnstile
+	SiteKey     string // public key of the CAPTCHA widget
+	ScriptURL   string // script rendering the CAPTCHA widget
+	WidgetClass string // class of the element the widget is rendered into
+	Puzzle      string // proof-of-work puzzle to solve
+	Difficulty  int    // leading zero bits the proof-of-work hash needs
+}
+
+// Verifier makes respondents prove they are human before they finish a form
+type Verifier interface {
+	// Challenge returns the challenge to show at now
+	Challenge(now time.Time) Challenge
+	// Verify checks the answer to a challenge sent with r
+	Verify(ctx context.Context, r *http.Request) error
+}
+
+// newVerifier returns the verifier cfg selects, or nil for none
+func newVerifier(cfg Config, secret []byte) (Verifier, error) {
+	switch cfg.CaptchaProvider {
+	case "", "none":
+		return nil, nil
+	case "pow":
+		pow := NewProofOfWork(secret)
+		if cfg.PowDifficulty < 1 || cfg.PowDifficulty > 32 {
+			return nil, fmt.Errorf("proof-of-work difficulty must be between 1 and 32, got %d", cfg.PowDifficulty)
+		}
+		pow.Difficulty = cfg.PowDifficulty
+		return pow, nil
+	default:
+		captcha, err := NewCaptcha(cfg.CaptchaProvider, cfg.CaptchaSiteKey, cfg.CaptchaSecret)
+		if err != nil {
+			return nil, err
+		}
+		return captcha, nil
+	}
+}
+
+// Captcha verifies a CAPTCHA widget's response with its provider. hCaptcha,
+// reCAPTCHA and Turnstile share the same verification protocol.
+type Captcha struct {
+	Provider      string
+	SiteKey       string
+	Secret        string
+	ScriptURL     string
+	WidgetClass   string
+	ResponseField string // form input the widget puts its response in
+	VerifyURL     string
+	Client        *http.Client
+}
+
+// NewCaptcha returns a verifier for the hcaptcha, recaptcha or turnstile provider
+func NewCaptcha(provider, siteKey, secret string) (*Captcha, error) {
+	if siteKey == "" || secret == "" {
+		return nil, fmt.Errorf("a CAPTCHA site key and secret are required for %s", provider)
+	}
+	captcha := &Captcha{
+		Provider: provider,
+		SiteKey:  siteKey,
+		Secret:   secret,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	switch provider {
+	case "hcaptcha":
+		captcha.ScriptURL = "https://js.hcaptcha.com/1/api.js"
+		captcha.WidgetClass = "h-captcha"
+		captcha.ResponseField = "h-captcha-response"
+		captcha.VerifyURL = "https://api.hcaptcha.com/siteverify"
+	case "recaptcha":
+		captcha.ScriptURL = "https://www.google.com/recaptcha/api.js"
+		captcha.WidgetClass = "g-recaptcha"
+		captcha.ResponseField = "g-recaptcha-response"
+		captcha.VerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+	case "turnstile":
+		captcha.ScriptURL = "https://challenges.cloudflare.com/turnstile/v0/api.js"
+		captcha.WidgetClass = "cf-turnstile"
+		captcha.ResponseField = "cf-turnstile-response"
+		captcha.VerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	default:
+		return nil, fmt.Errorf("unknown CAPTCHA provider %q: must be none, pow, hcaptcha, recaptcha or turnstile", provider)
+	}
+	return captcha, nil
+}
+
+// Challenge implements Verifier
+func (c *Captcha) Challenge(now time.Time) Challenge {
+	return Challenge{
+		Provider:    c.Provider,
+		SiteKey:     c.SiteKey,
+		ScriptURL:   c.ScriptURL,
+		WidgetClass: c.WidgetClass,
+	}
+}
+
+// Verify implements Verifier
+func (c *Captcha) Verify(ctx context.Context, r *http.Request) error {
+	response := r.PostFormValue(c.ResponseField)
+	if response == "" {
+		return ErrNotVerified
+	}
+
+	form := url.Values{"secret": {c.Secret}, "response": {response}}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		form.Set("remoteip", host)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s verification failed: %w", c.Provider, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%s verification failed: %w", c.Provider, err)
+	}
+	if !result.Success {
+		return ErrNotVerified
+	}
+	return nil
+}
+
+// Names of the form inputs carrying a proof of work
+const (
+	PuzzleField = "pow_puzzle"
+	NonceField  = "pow_nonce"
+)
+
+// ProofOfWork makes the respondent's browser find a nonce whose SHA-256
+// hash, together with a signed puzzle, starts with Difficulty zero bits.
+// This costs a browser a moment but makes sending many submissions costly.
+// Browsers only solve puzzles on pages served over HTTPS or from localhost.
+type ProofOfWork struct {
+	Secret     []byte
+	Difficulty int
+	MaxAge     time.Duration // how long a puzzle can be solved
+}
+
+// DefaultPowDifficulty is how many leading zero bits proof-of-work hashes need
+// unless configured otherwise
+const DefaultPowDifficulty = 18
+
+// NewProofOfWork returns a proof of work needing 18 zero bits, solved within a day
+func NewProofOfWork(secret []byte) *ProofOfWork {
+	return &ProofOfWork{Secret: secret, Difficulty: DefaultPowDifficulty, MaxAge: 24 * time.Hour}
+}
+
+// Challenge implements Verifier
+func (p *ProofOfWork) Challenge(now time.Time) Challenge {
+	salt := make([]byte, 8)
+	rand.Read(salt)
+	value := strconv.FormatInt(now.Unix(), 10) + "." + strconv.Itoa(p.Difficulty) + "." + hex.EncodeToString(salt)
+	return Challenge{
+		Provider:   "pow",
+		Puzzle:     value + "." + sign(p.Secret, value),
+		Difficulty: p.Difficulty,
+	}
+}
+
+// Verify implements Verifier
+func (p *ProofOfWork) Verify(ctx context.Context, r *http.Request) error {
+	puzzle := r.PostFormValue(PuzzleField)
+	nonce := r.PostFormValue(NonceField)
+
+	parts := strings.Split(puzzle, ".")
+	if len(parts) != 4 || nonce == "" {
+		return ErrNotVerified
+	}
+	value := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(sign(p.Secret, value)), []byte(parts[3])) {
+		return ErrNotVerified
+	}
+	issued, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Since(time.Unix(issued, 0)) > p.MaxAge {
+		return ErrNotVerified
+	}
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil || difficulty < p.Difficulty {
+		return ErrNotVerified
+	}
+
+	if leadingZeroBits(sha256.Sum256([]byte(puzzle+":"+nonce))) < difficulty {
+		return ErrNotVerified
+	}
+	return nil
+}
+
+// leadingZeroBits counts the zero bits at the start of a hash
+func leadingZeroBits(hash [sha256.Size]byte) int {
+	n := 0
+	for _, b := range hash {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
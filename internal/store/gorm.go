@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+// Gorm keeps everything in a PostgreSQL or SQLite database through GORM
+type Gorm struct {
+	DB *gorm.DB
+}
+
+// NewGorm returns a store backed by db
+func NewGorm(db *gorm.DB) *Gorm {
+	return &Gorm{DB: db}
+}
+
+// lookupError turns GORM's error for a missing record into ErrNotFound
+func lookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// memberEventIDs returns a subquery on db selecting the IDs of events the user belongs to
+func memberEventIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&database.EventMember{}).Select("event_id").Where("user_id = ?", userID)
+}
+
+// editorEventIDs returns a subquery on db selecting the IDs of events the user can edit
+func editorEventIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return memberEventIDs(db, userID).Where("role IN ?", []string{database.RoleEditor, database.RoleOwner})
+}
+
+// memberFormIDs returns a subquery on db selecting the IDs of forms of events the user belongs to
+func memberFormIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&database.Form{}).Select("id").Where("event_id IN (?)", memberEventIDs(db, userID))
+}
+
+// UserByEmail implements UserStore
+func (s *Gorm) UserByEmail(ctx context.Context, email string) (database.User, error) {
+	var user database.User
+	err := s.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	return user, lookupError(err)
+}
+
+// CreateUser implements UserStore
+func (s *Gorm) CreateUser(ctx context.Context, user *database.User) error {
+	return s.DB.WithContext(ctx).Create(user).Error
+}
+
+// CreateSession implements UserStore
+func (s *Gorm) CreateSession(ctx context.Context, session *database.Session) error {
+	db := s.DB.WithContext(ctx)
+
+	// Remove this user's expired sessions while we're here
+	db.Where("user_id = ? AND expires_at < ?", session.UserID, time.Now()).Delete(&database.Session{})
+
+	return db.Create(session).Error
+}
+
+// SessionUser implements UserStore
+func (s *Gorm) SessionUser(ctx context.Context, id string) (database.User, error) {
+	var session database.Session
+	err := s.DB.WithContext(ctx).Preload("User").
+		Where("id = ? AND expires_at > ?", id, time.Now()).
+		First(&session).Error
+	return session.User, lookupError(err)
+}
+
+// DeleteSession implements UserStore
+func (s *Gorm) DeleteSession(ctx context.Context, id string) error {
+	return s.DB.WithContext(ctx).Where("id = ?", id).Delete(&database.Session{}).Error
+}
+
+// Ready implements HealthStore. The database must answer and have every
+// migration applied.
+func (s *Gorm) Ready(ctx context.Context) error {
+	// Ping the database
+	sqlDB, err := s.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("database unavailable: %w", err)
+	}
+
+	// Check the schema is up to date
+	pending, err := database.PendingMigrations(s.DB.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to check migrations: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("pending migrations: %d", pending)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/formdef"
+	"gorm.io/gorm"
+)
+
+// Event implements EventStore
+func (s *Gorm) Event(ctx context.Context, id uint) (database.Event, error) {
+	var event database.Event
+	err := s.DB.WithContext(ctx).First(&event, id).Error
+	return event, lookupError(err)
+}
+
+// MemberEvents implements EventStore
+func (s *Gorm) MemberEvents(ctx context.Context, userID uint) ([]database.Event, error) {
+	db := s.DB.WithContext(ctx)
+	var events []database.Event
+	err := db.Where("id IN (?)", memberEventIDs(db, userID)).Order("date desc").Find(&events).Error
+	return events, err
+}
+
+// EditorEvents implements EventStore
+func (s *Gorm) EditorEvents(ctx context.Context, userID uint) ([]database.Event, error) {
+	db := s.DB.WithContext(ctx)
+	var events []database.Event
+	err := db.Where("id IN (?)", editorEventIDs(db, userID)).Order("date desc").Find(&events).Error
+	return events, err
+}
+
+// RecentEvents implements EventStore
+func (s *Gorm) RecentEvents(ctx context.Context, userID uint, limit int) ([]database.Event, error) {
+	db := s.DB.WithContext(ctx)
+	var events []database.Event
+	err := db.Where("id IN (?)", memberEventIDs(db, userID)).
+		Order("created_at desc").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// SearchEvents implements EventStore
+func (s *Gorm) SearchEvents(ctx context.Context, filter EventFilter, offset, limit int) ([]database.Event, int64, error) {
+	db := s.DB.WithContext(ctx)
+	query := db.Model(&database.Event{}).Where("id IN (?)", memberEventIDs(db, filter.UserID))
+	if filter.Name != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(filter.Name)+"%")
+	}
+	if filter.DateFrom != nil {
+		query = query.Where("date >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		query = query.Where("date <= ?", *filter.DateTo)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []database.Event
+	err := query.Order("date desc, id").Offset(offset).Limit(limit).Find(&events).Error
+	return events, total, err
+}
+
+// createOwner makes the user the owner of an event within tx
+func createOwner(tx *gorm.DB, eventID, userID uint) error {
+	return tx.Create(&database.EventMember{
+		EventID: eventID,
+		UserID:  userID,
+		Role:    database.RoleOwner,
+	}).Error
+}
+
+// CreateEvent implements EventStore
+func (s *Gorm) CreateEvent(ctx context.Context, event *database.Event, ownerID uint) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return createOwner(tx, event.ID, ownerID)
+	})
+}
+
+// CopyEvent implements EventStore
+func (s *Gorm) CopyEvent(ctx context.Context, sourceID uint, event *database.Event, ownerID uint) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := formdef.CopyEvent(tx, sourceID, event); err != nil {
+			return err
+		}
+		return createOwner(tx, event.ID, ownerID)
+	})
+}
+
+// SaveEvent implements EventStore
+func (s *Gorm) SaveEvent(ctx context.Context, event *database.Event) error {
+	return s.DB.WithContext(ctx).Save(event).Error
+}
+
+// DeleteEvent implements EventStore
+func (s *Gorm) DeleteEvent(ctx context.Context, event database.Event) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		formIDs := tx.Model(&database.Form{}).Select("id").Where("event_id = ?", event.ID)
+		if err := tx.Where("form_id IN (?)", formIDs).Delete(&database.FormField{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", event.ID).Delete(&database.Form{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&event).Error
+	})
+}
+
+// EventRole implements EventStore
+func (s *Gorm) EventRole(ctx context.Context, userID, eventID uint) (string, error) {
+	var member database.EventMember
+	err := s.DB.WithContext(ctx).Where("event_id = ? AND user_id = ?", eventID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// Members implements MemberStore
+func (s *Gorm) Members(ctx context.Context, eventID uint) ([]database.EventMember, error) {
+	var members []database.EventMember
+	err := s.DB.WithContext(ctx).Preload("User").
+		Where("event_id = ?", eventID).
+		Order("id").
+		Find(&members).Error
+	return members, err
+}
+
+// Member implements MemberStore
+func (s *Gorm) Member(ctx context.Context, eventID, id uint) (database.EventMember, error) {
+	var member database.EventMember
+	err := s.DB.WithContext(ctx).Where("id = ? AND event_id = ?", id, eventID).First(&member).Error
+	return member, lookupError(err)
+}
+
+// CreateMember implements MemberStore
+func (s *Gorm) CreateMember(ctx context.Context, member *database.EventMember) error {
+	return s.DB.WithContext(ctx).Create(member).Error
+}
+
+// SaveMember implements MemberStore
+func (s *Gorm) SaveMember(ctx context.Context, member *database.EventMember) error {
+	return s.DB.WithContext(ctx).Save(member).Error
+}
+
+// DeleteMember implements MemberStore
+func (s *Gorm) DeleteMember(ctx context.Context, member database.EventMember) error {
+	return s.DB.WithContext(ctx).Unscoped().Delete(&member).Error
+}
+
+// OwnerCount implements MemberStore
+func (s *Gorm) OwnerCount(ctx context.Context, eventID uint) (int64, error) {
+	var owners int64
+	err := s.DB.WithContext(ctx).Model(&database.EventMember{}).
+		Where("event_id = ? AND role = ?", eventID, database.RoleOwner).
+		Count(&owners).Error
+	return owners, err
+}
@@ -0,0 +1,338 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/formdef"
+	"github.com/yourusername/event-feedback/internal/layout"
+	"github.com/yourusername/event-feedback/internal/webhooks"
+	"gorm.io/gorm"
+)
+
+// Form implements FormStore
+func (s *Gorm) Form(ctx context.Context, id uint) (database.Form, error) {
+	var form database.Form
+	err := s.DB.WithContext(ctx).First(&form, id).Error
+	return form, lookupError(err)
+}
+
+// EventForms implements FormStore
+func (s *Gorm) EventForms(ctx context.Context, eventID uint) ([]database.Form, error) {
+	var forms []database.Form
+	err := s.DB.WithContext(ctx).Where("event_id = ?", eventID).Order("id").Find(&forms).Error
+	return forms, err
+}
+
+// PublishedForms implements FormStore
+func (s *Gorm) PublishedForms(ctx context.Context, limit int) ([]database.Form, error) {
+	var forms []database.Form
+	err := s.DB.WithContext(ctx).
+		Where("is_published = ?", true).
+		Order("created_at desc").
+		Limit(limit).
+		Preload("Event").
+		Find(&forms).Error
+	return forms, err
+}
+
+// SearchForms implements FormStore
+func (s *Gorm) SearchForms(ctx context.Context, filter FormFilter, offset, limit int) ([]database.Form, int64, error) {
+	db := s.DB.WithContext(ctx)
+	query := db.Model(&database.Form{}).Where("event_id IN (?)", memberEventIDs(db, filter.UserID))
+	if filter.EventID != nil {
+		query = query.Where("event_id = ?", *filter.EventID)
+	}
+	if filter.IsPublished != nil {
+		query = query.Where("is_published = ?", *filter.IsPublished)
+	}
+	if filter.Title != "" {
+		query = query.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(filter.Title)+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var forms []database.Form
+	err := query.Order("created_at desc, id").Offset(offset).Limit(limit).Find(&forms).Error
+	return forms, total, err
+}
+
+// CreateForm implements FormStore
+func (s *Gorm) CreateForm(ctx context.Context, form *database.Form) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(form).Error; err != nil {
+			return err
+		}
+		if !form.IsPublished {
+			return nil
+		}
+		if _, _, err := database.PublishForm(tx, form); err != nil {
+			return err
+		}
+		return webhooks.FormPublished(tx, *form)
+	})
+}
+
+// CreateFormFrom implements FormStore
+func (s *Gorm) CreateFormFrom(ctx context.Context, d database.FormDefinition, eventID uint, title string) (database.Form, error) {
+	var form database.Form
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		form, err = formdef.Create(tx, d, eventID, title)
+		return err
+	})
+	return form, err
+}
+
+// ImportForm implements FormStore
+func (s *Gorm) ImportForm(ctx context.Context, d database.FormDefinition, eventID uint, dryRun bool) (formdef.ImportResult, error) {
+	return formdef.Import(s.DB.WithContext(ctx), d, eventID, dryRun)
+}
+
+// SaveForm implements FormStore
+func (s *Gorm) SaveForm(ctx context.Context, form *database.Form, publish bool) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored database.Form
+		if err := tx.Select("id", "is_published").First(&stored, form.ID).Error; err != nil {
+			return lookupError(err)
+		}
+		if err := tx.Save(form).Error; err != nil {
+			return err
+		}
+
+		// Publishing snapshots the draft into a new version if it has changed
+		created := false
+		if publish {
+			var err error
+			if _, created, err = database.PublishForm(tx, form); err != nil {
+				return err
+			}
+		}
+		if form.IsPublished == stored.IsPublished && !created {
+			return nil
+		}
+		return webhooks.FormPublished(tx, *form)
+	})
+}
+
+// DeleteForm implements FormStore
+func (s *Gorm) DeleteForm(ctx context.Context, form database.Form) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("form_id = ?", form.ID).Delete(&database.FormField{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&form).Error
+	})
+}
+
+// Field implements FormStore
+func (s *Gorm) Field(ctx context.Context, id uint) (database.FormField, error) {
+	var field database.FormField
+	err := s.DB.WithContext(ctx).First(&field, id).Error
+	return field, lookupError(err)
+}
+
+// Fields implements FormStore
+func (s *Gorm) Fields(ctx context.Context, formID uint) ([]database.FormField, error) {
+	var fields []database.FormField
+	err := s.DB.WithContext(ctx).Where("form_id = ?", formID).Order("step, field_order").Find(&fields).Error
+	return fields, err
+}
+
+// SearchFields implements FormStore
+func (s *Gorm) SearchFields(ctx context.Context, filter FieldFilter, offset, limit int) ([]database.FormField, int64, error) {
+	db := s.DB.WithContext(ctx)
+	query := db.Model(&database.FormField{}).Where("form_id IN (?)", memberFormIDs(db, filter.UserID))
+	if filter.FormID != nil {
+		query = query.Where("form_id = ?", *filter.FormID)
+	}
+	if filter.Step != nil {
+		query = query.Where("step = ?", *filter.Step)
+	}
+	if filter.FieldType != "" {
+		query = query.Where("field_type = ?", filter.FieldType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var fields []database.FormField
+	err := query.Order("form_id, step, field_order").Offset(offset).Limit(limit).Find(&fields).Error
+	return fields, total, err
+}
+
+// CreateField implements FormStore
+func (s *Gorm) CreateField(ctx context.Context, field *database.FormField, position int) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Append the field to the end of its step
+		var maxOrder int
+		err := tx.Model(&database.FormField{}).
+			Where("form_id = ? AND step = ?", field.FormID, field.Step).
+			Select("COALESCE(MAX(field_order), 0)").
+			Scan(&maxOrder).Error
+		if err != nil {
+			return err
+		}
+		field.FieldOrder = maxOrder + 1
+		if err := tx.Create(field).Error; err != nil {
+			return err
+		}
+		if position < 1 || position >= field.FieldOrder {
+			return nil
+		}
+
+		// Then move it into place
+		if err := rearrangeForm(tx, field.FormID, layout.MoveField(field.ID, field.Step, position)); err != nil {
+			return err
+		}
+		return tx.First(field, field.ID).Error
+	})
+}
+
+// SaveField implements FormStore
+func (s *Gorm) SaveField(ctx context.Context, field *database.FormField, change layout.Change) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored database.FormField
+		if err := tx.Select("id", "step", "field_order").First(&stored, field.ID).Error; err != nil {
+			return lookupError(err)
+		}
+		field.Step, field.FieldOrder = stored.Step, stored.FieldOrder
+		if err := tx.Save(field).Error; err != nil {
+			return err
+		}
+		if change == nil {
+			return nil
+		}
+		if err := rearrangeForm(tx, field.FormID, change); err != nil {
+			return err
+		}
+		return tx.First(field, field.ID).Error
+	})
+}
+
+// DeleteField implements FormStore
+func (s *Gorm) DeleteField(ctx context.Context, field database.FormField) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&field).Error; err != nil {
+			return err
+		}
+		return rearrangeForm(tx, field.FormID, layout.Renumber(field.Step))
+	})
+}
+
+// Rearrange implements FormStore
+func (s *Gorm) Rearrange(ctx context.Context, form *database.Form, change layout.Change) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return rearrange(tx, form, change)
+	})
+}
+
+// rearrangeForm applies change to the layout of the form with the given ID within tx
+func rearrangeForm(tx *gorm.DB, formID uint, change layout.Change) error {
+	var form database.Form
+	if err := tx.First(&form, formID).Error; err != nil {
+		return err
+	}
+	return rearrange(tx, &form, change)
+}
+
+// rearrange applies change to the layout of form within tx, saving the
+// fields and step rules it changes and deleting the fields it drops
+func rearrange(tx *gorm.DB, form *database.Form, change layout.Change) error {
+	var fields []database.FormField
+	if err := tx.Where("form_id = ?", form.ID).Order("step, field_order, id").Find(&fields).Error; err != nil {
+		return err
+	}
+	rules := append(database.StepRules(nil), form.StepRules...)
+
+	changed, err := change(form, append([]database.FormField(nil), fields...))
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[uint]database.FormField, len(changed))
+	for _, field := range changed {
+		kept[field.ID] = field
+	}
+	for _, field := range fields {
+		next, ok := kept[field.ID]
+		switch {
+		case !ok:
+			err = tx.Delete(&field).Error
+		case next.Step != field.Step || next.FieldOrder != field.FieldOrder:
+			err = tx.Model(&field).Updates(map[string]interface{}{"step": next.Step, "field_order": next.FieldOrder}).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if reflect.DeepEqual(rules, form.StepRules) {
+		return nil
+	}
+	return tx.Model(form).Update("step_rules", form.StepRules).Error
+}
+
+// Versions implements FormStore
+func (s *Gorm) Versions(ctx context.Context, formID uint) ([]database.FormVersion, error) {
+	var versions []database.FormVersion
+	err := s.DB.WithContext(ctx).Where("form_id = ?", formID).Order("number desc").Find(&versions).Error
+	return versions, err
+}
+
+// Version implements FormStore
+func (s *Gorm) Version(ctx context.Context, formID, id uint) (database.FormVersion, error) {
+	var version database.FormVersion
+	err := s.DB.WithContext(ctx).Where("form_id = ?", formID).First(&version, id).Error
+	return version, lookupError(err)
+}
+
+// PublishedVersion implements FormStore
+func (s *Gorm) PublishedVersion(ctx context.Context, form database.Form) (*database.FormVersion, error) {
+	return database.PublishedVersion(s.DB.WithContext(ctx), form)
+}
+
+// HasDraftChanges implements FormStore
+func (s *Gorm) HasDraftChanges(ctx context.Context, form database.Form) (bool, error) {
+	return database.HasDraftChanges(s.DB.WithContext(ctx), form)
+}
+
+// FormAsOf implements FormStore
+func (s *Gorm) FormAsOf(ctx context.Context, form database.Form, versionID *uint) (database.Form, []database.FormField, error) {
+	form, fields, err := database.FormAsOf(s.DB.WithContext(ctx), form, versionID)
+	return form, fields, lookupError(err)
+}
+
+// ReportFields implements FormStore
+func (s *Gorm) ReportFields(ctx context.Context, form database.Form) ([]database.FormField, error) {
+	return database.ReportFields(s.DB.WithContext(ctx), form)
+}
+
+// UserTemplates implements TemplateStore
+func (s *Gorm) UserTemplates(ctx context.Context, userID uint) ([]database.FormTemplate, error) {
+	var templates []database.FormTemplate
+	err := s.DB.WithContext(ctx).Where("created_by_id = ?", userID).Order("name, id").Find(&templates).Error
+	return templates, err
+}
+
+// UserTemplate implements TemplateStore
+func (s *Gorm) UserTemplate(ctx context.Context, userID, id uint) (database.FormTemplate, error) {
+	var tmpl database.FormTemplate
+	err := s.DB.WithContext(ctx).Where("created_by_id = ?", userID).First(&tmpl, id).Error
+	return tmpl, lookupError(err)
+}
+
+// CreateTemplate implements TemplateStore
+func (s *Gorm) CreateTemplate(ctx context.Context, tmpl *database.FormTemplate) error {
+	return s.DB.WithContext(ctx).Create(tmpl).Error
+}
+
+// DeleteTemplate implements TemplateStore
+func (s *Gorm) DeleteTemplate(ctx context.Context, tmpl database.FormTemplate) error {
+	return s.DB.WithContext(ctx).Delete(&tmpl).Error
+}
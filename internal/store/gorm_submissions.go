@@ -0,0 +1,281 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/notify"
+	"github.com/yourusername/event-feedback/internal/results"
+	"github.com/yourusername/event-feedback/internal/webhooks"
+	"gorm.io/gorm"
+)
+
+// Submission implements SubmissionStore
+func (s *Gorm) Submission(ctx context.Context, id uint) (database.Submission, error) {
+	var submission database.Submission
+	err := s.DB.WithContext(ctx).First(&submission, id).Error
+	return submission, lookupError(err)
+}
+
+// SubmissionByKey implements SubmissionStore
+func (s *Gorm) SubmissionByKey(ctx context.Context, key string) (database.Submission, error) {
+	var submission database.Submission
+	err := s.DB.WithContext(ctx).Where("submission_key = ?", key).First(&submission).Error
+	return submission, lookupError(err)
+}
+
+// DraftSubmission implements SubmissionStore
+func (s *Gorm) DraftSubmission(ctx context.Context, formID uint, respondentKey string) (database.Submission, error) {
+	var submission database.Submission
+	err := s.DB.WithContext(ctx).
+		Where("form_id = ? AND respondent_key = ? AND status = ?", formID, respondentKey, "in_progress").
+		Order("updated_at desc").
+		First(&submission).Error
+	return submission, lookupError(err)
+}
+
+// FormSubmissions implements SubmissionStore
+func (s *Gorm) FormSubmissions(ctx context.Context, formID uint, offset, limit int) ([]database.Submission, error) {
+	var submissions []database.Submission
+	err := s.DB.WithContext(ctx).Where("form_id = ?", formID).
+		Order("created_at desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// FlaggedSubmissions implements SubmissionStore
+func (s *Gorm) FlaggedSubmissions(ctx context.Context, formID uint) ([]database.Submission, error) {
+	var submissions []database.Submission
+	err := s.DB.WithContext(ctx).Where("form_id = ? AND status = ?", formID, "flagged").
+		Order("completed_at").
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// SearchSubmissions implements SubmissionStore
+func (s *Gorm) SearchSubmissions(ctx context.Context, filter SubmissionFilter, offset, limit int) ([]database.Submission, int64, error) {
+	db := s.DB.WithContext(ctx)
+	query := db.Model(&database.Submission{}).Where("form_id IN (?)", memberFormIDs(db, filter.UserID))
+	if filter.FormID != nil {
+		query = query.Where("form_id = ?", *filter.FormID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var submissions []database.Submission
+	err := query.Order("created_at desc, id").Offset(offset).Limit(limit).Find(&submissions).Error
+	return submissions, total, err
+}
+
+// CountCompleted implements SubmissionStore
+func (s *Gorm) CountCompleted(ctx context.Context, formID uint, respondentKey string) (int64, error) {
+	return database.CountCompleted(s.DB.WithContext(ctx), formID, respondentKey)
+}
+
+// SaveStep implements SubmissionStore
+func (s *Gorm) SaveStep(ctx context.Context, step Step) error {
+	submission := step.Submission
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if step.Check != nil {
+			if err := database.LockForm(tx, step.Form.ID); err != nil {
+				return err
+			}
+			if err := step.Check(NewGorm(tx)); err != nil {
+				return err
+			}
+		}
+
+		if submission.ID == 0 {
+			if err := tx.Create(submission).Error; err != nil {
+				return err
+			}
+		} else {
+			// Claim the submission's step; a concurrent request saving the
+			// same step waits for this one and then finds it gone
+			result := tx.Model(&database.Submission{}).
+				Where("id = ? AND status = ? AND current_step = ?", submission.ID, "in_progress", step.OnStep).
+				Update("updated_at", time.Now())
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrConflict
+			}
+		}
+		for i := range step.Responses {
+			step.Responses[i].SubmissionID = submission.ID
+		}
+		if err := database.SaveResponses(tx, step.Responses); err != nil {
+			return err
+		}
+		if step.Shown != nil {
+			if err := deleteHiddenResponses(tx, submission.ID, step.Shown); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(submission).Error; err != nil {
+			return err
+		}
+
+		if submission.Status != "completed" {
+			return nil
+		}
+		// Queue the notification emails and webhooks; they are sent in the background
+		if err := notify.SubmissionCompleted(tx, step.Form, *submission); err != nil {
+			return err
+		}
+		return webhooks.SubmissionCompleted(tx, step.Form, *submission)
+	})
+}
+
+// deleteHiddenResponses deletes the answers of a submission to every field not in shown
+func deleteHiddenResponses(tx *gorm.DB, submissionID uint, shown map[uint]bool) error {
+	query := tx.Where("submission_id = ?", submissionID)
+	if len(shown) > 0 {
+		fieldIDs := make([]uint, 0, len(shown))
+		for fieldID := range shown {
+			fieldIDs = append(fieldIDs, fieldID)
+		}
+		query = query.Where("field_id NOT IN ?", fieldIDs)
+	}
+	return query.Delete(&database.SubmissionResponse{}).Error
+}
+
+// SaveSubmission implements SubmissionStore
+func (s *Gorm) SaveSubmission(ctx context.Context, submission *database.Submission, responses []database.SubmissionResponse) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(submission).Error; err != nil {
+			return err
+		}
+		for i := range responses {
+			responses[i].SubmissionID = submission.ID
+		}
+		return database.SaveResponses(tx, responses)
+	})
+}
+
+// ApproveSubmission implements SubmissionStore
+func (s *Gorm) ApproveSubmission(ctx context.Context, form database.Form, submission *database.Submission) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		submission.Status = "completed"
+		submission.FlagReason = ""
+		if err := tx.Save(submission).Error; err != nil {
+			return err
+		}
+		// Send the notifications and webhooks held back while it was flagged
+		if err := notify.SubmissionCompleted(tx, form, *submission); err != nil {
+			return err
+		}
+		return webhooks.SubmissionCompleted(tx, form, *submission)
+	})
+}
+
+// DeleteSubmission implements SubmissionStore
+func (s *Gorm) DeleteSubmission(ctx context.Context, submission database.Submission) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("submission_id = ?", submission.ID).Delete(&database.SubmissionResponse{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&submission).Error
+	})
+}
+
+// Responses implements SubmissionStore
+func (s *Gorm) Responses(ctx context.Context, submissionID uint) ([]database.SubmissionResponse, error) {
+	var responses []database.SubmissionResponse
+	err := s.DB.WithContext(ctx).Preload("Field").
+		Where("submission_id = ?", submissionID).
+		Order("field_id").
+		Find(&responses).Error
+	return responses, err
+}
+
+// FieldAnswered implements SubmissionStore
+func (s *Gorm) FieldAnswered(ctx context.Context, fieldID uint) (bool, error) {
+	var count int64
+	err := s.DB.WithContext(ctx).Model(&database.SubmissionResponse{}).
+		Where("field_id = ?", fieldID).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Answers implements SubmissionStore
+func (s *Gorm) Answers(ctx context.Context, submission database.Submission) ([]database.Answer, error) {
+	return database.SubmissionAnswers(s.DB.WithContext(ctx), submission)
+}
+
+// Results implements SubmissionStore
+func (s *Gorm) Results(ctx context.Context, form database.Form, fields []database.FormField) (*results.FormResults, error) {
+	return results.ForForm(s.DB.WithContext(ctx), form, fields)
+}
+
+// ExportSubmissions implements SubmissionStore. Submissions are streamed
+// joined with their answers rather than loaded all at once.
+func (s *Gorm) ExportSubmissions(ctx context.Context, formID uint, includeInProgress bool, fn func(database.Submission, []database.SubmissionResponse) error) error {
+	// Order the rows so that all answers of a submission arrive together
+	query := s.DB.WithContext(ctx).Table("submissions").
+		Select("submissions.id, submissions.submission_key, submissions.status, submissions.created_at, submissions.completed_at, submission_responses.field_id, submission_responses.response").
+		Joins("LEFT JOIN submission_responses ON submission_responses.submission_id = submissions.id AND submission_responses.deleted_at IS NULL").
+		Where("submissions.form_id = ? AND submissions.deleted_at IS NULL", formID).
+		Order("submissions.id, submission_responses.id")
+	if includeInProgress {
+		query = query.Where("submissions.status <> ?", "flagged")
+	} else {
+		query = query.Where("submissions.status = ?", "completed")
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var current database.Submission
+	var responses []database.SubmissionResponse
+	for rows.Next() {
+		var (
+			submission database.Submission
+			fieldID    sql.NullInt64
+			response   sql.NullString
+		)
+		err := rows.Scan(&submission.ID, &submission.SubmissionKey, &submission.Status, &submission.CreatedAt, &submission.CompletedAt, &fieldID, &response)
+		if err != nil {
+			return err
+		}
+		submission.FormID = formID
+
+		// Hand the previous submission over once the next one starts
+		if submission.ID != current.ID {
+			if current.ID != 0 {
+				if err := fn(current, responses); err != nil {
+					return err
+				}
+			}
+			current, responses = submission, nil
+		}
+		if fieldID.Valid {
+			responses = append(responses, database.SubmissionResponse{
+				SubmissionID: submission.ID,
+				FieldID:      uint(fieldID.Int64),
+				Response:     response.String,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if current.ID == 0 {
+		return nil
+	}
+	return fn(current, responses)
+}
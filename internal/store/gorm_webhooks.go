@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/webhooks"
+	"gorm.io/gorm"
+)
+
+// EventWebhooks implements WebhookStore
+func (s *Gorm) EventWebhooks(ctx context.Context, eventID uint) ([]database.Webhook, error) {
+	var hooks []database.Webhook
+	err := s.DB.WithContext(ctx).Preload("Form").Where("event_id = ?", eventID).Order("id").Find(&hooks).Error
+	return hooks, err
+}
+
+// Webhook implements WebhookStore
+func (s *Gorm) Webhook(ctx context.Context, eventID, id uint) (database.Webhook, error) {
+	var hook database.Webhook
+	err := s.DB.WithContext(ctx).Where("id = ? AND event_id = ?", id, eventID).First(&hook).Error
+	return hook, lookupError(err)
+}
+
+// CreateWebhook implements WebhookStore
+func (s *Gorm) CreateWebhook(ctx context.Context, hook *database.Webhook) error {
+	return s.DB.WithContext(ctx).Create(hook).Error
+}
+
+// SaveWebhook implements WebhookStore
+func (s *Gorm) SaveWebhook(ctx context.Context, hook *database.Webhook) error {
+	return s.DB.WithContext(ctx).Save(hook).Error
+}
+
+// DeleteWebhook implements WebhookStore
+func (s *Gorm) DeleteWebhook(ctx context.Context, hook database.Webhook) error {
+	return s.DB.WithContext(ctx).Delete(&hook).Error
+}
+
+// Deliveries implements WebhookStore
+func (s *Gorm) Deliveries(ctx context.Context, eventID uint, status string, limit int) ([]database.WebhookDelivery, error) {
+	db := s.DB.WithContext(ctx)
+	eventHooks := db.Unscoped().Model(&database.Webhook{}).Select("id").Where("event_id = ?", eventID)
+
+	query := db.
+		Preload("Webhook", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("AttemptLog", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("webhook_id IN (?)", eventHooks).
+		Order("id desc").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []database.WebhookDelivery
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+// RetryDelivery implements WebhookStore
+func (s *Gorm) RetryDelivery(ctx context.Context, eventID, id uint) error {
+	db := s.DB.WithContext(ctx)
+
+	// Get the delivery, making sure it belongs to one of the event's webhooks
+	var delivery database.WebhookDelivery
+	err := db.
+		Where("id = ? AND status = ? AND webhook_id IN (?)", id, database.DeliveryDead,
+			db.Model(&database.Webhook{}).Select("id").Where("event_id = ?", eventID)).
+		First(&delivery).Error
+	if err != nil {
+		return lookupError(err)
+	}
+	return webhooks.Retry(db, delivery)
+}
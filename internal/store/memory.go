@@ -0,0 +1,260 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+// Memory keeps everything in memory. It is meant for tests: records are
+// copied in and out, but not validated, and no emails or webhook deliveries
+// are queued.
+type Memory struct {
+	mu sync.Mutex
+	// commit serializes the steps saved with a check, as locking the form does in the database
+	commit      sync.Mutex
+	lastID      uint
+	users       map[uint]database.User
+	sessions    map[string]database.Session
+	events      map[uint]database.Event
+	members     map[uint]database.EventMember
+	forms       map[uint]database.Form
+	fields      map[uint]database.FormField // deleted fields are kept, as answers refer to them
+	versions    map[uint]database.FormVersion
+	submissions map[uint]database.Submission
+	responses   map[uint]database.SubmissionResponse
+	templates   map[uint]database.FormTemplate
+	webhooks    map[uint]database.Webhook // deleted webhooks are kept, as deliveries refer to them
+	deliveries  map[uint]database.WebhookDelivery
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		users:       make(map[uint]database.User),
+		sessions:    make(map[string]database.Session),
+		events:      make(map[uint]database.Event),
+		members:     make(map[uint]database.EventMember),
+		forms:       make(map[uint]database.Form),
+		fields:      make(map[uint]database.FormField),
+		versions:    make(map[uint]database.FormVersion),
+		submissions: make(map[uint]database.Submission),
+		responses:   make(map[uint]database.SubmissionResponse),
+		templates:   make(map[uint]database.FormTemplate),
+		webhooks:    make(map[uint]database.Webhook),
+		deliveries:  make(map[uint]database.WebhookDelivery),
+	}
+}
+
+// nextID returns a new record ID, unique across all kinds of records
+func (s *Memory) nextID() uint {
+	s.lastID++
+	return s.lastID
+}
+
+// newModel returns the model of a new record
+func (s *Memory) newModel() gorm.Model {
+	now := time.Now()
+	return gorm.Model{ID: s.nextID(), CreatedAt: now, UpdatedAt: now}
+}
+
+// deleted returns the deletion mark of a record deleted now
+func deleted() gorm.DeletedAt {
+	return gorm.DeletedAt{Time: time.Now(), Valid: true}
+}
+
+// AddMember gives a user a role on an event
+func (s *Memory) AddMember(eventID, userID uint, role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, member := range s.members {
+		if member.EventID == eventID && member.UserID == userID {
+			delete(s.members, id)
+		}
+	}
+	member := database.EventMember{Model: s.newModel(), EventID: eventID, UserID: userID, Role: role}
+	s.members[member.ID] = member
+}
+
+// AddForm stores a new form as it is, setting its ID
+func (s *Memory) AddForm(form *database.Form) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	form.Model = s.newModel()
+	s.forms[form.ID] = *form
+}
+
+// AddField stores a new form field as it is, setting its ID
+func (s *Memory) AddField(field *database.FormField) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	field.Model = s.newModel()
+	s.fields[field.ID] = *field
+}
+
+// AddSubmission stores a new submission as it is, setting its ID
+func (s *Memory) AddSubmission(submission *database.Submission) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submission.Model = s.newModel()
+	s.submissions[submission.ID] = *submission
+}
+
+// AddDelivery stores a new webhook delivery as it is, setting its ID
+func (s *Memory) AddDelivery(delivery *database.WebhookDelivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery.Model = s.newModel()
+	s.deliveries[delivery.ID] = *delivery
+}
+
+// UserByEmail implements UserStore
+func (s *Memory) UserByEmail(ctx context.Context, email string) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return database.User{}, ErrNotFound
+}
+
+// CreateUser implements UserStore
+func (s *Memory) CreateUser(ctx context.Context, user *database.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, other := range s.users {
+		if other.Email == user.Email {
+			return fmt.Errorf("email %q is already in use", user.Email)
+		}
+	}
+	user.Model = s.newModel()
+	s.users[user.ID] = *user
+	return nil
+}
+
+// CreateSession implements UserStore
+func (s *Memory) CreateSession(ctx context.Context, session *database.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, other := range s.sessions {
+		if other.UserID == session.UserID && other.ExpiresAt.Before(time.Now()) {
+			delete(s.sessions, id)
+		}
+	}
+	session.CreatedAt = time.Now()
+	s.sessions[session.ID] = *session
+	return nil
+}
+
+// SessionUser implements UserStore
+func (s *Memory) SessionUser(ctx context.Context, id string) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || !session.ExpiresAt.After(time.Now()) {
+		return database.User{}, ErrNotFound
+	}
+	return s.users[session.UserID], nil
+}
+
+// DeleteSession implements UserStore
+func (s *Memory) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+// UserTemplates implements TemplateStore
+func (s *Memory) UserTemplates(ctx context.Context, userID uint) ([]database.FormTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var templates []database.FormTemplate
+	for _, tmpl := range s.templates {
+		if tmpl.CreatedByID == userID {
+			templates = append(templates, tmpl)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].ID < templates[j].ID
+	})
+	return templates, nil
+}
+
+// UserTemplate implements TemplateStore
+func (s *Memory) UserTemplate(ctx context.Context, userID, id uint) (database.FormTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpl, ok := s.templates[id]
+	if !ok || tmpl.CreatedByID != userID {
+		return database.FormTemplate{}, ErrNotFound
+	}
+	return tmpl, nil
+}
+
+// CreateTemplate implements TemplateStore
+func (s *Memory) CreateTemplate(ctx context.Context, tmpl *database.FormTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpl.Model = s.newModel()
+	s.templates[tmpl.ID] = *tmpl
+	return nil
+}
+
+// DeleteTemplate implements TemplateStore
+func (s *Memory) DeleteTemplate(ctx context.Context, tmpl database.FormTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.templates, tmpl.ID)
+	return nil
+}
+
+// Ready implements HealthStore. Memory is always ready.
+func (s *Memory) Ready(ctx context.Context) error {
+	return nil
+}
+
+// page returns the bounds of the up to limit records after skipping offset of n records
+func page(n, offset, limit int) (from, to int) {
+	if offset > n {
+		offset = n
+	}
+	to = offset + limit
+	if limit < 0 || to > n {
+		to = n
+	}
+	return offset, to
+}
+
+// timeOf returns the time of t, or the zero time if it is null
+func timeOf(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time
+}
@@ -0,0 +1,251 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/formdef"
+)
+
+// Event implements EventStore
+func (s *Memory) Event(ctx context.Context, id uint) (database.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event, ok := s.events[id]
+	if !ok {
+		return database.Event{}, ErrNotFound
+	}
+	return event, nil
+}
+
+// role returns the user's role on an event, or "" if they are not a member
+func (s *Memory) role(userID, eventID uint) string {
+	for _, member := range s.members {
+		if member.EventID == eventID && member.UserID == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+// memberEvents returns the events a user belongs to, latest date first
+func (s *Memory) memberEvents(userID uint) []database.Event {
+	var events []database.Event
+	for id, event := range s.events {
+		if s.role(userID, id) != "" {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Date.Equal(events[j].Date) {
+			return events[i].Date.After(events[j].Date)
+		}
+		return events[i].ID < events[j].ID
+	})
+	return events
+}
+
+// MemberEvents implements EventStore
+func (s *Memory) MemberEvents(ctx context.Context, userID uint) ([]database.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.memberEvents(userID), nil
+}
+
+// EditorEvents implements EventStore
+func (s *Memory) EditorEvents(ctx context.Context, userID uint) ([]database.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []database.Event
+	for _, event := range s.memberEvents(userID) {
+		if role := s.role(userID, event.ID); role == database.RoleEditor || role == database.RoleOwner {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// RecentEvents implements EventStore
+func (s *Memory) RecentEvents(ctx context.Context, userID uint, limit int) ([]database.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := s.memberEvents(userID)
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID > events[j].ID
+	})
+	from, to := page(len(events), 0, limit)
+	return events[from:to], nil
+}
+
+// SearchEvents implements EventStore
+func (s *Memory) SearchEvents(ctx context.Context, filter EventFilter, offset, limit int) ([]database.Event, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []database.Event
+	for _, event := range s.memberEvents(filter.UserID) {
+		switch {
+		case filter.Name != "" && !strings.Contains(strings.ToLower(event.Name), strings.ToLower(filter.Name)):
+		case filter.DateFrom != nil && event.Date.Before(*filter.DateFrom):
+		case filter.DateTo != nil && event.Date.After(*filter.DateTo):
+		default:
+			events = append(events, event)
+		}
+	}
+	from, to := page(len(events), offset, limit)
+	return events[from:to], int64(len(events)), nil
+}
+
+// createEvent stores a new event with the given user as its owner
+func (s *Memory) createEvent(event *database.Event, ownerID uint) {
+	event.Model = s.newModel()
+	s.events[event.ID] = *event
+	member := database.EventMember{Model: s.newModel(), EventID: event.ID, UserID: ownerID, Role: database.RoleOwner}
+	s.members[member.ID] = member
+}
+
+// CreateEvent implements EventStore
+func (s *Memory) CreateEvent(ctx context.Context, event *database.Event, ownerID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.createEvent(event, ownerID)
+	return nil
+}
+
+// CopyEvent implements EventStore
+func (s *Memory) CopyEvent(ctx context.Context, sourceID uint, event *database.Event, ownerID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	restore := s.snapshot()
+	s.createEvent(event, ownerID)
+	for _, form := range s.eventForms(sourceID) {
+		d := formdef.FromForm(form, s.formFields(form.ID))
+		if _, err := s.createFormFrom(d, event.ID, form.Title); err != nil {
+			restore()
+			return fmt.Errorf("form %q: %w", form.Title, err)
+		}
+	}
+	return nil
+}
+
+// SaveEvent implements EventStore
+func (s *Memory) SaveEvent(ctx context.Context, event *database.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.events[event.ID]; !ok {
+		return ErrNotFound
+	}
+	event.UpdatedAt = time.Now()
+	s.events[event.ID] = *event
+	return nil
+}
+
+// DeleteEvent implements EventStore
+func (s *Memory) DeleteEvent(ctx context.Context, event database.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, form := range s.eventForms(event.ID) {
+		s.deleteForm(form.ID)
+	}
+	delete(s.events, event.ID)
+	return nil
+}
+
+// EventRole implements EventStore
+func (s *Memory) EventRole(ctx context.Context, userID, eventID uint) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.role(userID, eventID), nil
+}
+
+// Members implements MemberStore
+func (s *Memory) Members(ctx context.Context, eventID uint) ([]database.EventMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var members []database.EventMember
+	for _, member := range s.members {
+		if member.EventID == eventID {
+			member.User = s.users[member.UserID]
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ID < members[j].ID
+	})
+	return members, nil
+}
+
+// Member implements MemberStore
+func (s *Memory) Member(ctx context.Context, eventID, id uint) (database.EventMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[id]
+	if !ok || member.EventID != eventID {
+		return database.EventMember{}, ErrNotFound
+	}
+	return member, nil
+}
+
+// CreateMember implements MemberStore
+func (s *Memory) CreateMember(ctx context.Context, member *database.EventMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.role(member.UserID, member.EventID) != "" {
+		return fmt.Errorf("user %d is already a member of event %d", member.UserID, member.EventID)
+	}
+	member.Model = s.newModel()
+	s.members[member.ID] = *member
+	return nil
+}
+
+// SaveMember implements MemberStore
+func (s *Memory) SaveMember(ctx context.Context, member *database.EventMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.members[member.ID]; !ok {
+		return ErrNotFound
+	}
+	member.UpdatedAt = time.Now()
+	s.members[member.ID] = *member
+	return nil
+}
+
+// DeleteMember implements MemberStore
+func (s *Memory) DeleteMember(ctx context.Context, member database.EventMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.members, member.ID)
+	return nil
+}
+
+// OwnerCount implements MemberStore
+func (s *Memory) OwnerCount(ctx context.Context, eventID uint) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var owners int64
+	for _, member := range s.members {
+		if member.EventID == eventID && member.Role == database.RoleOwner {
+			owners++
+		}
+	}
+	return owners, nil
+}
@@ -0,0 +1,674 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/formdef"
+	"github.com/yourusername/event-feedback/internal/layout"
+)
+
+// snapshot copies the events, forms and fields, and returns a function
+// putting the copies back, which undoes a change that fails halfway as a
+// transaction would
+func (s *Memory) snapshot() (restore func()) {
+	lastID := s.lastID
+	events := make(map[uint]database.Event, len(s.events))
+	for id, event := range s.events {
+		events[id] = event
+	}
+	members := make(map[uint]database.EventMember, len(s.members))
+	for id, member := range s.members {
+		members[id] = member
+	}
+	forms := make(map[uint]database.Form, len(s.forms))
+	for id, form := range s.forms {
+		forms[id] = form
+	}
+	fields := make(map[uint]database.FormField, len(s.fields))
+	for id, field := range s.fields {
+		fields[id] = field
+	}
+	versions := make(map[uint]database.FormVersion, len(s.versions))
+	for id, version := range s.versions {
+		versions[id] = version
+	}
+	return func() {
+		s.lastID = lastID
+		s.events, s.members, s.forms, s.fields, s.versions = events, members, forms, fields, versions
+	}
+}
+
+// Form implements FormStore
+func (s *Memory) Form(ctx context.Context, id uint) (database.Form, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	form, ok := s.forms[id]
+	if !ok {
+		return database.Form{}, ErrNotFound
+	}
+	return form, nil
+}
+
+// eventForms returns the forms of an event in the order they were created
+func (s *Memory) eventForms(eventID uint) []database.Form {
+	var forms []database.Form
+	for _, form := range s.forms {
+		if form.EventID == eventID {
+			forms = append(forms, form)
+		}
+	}
+	sort.Slice(forms, func(i, j int) bool {
+		return forms[i].ID < forms[j].ID
+	})
+	return forms
+}
+
+// EventForms implements FormStore
+func (s *Memory) EventForms(ctx context.Context, eventID uint) ([]database.Form, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.eventForms(eventID), nil
+}
+
+// PublishedForms implements FormStore
+func (s *Memory) PublishedForms(ctx context.Context, limit int) ([]database.Form, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var forms []database.Form
+	for _, form := range s.forms {
+		if form.IsPublished {
+			form.Event = s.events[form.EventID]
+			forms = append(forms, form)
+		}
+	}
+	sort.Slice(forms, func(i, j int) bool {
+		return forms[i].ID > forms[j].ID
+	})
+	from, to := page(len(forms), 0, limit)
+	return forms[from:to], nil
+}
+
+// SearchForms implements FormStore
+func (s *Memory) SearchForms(ctx context.Context, filter FormFilter, offset, limit int) ([]database.Form, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var forms []database.Form
+	for _, form := range s.forms {
+		switch {
+		case s.role(filter.UserID, form.EventID) == "":
+		case filter.EventID != nil && form.EventID != *filter.EventID:
+		case filter.IsPublished != nil && form.IsPublished != *filter.IsPublished:
+		case filter.Title != "" && !strings.Contains(strings.ToLower(form.Title), strings.ToLower(filter.Title)):
+		default:
+			forms = append(forms, form)
+		}
+	}
+	sort.Slice(forms, func(i, j int) bool {
+		return forms[i].ID > forms[j].ID
+	})
+	from, to := page(len(forms), offset, limit)
+	return forms[from:to], int64(len(forms)), nil
+}
+
+// insertForm stores a new form, giving it a slug unique within its event if it has none
+func (s *Memory) insertForm(form *database.Form) {
+	if form.Slug == "" {
+		var used []string
+		for _, other := range s.eventForms(form.EventID) {
+			used = append(used, other.Slug)
+		}
+		form.Slug = database.PickSlug(database.Slugify(form.Title), "form", used)
+	}
+	form.Model = s.newModel()
+	s.forms[form.ID] = *form
+}
+
+// insertField stores a new field, giving it a ref unique within its form if it has none
+func (s *Memory) insertField(field *database.FormField) {
+	if field.Ref == "" {
+		var used []string
+		for _, other := range s.formFields(field.FormID) {
+			used = append(used, other.Ref)
+		}
+		field.Ref = database.PickSlug(database.Slugify(field.Label), "field", used)
+	}
+	field.Model = s.newModel()
+	s.fields[field.ID] = *field
+}
+
+// publish snapshots the draft of form into a new version unless the latest
+// one has the same content, and shows respondents that version. It reports
+// whether a version was created.
+func (s *Memory) publish(form *database.Form) bool {
+	draft := database.NewVersion(*form, s.formFields(form.ID))
+
+	var latest *database.FormVersion
+	for _, version := range s.versions {
+		if version.FormID == form.ID && (latest == nil || version.Number > latest.Number) {
+			version := version
+			latest = &version
+		}
+	}
+
+	created := latest == nil || !latest.SameContent(draft)
+	if created {
+		draft.Number = 1
+		if latest != nil {
+			draft.Number = latest.Number + 1
+		}
+		draft.Model = s.newModel()
+		s.versions[draft.ID] = draft
+		latest = &draft
+	}
+	form.IsPublished = true
+	form.PublishedVersionID = &latest.ID
+	s.forms[form.ID] = *form
+	return created
+}
+
+// CreateForm implements FormStore
+func (s *Memory) CreateForm(ctx context.Context, form *database.Form) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.insertForm(form)
+	if form.IsPublished {
+		s.publish(form)
+	}
+	return nil
+}
+
+// createFormFrom creates an unpublished form under eventID from a definition.
+// Nothing is stored if the definition is invalid.
+func (s *Memory) createFormFrom(d database.FormDefinition, eventID uint, title string) (database.Form, error) {
+	form, err := formdef.NewForm(d, eventID, title)
+	if err != nil {
+		return form, err
+	}
+	fields, _, err := formdef.Fields(d, nil)
+	if err != nil {
+		return form, err
+	}
+
+	restore := s.snapshot()
+	s.insertForm(&form)
+	for i := range fields {
+		fields[i].FormID = form.ID
+		s.insertField(&fields[i])
+	}
+	if err := s.writeConditions(&form, fields, d); err != nil {
+		restore()
+		return database.Form{}, err
+	}
+	return form, nil
+}
+
+// writeConditions resolves the conditions of d against the stored fields
+// written from it and saves them along with the step rules of form
+func (s *Memory) writeConditions(form *database.Form, fields []database.FormField, d database.FormDefinition) error {
+	conditions, rules, err := formdef.Conditions(d, fields)
+	if err != nil {
+		return err
+	}
+	for i, c := range conditions {
+		field := s.fields[fields[i].ID]
+		field.ShowIf = c
+		s.fields[field.ID] = field
+	}
+	if len(rules) > 0 || len(form.StepRules) > 0 {
+		form.StepRules = rules
+	}
+	s.forms[form.ID] = *form
+	return nil
+}
+
+// CreateFormFrom implements FormStore
+func (s *Memory) CreateFormFrom(ctx context.Context, d database.FormDefinition, eventID uint, title string) (database.Form, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createFormFrom(d, eventID, title)
+}
+
+// ImportForm implements FormStore
+func (s *Memory) ImportForm(ctx context.Context, d database.FormDefinition, eventID uint, dryRun bool) (formdef.ImportResult, error) {
+	d, err := formdef.Prepare(d)
+	if err != nil {
+		return formdef.ImportResult{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := formdef.ImportResult{Slug: d.Slug, DryRun: dryRun}
+	restore := s.snapshot()
+
+	var before database.FormDefinition
+	var existing []database.FormField
+	var form database.Form
+	for _, other := range s.eventForms(eventID) {
+		if other.Slug == d.Slug {
+			form = other
+		}
+	}
+	if form.ID == 0 {
+		result.Created = true
+		form = database.Form{
+			EventID:     eventID,
+			Slug:        d.Slug,
+			Title:       d.Title,
+			IsMultiStep: d.IsMultiStep,
+			NotifyMode:  database.NotifyNone,
+			StepRules:   database.StepRules{},
+		}
+		s.insertForm(&form)
+	} else {
+		existing = s.formFields(form.ID)
+		before = formdef.FromForm(form, existing)
+		form.Title = d.Title
+		form.IsMultiStep = d.IsMultiStep
+		form.UpdatedAt = time.Now()
+	}
+
+	// Write the fields, then the conditions referring to their IDs
+	fields, removed, err := formdef.Fields(d, existing)
+	if err != nil {
+		restore()
+		return formdef.ImportResult{}, err
+	}
+	for i := range fields {
+		fields[i].FormID = form.ID
+		if fields[i].ID == 0 {
+			s.insertField(&fields[i])
+			continue
+		}
+		stored := s.fields[fields[i].ID]
+		stored.Step, stored.FieldType, stored.Label = fields[i].Step, fields[i].FieldType, fields[i].Label
+		stored.Placeholder, stored.Options, stored.Constraints = fields[i].Placeholder, fields[i].Options, fields[i].Constraints
+		stored.IsRequired, stored.FieldOrder = fields[i].IsRequired, fields[i].FieldOrder
+		stored.UpdatedAt = time.Now()
+		s.fields[stored.ID] = stored
+	}
+	for _, field := range removed {
+		s.deleteField(field.ID)
+	}
+	if err := s.writeConditions(&form, fields, d); err != nil {
+		restore()
+		return formdef.ImportResult{}, err
+	}
+
+	result.Form = form
+	result.Changes = formdef.Diff(before, formdef.FromForm(form, s.formFields(form.ID)))
+	if !result.Created || !dryRun {
+		result.FormID = form.ID
+	}
+	if dryRun {
+		restore()
+	}
+	return result, nil
+}
+
+// SaveForm implements FormStore
+func (s *Memory) SaveForm(ctx context.Context, form *database.Form, publish bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.forms[form.ID]; !ok {
+		return ErrNotFound
+	}
+	form.UpdatedAt = time.Now()
+	s.forms[form.ID] = *form
+	if publish {
+		s.publish(form)
+	}
+	return nil
+}
+
+// deleteForm deletes a form together with its fields
+func (s *Memory) deleteForm(id uint) {
+	for _, field := range s.formFields(id) {
+		s.deleteField(field.ID)
+	}
+	delete(s.forms, id)
+}
+
+// DeleteForm implements FormStore
+func (s *Memory) DeleteForm(ctx context.Context, form database.Form) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteForm(form.ID)
+	return nil
+}
+
+// Field implements FormStore
+func (s *Memory) Field(ctx context.Context, id uint) (database.FormField, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	field, ok := s.fields[id]
+	if !ok || field.DeletedAt.Valid {
+		return database.FormField{}, ErrNotFound
+	}
+	return field, nil
+}
+
+// formFields returns the fields of a form that have not been deleted, in step and display order
+func (s *Memory) formFields(formID uint) []database.FormField {
+	var fields []database.FormField
+	for _, field := range s.fields {
+		if field.FormID == formID && !field.DeletedAt.Valid {
+			fields = append(fields, field)
+		}
+	}
+	sortFields(fields)
+	return fields
+}
+
+// sortFields sorts fields by step and display order
+func sortFields(fields []database.FormField) {
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.Step != b.Step {
+			return a.Step < b.Step
+		}
+		if a.FieldOrder != b.FieldOrder {
+			return a.FieldOrder < b.FieldOrder
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Fields implements FormStore
+func (s *Memory) Fields(ctx context.Context, formID uint) ([]database.FormField, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.formFields(formID), nil
+}
+
+// SearchFields implements FormStore
+func (s *Memory) SearchFields(ctx context.Context, filter FieldFilter, offset, limit int) ([]database.FormField, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fields []database.FormField
+	for _, field := range s.fields {
+		switch {
+		case field.DeletedAt.Valid:
+		case s.role(filter.UserID, s.forms[field.FormID].EventID) == "":
+		case filter.FormID != nil && field.FormID != *filter.FormID:
+		case filter.Step != nil && field.Step != *filter.Step:
+		case filter.FieldType != "" && field.FieldType != filter.FieldType:
+		default:
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.FormID != b.FormID {
+			return a.FormID < b.FormID
+		}
+		if a.Step != b.Step {
+			return a.Step < b.Step
+		}
+		return a.FieldOrder < b.FieldOrder
+	})
+	from, to := page(len(fields), offset, limit)
+	return fields[from:to], int64(len(fields)), nil
+}
+
+// CreateField implements FormStore
+func (s *Memory) CreateField(ctx context.Context, field *database.FormField, position int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Append the field to the end of its step
+	field.FieldOrder = 1
+	for _, other := range s.formFields(field.FormID) {
+		if other.Step == field.Step && other.FieldOrder >= field.FieldOrder {
+			field.FieldOrder = other.FieldOrder + 1
+		}
+	}
+	restore := s.snapshot()
+	s.insertField(field)
+	if position < 1 || position >= field.FieldOrder {
+		return nil
+	}
+
+	// Then move it into place
+	if err := s.rearrangeForm(field.FormID, layout.MoveField(field.ID, field.Step, position)); err != nil {
+		restore()
+		return err
+	}
+	*field = s.fields[field.ID]
+	return nil
+}
+
+// SaveField implements FormStore
+func (s *Memory) SaveField(ctx context.Context, field *database.FormField, change layout.Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.fields[field.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	restore := s.snapshot()
+	field.Step, field.FieldOrder = stored.Step, stored.FieldOrder
+	field.UpdatedAt = time.Now()
+	s.fields[field.ID] = *field
+	if change == nil {
+		return nil
+	}
+	if err := s.rearrangeForm(field.FormID, change); err != nil {
+		restore()
+		return err
+	}
+	*field = s.fields[field.ID]
+	return nil
+}
+
+// deleteField marks a field deleted
+func (s *Memory) deleteField(id uint) {
+	field := s.fields[id]
+	field.DeletedAt = deleted()
+	s.fields[id] = field
+}
+
+// DeleteField implements FormStore
+func (s *Memory) DeleteField(ctx context.Context, field database.FormField) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	restore := s.snapshot()
+	s.deleteField(field.ID)
+	if err := s.rearrangeForm(field.FormID, layout.Renumber(field.Step)); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// Rearrange implements FormStore
+func (s *Memory) Rearrange(ctx context.Context, form *database.Form, change layout.Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rearrange(form, change)
+}
+
+// rearrangeForm applies change to the layout of the form with the given ID
+func (s *Memory) rearrangeForm(formID uint, change layout.Change) error {
+	form, ok := s.forms[formID]
+	if !ok {
+		return ErrNotFound
+	}
+	return s.rearrange(&form, change)
+}
+
+// rearrange applies change to the layout of form, saving the fields and step
+// rules it changes and deleting the fields it drops. Nothing is changed if
+// change fails.
+func (s *Memory) rearrange(form *database.Form, change layout.Change) error {
+	fields := s.formFields(form.ID)
+	rules := append(database.StepRules(nil), form.StepRules...)
+
+	changed, err := change(form, append([]database.FormField(nil), fields...))
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[uint]database.FormField, len(changed))
+	for _, field := range changed {
+		kept[field.ID] = field
+	}
+	for _, field := range fields {
+		next, ok := kept[field.ID]
+		switch {
+		case !ok:
+			s.deleteField(field.ID)
+		case next.Step != field.Step || next.FieldOrder != field.FieldOrder:
+			field.Step, field.FieldOrder = next.Step, next.FieldOrder
+			field.UpdatedAt = time.Now()
+			s.fields[field.ID] = field
+		}
+	}
+
+	if !reflect.DeepEqual(rules, form.StepRules) {
+		stored := s.forms[form.ID]
+		stored.StepRules = form.StepRules
+		s.forms[form.ID] = stored
+	}
+	return nil
+}
+
+// Versions implements FormStore
+func (s *Memory) Versions(ctx context.Context, formID uint) ([]database.FormVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.formVersions(formID), nil
+}
+
+// formVersions returns the versions of a form, newest first
+func (s *Memory) formVersions(formID uint) []database.FormVersion {
+	var versions []database.FormVersion
+	for _, version := range s.versions {
+		if version.FormID == formID {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Number > versions[j].Number
+	})
+	return versions
+}
+
+// Version implements FormStore
+func (s *Memory) Version(ctx context.Context, formID, id uint) (database.FormVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version, ok := s.versions[id]
+	if !ok || version.FormID != formID {
+		return database.FormVersion{}, ErrNotFound
+	}
+	return version, nil
+}
+
+// PublishedVersion implements FormStore
+func (s *Memory) PublishedVersion(ctx context.Context, form database.Form) (*database.FormVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if form.PublishedVersionID == nil {
+		return nil, nil
+	}
+	version, ok := s.versions[*form.PublishedVersionID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &version, nil
+}
+
+// HasDraftChanges implements FormStore
+func (s *Memory) HasDraftChanges(ctx context.Context, form database.Form) (bool, error) {
+	published, err := s.PublishedVersion(ctx, form)
+	if err != nil || published == nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !published.SameContent(database.NewVersion(form, s.formFields(form.ID))), nil
+}
+
+// FormAsOf implements FormStore
+func (s *Memory) FormAsOf(ctx context.Context, form database.Form, versionID *uint) (database.Form, []database.FormField, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.formAsOf(form, versionID)
+}
+
+// formAsOf returns form and its fields as they were in the version with ID
+// versionID, or the current draft if versionID is nil
+func (s *Memory) formAsOf(form database.Form, versionID *uint) (database.Form, []database.FormField, error) {
+	if versionID == nil {
+		return form, s.formFields(form.ID), nil
+	}
+	version, ok := s.versions[*versionID]
+	if !ok || version.FormID != form.ID {
+		return form, nil, ErrNotFound
+	}
+	form.Title = version.Title
+	form.IsMultiStep = version.IsMultiStep
+	form.StepRules = version.StepRules
+	return form, version.FormFields(), nil
+}
+
+// ReportFields implements FormStore
+func (s *Memory) ReportFields(ctx context.Context, form database.Form) ([]database.FormField, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Take the published version and those submissions were started against
+	used := make(map[uint]bool)
+	if form.PublishedVersionID != nil {
+		used[*form.PublishedVersionID] = true
+	}
+	unversioned := false
+	for _, submission := range s.submissions {
+		if submission.FormID != form.ID {
+			continue
+		}
+		if submission.FormVersionID == nil {
+			unversioned = true
+		} else {
+			used[*submission.FormVersionID] = true
+		}
+	}
+
+	var versions []database.FormVersion
+	for _, version := range s.formVersions(form.ID) {
+		if used[version.ID] {
+			versions = append(versions, version)
+		}
+	}
+	var draft []database.FormField
+	if unversioned || len(versions) == 0 {
+		draft = s.formFields(form.ID)
+	}
+	return database.MergeFields(versions, draft), nil
+}
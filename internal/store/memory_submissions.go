@@ -0,0 +1,561 @@
+package store
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/results"
+)
+
+// Submission implements SubmissionStore
+func (s *Memory) Submission(ctx context.Context, id uint) (database.Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submission, ok := s.submissions[id]
+	if !ok {
+		return database.Submission{}, ErrNotFound
+	}
+	return submission, nil
+}
+
+// SubmissionByKey implements SubmissionStore
+func (s *Memory) SubmissionByKey(ctx context.Context, key string) (database.Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, submission := range s.submissions {
+		if submission.SubmissionKey == key {
+			return submission, nil
+		}
+	}
+	return database.Submission{}, ErrNotFound
+}
+
+// DraftSubmission implements SubmissionStore
+func (s *Memory) DraftSubmission(ctx context.Context, formID uint, respondentKey string) (database.Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var draft database.Submission
+	for _, submission := range s.formSubmissions(formID, "in_progress") {
+		if submission.RespondentKey == respondentKey && (draft.ID == 0 || submission.UpdatedAt.After(draft.UpdatedAt)) {
+			draft = submission
+		}
+	}
+	if draft.ID == 0 {
+		return draft, ErrNotFound
+	}
+	return draft, nil
+}
+
+// formSubmissions returns the submissions of a form with the given status,
+// or with any status if it is empty, in the order they were started
+func (s *Memory) formSubmissions(formID uint, status string) []database.Submission {
+	var submissions []database.Submission
+	for _, submission := range s.submissions {
+		if submission.FormID == formID && (status == "" || submission.Status == status) {
+			submissions = append(submissions, submission)
+		}
+	}
+	sort.Slice(submissions, func(i, j int) bool {
+		return submissions[i].ID < submissions[j].ID
+	})
+	return submissions
+}
+
+// newestFirst reverses submissions that are in the order they were started
+func newestFirst(submissions []database.Submission) {
+	for i, j := 0, len(submissions)-1; i < j; i, j = i+1, j-1 {
+		submissions[i], submissions[j] = submissions[j], submissions[i]
+	}
+}
+
+// FormSubmissions implements SubmissionStore
+func (s *Memory) FormSubmissions(ctx context.Context, formID uint, offset, limit int) ([]database.Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submissions := s.formSubmissions(formID, "")
+	newestFirst(submissions)
+	from, to := page(len(submissions), offset, limit)
+	return submissions[from:to], nil
+}
+
+// FlaggedSubmissions implements SubmissionStore
+func (s *Memory) FlaggedSubmissions(ctx context.Context, formID uint) ([]database.Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submissions := s.formSubmissions(formID, "flagged")
+	sort.SliceStable(submissions, func(i, j int) bool {
+		return timeOf(submissions[i].CompletedAt).Before(timeOf(submissions[j].CompletedAt))
+	})
+	return submissions, nil
+}
+
+// SearchSubmissions implements SubmissionStore
+func (s *Memory) SearchSubmissions(ctx context.Context, filter SubmissionFilter, offset, limit int) ([]database.Submission, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var submissions []database.Submission
+	for _, submission := range s.submissions {
+		switch {
+		case s.role(filter.UserID, s.forms[submission.FormID].EventID) == "":
+		case filter.FormID != nil && submission.FormID != *filter.FormID:
+		case filter.Status != "" && submission.Status != filter.Status:
+		default:
+			submissions = append(submissions, submission)
+		}
+	}
+	sort.Slice(submissions, func(i, j int) bool {
+		return submissions[i].ID > submissions[j].ID
+	})
+	from, to := page(len(submissions), offset, limit)
+	return submissions[from:to], int64(len(submissions)), nil
+}
+
+// CountCompleted implements SubmissionStore
+func (s *Memory) CountCompleted(ctx context.Context, formID uint, respondentKey string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, submission := range s.formSubmissions(formID, "completed") {
+		if respondentKey == "" || submission.RespondentKey == respondentKey {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// saveSubmission stores a submission, creating it if its ID is 0
+func (s *Memory) saveSubmission(submission *database.Submission) {
+	if submission.ID == 0 {
+		submission.Model = s.newModel()
+	} else {
+		submission.UpdatedAt = time.Now()
+	}
+	s.submissions[submission.ID] = *submission
+}
+
+// saveResponses stores the answers of a submission, replacing any earlier
+// answer of the same submission to the same field
+func (s *Memory) saveResponses(submissionID uint, responses []database.SubmissionResponse) {
+	for i := range responses {
+		responses[i].SubmissionID = submissionID
+		model := s.newModel()
+		for _, other := range s.responses {
+			if other.SubmissionID == submissionID && other.FieldID == responses[i].FieldID {
+				model.ID, model.CreatedAt = other.ID, other.CreatedAt
+			}
+		}
+		responses[i].Model = model
+		s.responses[responses[i].ID] = responses[i]
+	}
+}
+
+// SaveStep implements SubmissionStore
+func (s *Memory) SaveStep(ctx context.Context, step Step) error {
+	if step.Check != nil {
+		s.commit.Lock()
+		defer s.commit.Unlock()
+		if err := step.Check(s); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submission := step.Submission
+	if submission.ID != 0 {
+		stored := s.submissions[submission.ID]
+		if stored.Status != "in_progress" || stored.CurrentStep != step.OnStep {
+			return ErrConflict
+		}
+	}
+	s.saveSubmission(submission)
+	s.saveResponses(submission.ID, step.Responses)
+	if step.Shown != nil {
+		for id, response := range s.responses {
+			if response.SubmissionID == submission.ID && !step.Shown[response.FieldID] {
+				delete(s.responses, id)
+			}
+		}
+	}
+	return nil
+}
+
+// SaveSubmission implements SubmissionStore
+func (s *Memory) SaveSubmission(ctx context.Context, submission *database.Submission, responses []database.SubmissionResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveSubmission(submission)
+	s.saveResponses(submission.ID, responses)
+	return nil
+}
+
+// ApproveSubmission implements SubmissionStore
+func (s *Memory) ApproveSubmission(ctx context.Context, form database.Form, submission *database.Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submission.Status = "completed"
+	submission.FlagReason = ""
+	s.saveSubmission(submission)
+	return nil
+}
+
+// DeleteSubmission implements SubmissionStore
+func (s *Memory) DeleteSubmission(ctx context.Context, submission database.Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, response := range s.responses {
+		if response.SubmissionID == submission.ID {
+			delete(s.responses, id)
+		}
+	}
+	delete(s.submissions, submission.ID)
+	return nil
+}
+
+// submissionResponses returns the answers of a submission in the order they were first given
+func (s *Memory) submissionResponses(submissionID uint) []database.SubmissionResponse {
+	var responses []database.SubmissionResponse
+	for _, response := range s.responses {
+		if response.SubmissionID == submissionID {
+			responses = append(responses, response)
+		}
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].ID < responses[j].ID
+	})
+	return responses
+}
+
+// Responses implements SubmissionStore
+func (s *Memory) Responses(ctx context.Context, submissionID uint) ([]database.SubmissionResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	responses := s.submissionResponses(submissionID)
+	for i := range responses {
+		if field := s.fields[responses[i].FieldID]; !field.DeletedAt.Valid {
+			responses[i].Field = field
+		}
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].FieldID < responses[j].FieldID
+	})
+	return responses, nil
+}
+
+// FieldAnswered implements SubmissionStore
+func (s *Memory) FieldAnswered(ctx context.Context, fieldID uint) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, response := range s.responses {
+		if response.FieldID == fieldID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Answers implements SubmissionStore
+func (s *Memory) Answers(ctx context.Context, submission database.Submission) ([]database.Answer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	responses := s.submissionResponses(submission.ID)
+	var fields []database.FormField
+	if submission.FormVersionID != nil {
+		version, ok := s.versions[*submission.FormVersionID]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		fields = version.FormFields()
+	}
+
+	// Take the fields of the answers the version does not have as they are now
+	inVersion := make(map[uint]bool, len(fields))
+	for _, field := range fields {
+		inVersion[field.ID] = true
+	}
+	var current []database.FormField
+	for _, response := range responses {
+		if field, ok := s.fields[response.FieldID]; ok && !inVersion[field.ID] {
+			current = append(current, field)
+		}
+	}
+	sortFields(current)
+	return database.MatchAnswers(responses, fields, current), nil
+}
+
+// numericAnswer matches the answers to number fields that are counted
+var numericAnswer = regexp.MustCompile(`^\s*-?[0-9]+(\.[0-9]+)?\s*$`)
+
+// Results implements SubmissionStore. The answers are aggregated the same way
+// results.ForForm does in the database.
+func (s *Memory) Results(ctx context.Context, form database.Form, fields []database.FormField) (*results.FormResults, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &results.FormResults{FormID: form.ID, Fields: []results.FieldResult{}}
+
+	// Count submissions by status, leaving out those awaiting moderation
+	var counted []database.Submission
+	completed := make(map[uint]database.Submission)
+	for _, submission := range s.formSubmissions(form.ID, "") {
+		if submission.Status == "flagged" {
+			continue
+		}
+		counted = append(counted, submission)
+		if submission.Status == "completed" {
+			completed[submission.ID] = submission
+		}
+	}
+	res.TotalSubmissions, res.CompletedSubmissions = int64(len(counted)), int64(len(completed))
+	if res.TotalSubmissions > 0 {
+		res.CompletionRate = percent(res.CompletedSubmissions, res.TotalSubmissions)
+	}
+	if form.IsMultiStep {
+		res.Steps = stepResults(counted, fields)
+	}
+
+	// Gather the non-empty answers of completed submissions to each field
+	answers := make(map[uint][]database.SubmissionResponse)
+	for _, submission := range completed {
+		for _, response := range s.submissionResponses(submission.ID) {
+			if response.Response != "" {
+				answers[response.FieldID] = append(answers[response.FieldID], response)
+			}
+		}
+	}
+	for _, field := range fields {
+		res.Fields = append(res.Fields, fieldResult(field, answers[field.ID], completed))
+	}
+	return res, nil
+}
+
+// stepResults counts how many of submissions reached each step and how many stopped there
+func stepResults(submissions []database.Submission, fields []database.FormField) []results.StepResult {
+	totalSteps := 1
+	for _, field := range fields {
+		if field.Step > totalSteps {
+			totalSteps = field.Step
+		}
+	}
+
+	atStep := make(map[int]int64)
+	for _, submission := range submissions {
+		step := submission.CurrentStep
+		if submission.Status == "completed" || step > totalSteps+1 {
+			step = totalSteps + 1
+		}
+		atStep[step]++
+	}
+
+	steps := make([]results.StepResult, totalSteps)
+	var reached int64
+	for step := totalSteps + 1; step >= 1; step-- {
+		reached += atStep[step]
+		if step <= totalSteps {
+			steps[step-1] = results.StepResult{
+				Step:       step,
+				Reached:    reached,
+				DroppedOff: atStep[step],
+				DropOff:    percent(atStep[step], reached),
+			}
+		}
+	}
+	return steps
+}
+
+// fieldResult aggregates the answers to one field according to its type
+func fieldResult(field database.FormField, answers []database.SubmissionResponse, completed map[uint]database.Submission) results.FieldResult {
+	res := results.FieldResult{
+		FieldID:       field.ID,
+		Label:         field.Label,
+		FieldType:     field.FieldType,
+		Step:          field.Step,
+		ResponseCount: int64(len(answers)),
+	}
+
+	switch field.FieldType {
+	case "select", "radio", "checkbox":
+		res.Options = optionCounts(field, answers)
+	case "number":
+		res.Number = numberStats(answers)
+	case "text", "textarea":
+		sort.Slice(answers, func(i, j int) bool {
+			a, b := timeOf(completed[answers[i].SubmissionID].CompletedAt), timeOf(completed[answers[j].SubmissionID].CompletedAt)
+			if !a.Equal(b) {
+				return a.After(b)
+			}
+			return answers[i].ID > answers[j].ID
+		})
+		res.RecentAnswers = []results.TextAnswer{}
+		for i := 0; i < len(answers) && i < results.RecentAnswersLimit; i++ {
+			res.RecentAnswers = append(res.RecentAnswers, results.TextAnswer{
+				Response:    answers[i].Response,
+				SubmittedAt: timeOf(completed[answers[i].SubmissionID].CompletedAt),
+			})
+		}
+	}
+	return res
+}
+
+// optionCounts counts how often each option of a choice field was chosen,
+// listing the configured options first
+func optionCounts(field database.FormField, answers []database.SubmissionResponse) []results.OptionCount {
+	counted := make(map[string]int64)
+	var order []string
+	for _, answer := range answers {
+		values := []string{answer.Response}
+		if database.IsMultiValueField(field.FieldType) {
+			// Only answers stored as JSON arrays are counted, as in the database
+			if !strings.HasPrefix(answer.Response, "[") {
+				continue
+			}
+			values = database.SplitResponseValues(answer.Response)
+		}
+		for _, value := range values {
+			if counted[value] == 0 {
+				order = append(order, value)
+			}
+			counted[value]++
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if counted[order[i]] != counted[order[j]] {
+			return counted[order[i]] > counted[order[j]]
+		}
+		return order[i] < order[j]
+	})
+
+	respondents := int64(len(answers))
+	options := []results.OptionCount{}
+	seen := make(map[string]bool)
+	for _, option := range field.Options {
+		seen[option.Value] = true
+		options = append(options, results.OptionCount{
+			Value:   option.Value,
+			Label:   option.Label,
+			Count:   counted[option.Value],
+			Percent: percent(counted[option.Value], respondents),
+		})
+	}
+	for _, value := range order {
+		if seen[value] {
+			continue
+		}
+		options = append(options, results.OptionCount{
+			Value:   value,
+			Label:   value,
+			Count:   counted[value],
+			Percent: percent(counted[value], respondents),
+		})
+	}
+	return options
+}
+
+// numberStats computes the mean, median and a histogram of the numeric answers
+func numberStats(answers []database.SubmissionResponse) *results.NumberStats {
+	var values []float64
+	for _, answer := range answers {
+		if !numericAnswer.MatchString(answer.Response) {
+			continue
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(answer.Response), 64); err == nil {
+			values = append(values, v)
+		}
+	}
+
+	stats := &results.NumberStats{Count: int64(len(values)), Histogram: []results.HistogramBucket{}}
+	if len(values) == 0 {
+		return stats
+	}
+	sort.Float64s(values)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	stats.Mean = sum / float64(len(values))
+	stats.Min, stats.Max = values[0], values[len(values)-1]
+	middle := (len(values) - 1) / 2
+	stats.Median = values[middle]
+	if len(values)%2 == 0 {
+		stats.Median = (values[middle] + values[middle+1]) / 2
+	}
+
+	// All answers are the same, so there is only one bucket
+	if stats.Min == stats.Max {
+		stats.Histogram = append(stats.Histogram, results.HistogramBucket{
+			From:    stats.Min,
+			To:      stats.Max,
+			Count:   stats.Count,
+			Percent: 100,
+		})
+		return stats
+	}
+
+	counts := make([]int64, results.HistogramBuckets)
+	for _, v := range values {
+		bucket := int(math.Floor((v - stats.Min) * results.HistogramBuckets / (stats.Max - stats.Min)))
+		if bucket >= results.HistogramBuckets {
+			bucket = results.HistogramBuckets - 1
+		}
+		counts[bucket]++
+	}
+	width := (stats.Max - stats.Min) / results.HistogramBuckets
+	for i, count := range counts {
+		stats.Histogram = append(stats.Histogram, results.HistogramBucket{
+			From:    stats.Min + float64(i)*width,
+			To:      stats.Min + float64(i+1)*width,
+			Count:   count,
+			Percent: percent(count, stats.Count),
+		})
+	}
+	return stats
+}
+
+// percent returns part as a percentage of total, rounded to one decimal place
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(int64(float64(part)*1000/float64(total)+0.5)) / 10
+}
+
+// ExportSubmissions implements SubmissionStore
+func (s *Memory) ExportSubmissions(ctx context.Context, formID uint, includeInProgress bool, fn func(database.Submission, []database.SubmissionResponse) error) error {
+	s.mu.Lock()
+	var submissions []database.Submission
+	var responses [][]database.SubmissionResponse
+	for _, submission := range s.formSubmissions(formID, "") {
+		if submission.Status == "completed" || includeInProgress && submission.Status != "flagged" {
+			submissions = append(submissions, submission)
+			responses = append(responses, s.submissionResponses(submission.ID))
+		}
+	}
+	s.mu.Unlock()
+
+	for i, submission := range submissions {
+		if err := fn(submission, responses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
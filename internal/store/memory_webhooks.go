@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+)
+
+// EventWebhooks implements WebhookStore
+func (s *Memory) EventWebhooks(ctx context.Context, eventID uint) ([]database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var hooks []database.Webhook
+	for _, hook := range s.webhooks {
+		if hook.EventID != eventID || hook.DeletedAt.Valid {
+			continue
+		}
+		if hook.FormID != nil {
+			if form, ok := s.forms[*hook.FormID]; ok {
+				hook.Form = &form
+			}
+		}
+		hooks = append(hooks, hook)
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].ID < hooks[j].ID
+	})
+	return hooks, nil
+}
+
+// Webhook implements WebhookStore
+func (s *Memory) Webhook(ctx context.Context, eventID, id uint) (database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook, ok := s.webhooks[id]
+	if !ok || hook.EventID != eventID || hook.DeletedAt.Valid {
+		return database.Webhook{}, ErrNotFound
+	}
+	return hook, nil
+}
+
+// CreateWebhook implements WebhookStore
+func (s *Memory) CreateWebhook(ctx context.Context, hook *database.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook.Model = s.newModel()
+	s.webhooks[hook.ID] = *hook
+	return nil
+}
+
+// SaveWebhook implements WebhookStore
+func (s *Memory) SaveWebhook(ctx context.Context, hook *database.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[hook.ID]; !ok {
+		return ErrNotFound
+	}
+	hook.UpdatedAt = time.Now()
+	s.webhooks[hook.ID] = *hook
+	return nil
+}
+
+// DeleteWebhook implements WebhookStore
+func (s *Memory) DeleteWebhook(ctx context.Context, hook database.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook = s.webhooks[hook.ID]
+	hook.DeletedAt = deleted()
+	s.webhooks[hook.ID] = hook
+	return nil
+}
+
+// Deliveries implements WebhookStore
+func (s *Memory) Deliveries(ctx context.Context, eventID uint, status string, limit int) ([]database.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deliveries []database.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		hook, ok := s.webhooks[delivery.WebhookID]
+		if !ok || hook.EventID != eventID || status != "" && delivery.Status != status {
+			continue
+		}
+		delivery.Webhook = hook
+		deliveries = append(deliveries, delivery)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	from, to := page(len(deliveries), 0, limit)
+	return deliveries[from:to], nil
+}
+
+// RetryDelivery implements WebhookStore
+func (s *Memory) RetryDelivery(ctx context.Context, eventID, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery, ok := s.deliveries[id]
+	if !ok || delivery.Status != database.DeliveryDead {
+		return ErrNotFound
+	}
+	if hook, ok := s.webhooks[delivery.WebhookID]; !ok || hook.EventID != eventID || hook.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	delivery.Status = database.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	s.deliveries[id] = delivery
+	return nil
+}
@@ -0,0 +1,301 @@
+// Package store gives the handlers access to everything they keep through
+// interfaces. Gorm keeps it in the database; Memory keeps it in memory for
+// tests that should not need one.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"github.com/yourusername/event-feedback/internal/formdef"
+	"github.com/yourusername/event-feedback/internal/layout"
+	"github.com/yourusername/event-feedback/internal/results"
+)
+
+// ErrNotFound is returned when the requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned when a record was changed by another request since
+// it was read
+var ErrConflict = errors.New("record changed by another request")
+
+// Store is everything the handlers keep
+type Store interface {
+	UserStore
+	EventStore
+	MemberStore
+	FormStore
+	SubmissionStore
+	TemplateStore
+	WebhookStore
+	HealthStore
+}
+
+// UserStore reads and writes organizer accounts and their login sessions
+type UserStore interface {
+	// UserByEmail returns the user with the given normalized email address
+	UserByEmail(ctx context.Context, email string) (database.User, error)
+	// CreateUser creates an organizer account
+	CreateUser(ctx context.Context, user *database.User) error
+	// CreateSession stores a session, deleting the user's expired ones
+	CreateSession(ctx context.Context, session *database.Session) error
+	// SessionUser returns the user owning the session with the given ID if
+	// it has not expired
+	SessionUser(ctx context.Context, id string) (database.User, error)
+	// DeleteSession deletes the session with the given ID, if there is one
+	DeleteSession(ctx context.Context, id string) error
+}
+
+// EventFilter selects the events listed by SearchEvents
+type EventFilter struct {
+	UserID   uint       // only events the user belongs to
+	Name     string     // only events whose name contains it in any case, if set
+	DateFrom *time.Time // only events on or after it, if set
+	DateTo   *time.Time // only events on or before it, if set
+}
+
+// EventStore reads and writes events and who belongs to them
+type EventStore interface {
+	// Event returns the event with the given ID
+	Event(ctx context.Context, id uint) (database.Event, error)
+	// MemberEvents returns the events a user belongs to, latest date first
+	MemberEvents(ctx context.Context, userID uint) ([]database.Event, error)
+	// EditorEvents returns the events a user can edit, latest date first
+	EditorEvents(ctx context.Context, userID uint) ([]database.Event, error)
+	// RecentEvents returns up to limit events a user belongs to, newest first
+	RecentEvents(ctx context.Context, userID uint, limit int) ([]database.Event, error)
+	// SearchEvents returns up to limit events matching filter after skipping
+	// offset, latest date first, and how many match in all
+	SearchEvents(ctx context.Context, filter EventFilter, offset, limit int) ([]database.Event, int64, error)
+	// CreateEvent creates an event with the given user as its owner
+	CreateEvent(ctx context.Context, event *database.Event, ownerID uint) error
+	// CopyEvent creates an event with the given user as its owner and
+	// unpublished copies of the forms of the event with ID sourceID
+	CopyEvent(ctx context.Context, sourceID uint, event *database.Event, ownerID uint) error
+	// SaveEvent updates an event
+	SaveEvent(ctx context.Context, event *database.Event) error
+	// DeleteEvent deletes an event together with its forms and their fields
+	DeleteEvent(ctx context.Context, event database.Event) error
+	// EventRole returns the user's role on an event, or "" if they are not a member
+	EventRole(ctx context.Context, userID, eventID uint) (string, error)
+}
+
+// MemberStore reads and writes the members of events
+type MemberStore interface {
+	// Members returns the members of an event with their users, in the order they were added
+	Members(ctx context.Context, eventID uint) ([]database.EventMember, error)
+	// Member returns the member of an event with the given ID
+	Member(ctx context.Context, eventID, id uint) (database.EventMember, error)
+	// CreateMember adds a member to an event
+	CreateMember(ctx context.Context, member *database.EventMember) error
+	// SaveMember updates a member
+	SaveMember(ctx context.Context, member *database.EventMember) error
+	// DeleteMember removes a member for good, so the user can be added again
+	DeleteMember(ctx context.Context, member database.EventMember) error
+	// OwnerCount returns the number of owners of an event
+	OwnerCount(ctx context.Context, eventID uint) (int64, error)
+}
+
+// FormFilter selects the forms listed by SearchForms
+type FormFilter struct {
+	UserID      uint   // only forms of events the user belongs to
+	EventID     *uint  // only forms of the event, if set
+	IsPublished *bool  // only forms with the publish state, if set
+	Title       string // only forms whose title contains it in any case, if set
+}
+
+// FieldFilter selects the fields listed by SearchFields
+type FieldFilter struct {
+	UserID    uint   // only fields of forms of events the user belongs to
+	FormID    *uint  // only fields of the form, if set
+	Step      *int   // only fields on the step, if set
+	FieldType string // only fields of the type, if set
+}
+
+// FormStore reads and writes forms, their fields and their published versions
+type FormStore interface {
+	// Form returns the form with the given ID
+	Form(ctx context.Context, id uint) (database.Form, error)
+	// EventForms returns the forms of an event in the order they were created
+	EventForms(ctx context.Context, eventID uint) ([]database.Form, error)
+	// PublishedForms returns up to limit published forms with their events, newest first
+	PublishedForms(ctx context.Context, limit int) ([]database.Form, error)
+	// SearchForms returns up to limit forms matching filter after skipping
+	// offset, newest first, and how many match in all
+	SearchForms(ctx context.Context, filter FormFilter, offset, limit int) ([]database.Form, int64, error)
+	// CreateForm creates a form, publishing it if it is marked published
+	CreateForm(ctx context.Context, form *database.Form) error
+	// CreateFormFrom creates an unpublished form under eventID from a
+	// definition, with the given title
+	CreateFormFrom(ctx context.Context, d database.FormDefinition, eventID uint, title string) (database.Form, error)
+	// ImportForm creates or updates the form of an event described by a
+	// definition, as formdef.Import does
+	ImportForm(ctx context.Context, d database.FormDefinition, eventID uint, dryRun bool) (formdef.ImportResult, error)
+	// SaveForm updates a form, also publishing its draft if publish is set.
+	// The form.published or form.unpublished webhook is queued if this
+	// changes whether the form is published or publishes a new version.
+	SaveForm(ctx context.Context, form *database.Form, publish bool) error
+	// DeleteForm deletes a form together with its fields
+	DeleteForm(ctx context.Context, form database.Form) error
+
+	// Field returns the field with the given ID
+	Field(ctx context.Context, id uint) (database.FormField, error)
+	// Fields returns the current fields of a form in step and display order
+	Fields(ctx context.Context, formID uint) ([]database.FormField, error)
+	// SearchFields returns up to limit fields matching filter after skipping
+	// offset, by form and in display order, and how many match in all
+	SearchFields(ctx context.Context, filter FieldFilter, offset, limit int) ([]database.FormField, int64, error)
+	// CreateField adds a field to its step at the given position, or at the
+	// end if position is 0 or past it
+	CreateField(ctx context.Context, field *database.FormField, position int) error
+	// SaveField updates a field, keeping its place, then applies change to
+	// the layout of its form if it is not nil. field is read back afterwards.
+	SaveField(ctx context.Context, field *database.FormField, change layout.Change) error
+	// DeleteField deletes a field and closes the gap it leaves in its step
+	DeleteField(ctx context.Context, field database.FormField) error
+	// Rearrange applies change to the layout of a form
+	Rearrange(ctx context.Context, form *database.Form, change layout.Change) error
+
+	// Versions returns the published versions of a form, newest first
+	Versions(ctx context.Context, formID uint) ([]database.FormVersion, error)
+	// Version returns the version of a form with the given ID
+	Version(ctx context.Context, formID, id uint) (database.FormVersion, error)
+	// PublishedVersion returns the version of a form respondents are shown,
+	// or nil if it has never been published
+	PublishedVersion(ctx context.Context, form database.Form) (*database.FormVersion, error)
+	// HasDraftChanges reports whether the draft of a published form differs
+	// from the version respondents are shown
+	HasDraftChanges(ctx context.Context, form database.Form) (bool, error)
+	// FormAsOf returns a form and its fields as they were in the version
+	// with ID versionID, or the current draft if versionID is nil
+	FormAsOf(ctx context.Context, form database.Form, versionID *uint) (database.Form, []database.FormField, error)
+	// ReportFields returns the fields to report the answers to a form under,
+	// as database.ReportFields does
+	ReportFields(ctx context.Context, form database.Form) ([]database.FormField, error)
+}
+
+// SubmissionFilter selects the submissions listed by SearchSubmissions
+type SubmissionFilter struct {
+	UserID uint   // only submissions to forms of events the user belongs to
+	FormID *uint  // only submissions to the form, if set
+	Status string // only submissions with the status, if set
+}
+
+// Step is what a respondent sends on one step of a form
+type Step struct {
+	Form database.Form
+	// Submission is saved with its progress, and created first if its ID is 0
+	Submission *database.Submission
+	// OnStep is the step an existing submission was on when it was read. It
+	// is only saved while still in progress on that step, failing with
+	// ErrConflict otherwise, so that no step is saved twice
+	OnStep int
+	// Responses are the answers given on the step, replacing earlier answers
+	// to the same fields
+	Responses []database.SubmissionResponse
+	// Shown, if not nil, holds the fields the respondent was shown on their
+	// way through the form; answers to any other field are deleted
+	Shown map[uint]bool
+	// Check, if not nil, is called first with the form locked against other
+	// submissions to it, and stops the step from being saved if it fails
+	Check func(submissions SubmissionStore) error
+}
+
+// SubmissionStore reads and writes submissions and their answers
+type SubmissionStore interface {
+	// Submission returns the submission with the given ID
+	Submission(ctx context.Context, id uint) (database.Submission, error)
+	// SubmissionByKey returns the submission with the given key
+	SubmissionByKey(ctx context.Context, key string) (database.Submission, error)
+	// DraftSubmission returns the submission to a form a respondent most
+	// recently worked on without finishing it
+	DraftSubmission(ctx context.Context, formID uint, respondentKey string) (database.Submission, error)
+	// FormSubmissions returns up to limit submissions of a form after
+	// skipping offset, newest first
+	FormSubmissions(ctx context.Context, formID uint, offset, limit int) ([]database.Submission, error)
+	// FlaggedSubmissions returns the submissions of a form flagged as likely
+	// spam, oldest first
+	FlaggedSubmissions(ctx context.Context, formID uint) ([]database.Submission, error)
+	// SearchSubmissions returns up to limit submissions matching filter after
+	// skipping offset, newest first, and how many match in all
+	SearchSubmissions(ctx context.Context, filter SubmissionFilter, offset, limit int) ([]database.Submission, int64, error)
+	// CountCompleted returns the number of completed submissions of a form,
+	// only counting those of the given respondent unless respondentKey is empty
+	CountCompleted(ctx context.Context, formID uint, respondentKey string) (int64, error)
+
+	// SaveStep saves a step of a submission together with its answers. Once
+	// the submission is completed, its notifications and webhooks are queued.
+	SaveStep(ctx context.Context, step Step) error
+	// SaveSubmission saves a submission, creating it if its ID is 0, together
+	// with answers replacing earlier answers to the same fields
+	SaveSubmission(ctx context.Context, submission *database.Submission, responses []database.SubmissionResponse) error
+	// ApproveSubmission completes a submission flagged as likely spam and
+	// queues the notifications and webhooks held back while it was flagged
+	ApproveSubmission(ctx context.Context, form database.Form, submission *database.Submission) error
+	// DeleteSubmission deletes a submission together with its answers
+	DeleteSubmission(ctx context.Context, submission database.Submission) error
+
+	// Responses returns the answers of a submission with their fields, by field ID
+	Responses(ctx context.Context, submissionID uint) ([]database.SubmissionResponse, error)
+	// FieldAnswered reports whether any submission has answered a field
+	FieldAnswered(ctx context.Context, fieldID uint) (bool, error)
+	// Answers returns the answers of a submission under the fields as the
+	// respondent saw them, as database.SubmissionAnswers does
+	Answers(ctx context.Context, submission database.Submission) ([]database.Answer, error)
+	// Results aggregates the answers to a form under the given fields
+	Results(ctx context.Context, form database.Form, fields []database.FormField) (*results.FormResults, error)
+	// ExportSubmissions calls fn with each completed submission of a form, or
+	// each one not awaiting moderation if includeInProgress is set, in the
+	// order they were started, and their answers in the order they were given.
+	// It stops at the first error fn returns.
+	ExportSubmissions(ctx context.Context, formID uint, includeInProgress bool, fn func(database.Submission, []database.SubmissionResponse) error) error
+}
+
+// TemplateStore reads and writes the form templates users save. Templates
+// are only seen by the user who saved them.
+type TemplateStore interface {
+	// UserTemplates returns the templates a user saved, by name
+	UserTemplates(ctx context.Context, userID uint) ([]database.FormTemplate, error)
+	// UserTemplate returns the template with the given ID if the user saved it
+	UserTemplate(ctx context.Context, userID, id uint) (database.FormTemplate, error)
+	// CreateTemplate saves a template
+	CreateTemplate(ctx context.Context, tmpl *database.FormTemplate) error
+	// DeleteTemplate deletes a template
+	DeleteTemplate(ctx context.Context, tmpl database.FormTemplate) error
+}
+
+// WebhookStore reads and writes the webhooks of events and their deliveries
+type WebhookStore interface {
+	// EventWebhooks returns the webhooks of an event with their forms, in
+	// the order they were added
+	EventWebhooks(ctx context.Context, eventID uint) ([]database.Webhook, error)
+	// Webhook returns the webhook of an event with the given ID
+	Webhook(ctx context.Context, eventID, id uint) (database.Webhook, error)
+	// CreateWebhook adds a webhook
+	CreateWebhook(ctx context.Context, hook *database.Webhook) error
+	// SaveWebhook updates a webhook
+	SaveWebhook(ctx context.Context, hook *database.Webhook) error
+	// DeleteWebhook removes a webhook, keeping its deliveries
+	DeleteWebhook(ctx context.Context, hook database.Webhook) error
+	// Deliveries returns up to limit deliveries to the webhooks of an event,
+	// including removed ones, newest first, with their webhooks and attempts.
+	// Only deliveries with the given status are returned unless it is empty.
+	Deliveries(ctx context.Context, eventID uint, status string, limit int) ([]database.WebhookDelivery, error)
+	// RetryDelivery sends a delivery to a webhook of an event that ran out of
+	// attempts again. It returns ErrNotFound if there is no such delivery.
+	RetryDelivery(ctx context.Context, eventID, id uint) error
+}
+
+// HealthStore reports whether the store can be used
+type HealthStore interface {
+	// Ready returns why the store cannot be used, or nil if it can
+	Ready(ctx context.Context) error
+}
+
+// Both stores implement all of the interfaces
+var (
+	_ Store = (*Gorm)(nil)
+	_ Store = (*Memory)(nil)
+)
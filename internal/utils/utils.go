@@ -2,7 +2,6 @@ package utils
 
 import (
 	"html/template"
-	"strings"
 	"time"
 
 	"github.com/yourusername/event-feedback/internal/database"
@@ -39,35 +38,26 @@ func TemplateFuncs() template.FuncMap {
 			return maxStep
 		},
 
-		// Returns the total number of steps in a form
-		"totalSteps": func(fields []database.FormField) int {
-			maxStep := 1
+		// Returns the label of the field with the given ID
+		"fieldLabel": func(fields []database.FormField, id uint) string {
 			for _, field := range fields {
-				if field.Step > maxStep {
-					maxStep = field.Step
+				if field.ID == id {
+					return field.Label
 				}
 			}
-			return maxStep
+			return "(deleted field)"
 		},
 
-		// Splits options string into a slice of options
-		"splitOptions": func(options string) []string {
-			if options == "" {
-				return []string{}
-			}
-			// Remove the JSON array brackets and quotes
-			options = strings.Trim(options, "[]")
-			options = strings.ReplaceAll(options, "\"", "")
-			return strings.Split(options, ",")
+		// Lists the operators branching conditions can use
+		"conditionOperators": func() []database.ConditionOperator {
+			return database.ConditionOperators
 		},
 
-		// Splits a comma-separated string of values into a slice
-		"splitValues": func(values string) []string {
-			if values == "" {
-				return []string{}
-			}
-			return strings.Split(values, ",")
-		},
+		// Returns the human readable form of a condition operator
+		"operatorLabel": database.OperatorLabel,
+
+		// Splits the stored answer to a multi-value field into its values
+		"splitValues": database.SplitResponseValues,
 
 		// Checks if a slice contains a string
 		"contains": func(slice []string, item string) bool {
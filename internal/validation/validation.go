@@ -0,0 +1,188 @@
+// Package validation checks answers submitted to form fields against the
+// field's type, options and constraints.
+package validation
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yourusername/event-feedback/internal/database"
+)
+
+// dateLayout is the format browsers submit date inputs in
+const dateLayout = "2006-01-02"
+
+// decimalNumber matches the plain decimal numbers number fields accept, which
+// are the answers results count in their statistics
+var decimalNumber = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Error describes why an answer was rejected. Message is meant to be shown
+// to the respondent next to the field.
+type Error struct {
+	FieldID uint
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("field %d: %s", e.FieldID, e.Message)
+}
+
+// Errors maps field IDs to the message explaining why their answer was rejected
+type Errors map[uint]string
+
+// Add records err if it is a validation error for a field
+func (e Errors) Add(err error) {
+	if verr, ok := err.(*Error); ok {
+		e[verr.FieldID] = verr.Message
+	}
+}
+
+// Answer checks the values submitted for field. Single-value fields are
+// expected to receive at most one value; checkbox fields may receive several.
+// Values are normalized before they are checked, so callers should store
+// Normalize(values) rather than the values as sent.
+func Answer(field database.FormField, values []string) error {
+	values = Normalize(values)
+
+	if len(values) == 0 {
+		if field.IsRequired {
+			return fieldError(field, "This field is required")
+		}
+		return nil
+	}
+
+	if field.FieldType != "checkbox" && len(values) > 1 {
+		return fieldError(field, "Please give a single answer")
+	}
+
+	switch field.FieldType {
+	case "number":
+		return checkNumber(field, values[0])
+	case "email":
+		if err := checkEmail(field, values[0]); err != nil {
+			return err
+		}
+		return checkText(field, values[0])
+	case "text", "textarea":
+		return checkText(field, values[0])
+	case "date":
+		if _, err := time.Parse(dateLayout, values[0]); err != nil {
+			return fieldError(field, "Please enter a valid date")
+		}
+	case "select", "radio":
+		if !field.Options.Has(values[0]) {
+			return fieldError(field, "Please choose one of the listed options")
+		}
+	case "checkbox":
+		return checkSelections(field, values)
+	}
+	return nil
+}
+
+// checkNumber checks that value is a plain decimal number within the field's
+// limits. Forms like "1e3", "+5" or ".5" are rejected, as results would not
+// count them.
+func checkNumber(field database.FormField, value string) error {
+	if !decimalNumber.MatchString(value) {
+		return fieldError(field, "Please enter a number")
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(n, 0) {
+		return fieldError(field, "Please enter a number")
+	}
+
+	c := field.Constraints
+	if c.Min != nil && n < *c.Min {
+		return fieldError(field, "Please enter a number no less than "+formatNumber(*c.Min))
+	}
+	if c.Max != nil && n > *c.Max {
+		return fieldError(field, "Please enter a number no greater than "+formatNumber(*c.Max))
+	}
+	return nil
+}
+
+// checkEmail checks that value is a bare email address
+func checkEmail(field database.FormField, value string) error {
+	// Display names like "Name <a@b.c>" are not accepted, and the domain needs a dot
+	addr, err := mail.ParseAddress(value)
+	domain := value[strings.LastIndex(value, "@")+1:]
+	if err != nil || addr.Address != value || !strings.Contains(domain, ".") {
+		return fieldError(field, "Please enter a valid email address")
+	}
+	return nil
+}
+
+// checkText checks the length and pattern limits of a text answer
+func checkText(field database.FormField, value string) error {
+	c := field.Constraints
+	length := utf8.RuneCountInString(value)
+	if c.MinLength != nil && length < *c.MinLength {
+		return fieldError(field, fmt.Sprintf("Please enter at least %d characters", *c.MinLength))
+	}
+	if c.MaxLength != nil && length > *c.MaxLength {
+		return fieldError(field, fmt.Sprintf("Please enter no more than %d characters", *c.MaxLength))
+	}
+
+	if c.Pattern != "" {
+		// The whole answer has to match, not just part of it
+		pattern, err := regexp.Compile("^(?:" + c.Pattern + ")$")
+		if err != nil {
+			return fieldError(field, "This field cannot be validated, please contact the organizer")
+		}
+		if !pattern.MatchString(value) {
+			return fieldError(field, "Please match the requested format")
+		}
+	}
+	return nil
+}
+
+// checkSelections checks that every checked value is an option, chosen once,
+// and that the number of selections is within the field's limits
+func checkSelections(field database.FormField, values []string) error {
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		if !field.Options.Has(value) {
+			return fieldError(field, "Please choose only from the listed options")
+		}
+		if seen[value] {
+			return fieldError(field, "Each option can only be chosen once")
+		}
+		seen[value] = true
+	}
+
+	c := field.Constraints
+	if c.MinSelections != nil && len(values) < *c.MinSelections {
+		return fieldError(field, fmt.Sprintf("Please choose at least %d options", *c.MinSelections))
+	}
+	if c.MaxSelections != nil && len(values) > *c.MaxSelections {
+		return fieldError(field, fmt.Sprintf("Please choose no more than %d options", *c.MaxSelections))
+	}
+	return nil
+}
+
+// Normalize trims values of surrounding whitespace and drops the ones that are blank
+func Normalize(values []string) []string {
+	var kept []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+// formatNumber formats a limit without unnecessary decimals
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// fieldError returns a validation error for field
+func fieldError(field database.FormField, message string) *Error {
+	return &Error{FieldID: field.ID, Message: message}
+}
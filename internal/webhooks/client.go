@@ -0,0 +1,109 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errBlockedDestination is returned when a webhook would be sent to an
+// address inside the server's own network
+var errBlockedDestination = errors.New("webhooks cannot be sent to private, loopback or link-local addresses")
+
+// blockedNetworks are the ranges not covered by the net.IP checks in
+// isBlocked that webhooks cannot reach either
+var blockedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // "this" network
+	mustParseCIDR("100.64.0.0/10"), // carrier-grade NAT
+	mustParseCIDR("192.0.0.0/24"),  // IETF protocol assignments
+	mustParseCIDR("198.18.0.0/15"), // benchmarking
+	mustParseCIDR("240.0.0.0/4"),   // reserved, including broadcast
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// isBlocked reports whether ip belongs to the server's own host or network
+// rather than the public internet
+func isBlocked(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDestination refuses connections to blocked addresses. It runs on the
+// resolved address of every connection, so a host name that resolves to a
+// blocked address, at registration or later, cannot reach it either.
+func checkDestination(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isBlocked(ip) {
+		return fmt.Errorf("%w: %s", errBlockedDestination, host)
+	}
+	return nil
+}
+
+// NewClient returns the HTTP client webhooks are delivered with. It only
+// connects to public addresses, goes through no proxy and does not follow
+// redirects, which count as failed deliveries.
+func NewClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   requestTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDestination,
+	}
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   requestTimeout,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// ValidateURL checks that rawURL is an absolute http or https URL that does
+// not obviously point inside the server's network. Host names are checked
+// again when deliveries connect.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("the URL must start with http:// or https://")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errBlockedDestination
+	}
+	if ip := net.ParseIP(host); ip != nil && isBlocked(ip) {
+		return errBlockedDestination
+	}
+	return nil
+}
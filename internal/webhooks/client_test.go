@@ -0,0 +1,95 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// errOther stands for any error other than errBlockedDestination
+var errOther = errors.New("other error")
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{url: "https://hooks.example.com/feedback", want: nil},
+		{url: "http://203.0.113.10:8080/hook", want: nil},
+		{url: "http://localhost/hook", want: errBlockedDestination},
+		{url: "http://LOCALHOST./hook", want: errBlockedDestination},
+		{url: "http://app.localhost/hook", want: errBlockedDestination},
+		{url: "http://127.0.0.1/hook", want: errBlockedDestination},
+		{url: "http://[::1]/hook", want: errBlockedDestination},
+		{url: "http://10.1.2.3/hook", want: errBlockedDestination},
+		{url: "http://172.16.0.1/hook", want: errBlockedDestination},
+		{url: "http://192.168.1.1/hook", want: errBlockedDestination},
+		{url: "http://169.254.169.254/latest/meta-data", want: errBlockedDestination},
+		{url: "http://100.64.0.1/hook", want: errBlockedDestination},
+		{url: "http://0.0.0.0/hook", want: errBlockedDestination},
+		{url: "http://[fd00::1]/hook", want: errBlockedDestination},
+		{url: "http://[::ffff:127.0.0.1]/hook", want: errBlockedDestination},
+		{url: "ftp://hooks.example.com/feedback", want: errOther},
+		{url: "/relative/hook", want: errOther},
+	}
+
+	for _, tt := range tests {
+		err := ValidateURL(tt.url)
+		switch {
+		case tt.want == errOther:
+			if err == nil || errors.Is(err, errBlockedDestination) {
+				t.Errorf("ValidateURL(%q) = %v, want an invalid URL error", tt.url, err)
+			}
+		case !errors.Is(err, tt.want):
+			t.Errorf("ValidateURL(%q) = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestCheckDestination(t *testing.T) {
+	tests := []struct {
+		address string
+		blocked bool
+	}{
+		{address: "203.0.113.10:443"},
+		{address: "[2001:db8::1]:443"},
+		{address: "127.0.0.1:80", blocked: true},
+		{address: "[::1]:80", blocked: true},
+		{address: "10.0.0.5:80", blocked: true},
+		{address: "192.168.0.10:80", blocked: true},
+		{address: "169.254.169.254:80", blocked: true},
+		{address: "[fe80::1]:80", blocked: true},
+		{address: "[fc00::1]:80", blocked: true},
+		{address: "224.0.0.1:80", blocked: true},
+		{address: "255.255.255.255:80", blocked: true},
+	}
+
+	for _, tt := range tests {
+		err := checkDestination("tcp", tt.address, nil)
+		if blocked := errors.Is(err, errBlockedDestination); blocked != tt.blocked || (!tt.blocked && err != nil) {
+			t.Errorf("checkDestination(%q) = %v, want blocked: %t", tt.address, err, tt.blocked)
+		}
+	}
+}
+
+func TestNewClientRefusesLoopback(t *testing.T) {
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+
+	// The test server listens on a loopback address, which the client must
+	// not connect to
+	resp, err := NewClient().Post(server.URL, "application/json", nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, errBlockedDestination) {
+		t.Errorf("got error %v, want %v", err, errBlockedDestination)
+	}
+	if reached {
+		t.Error("the request reached the server")
+	}
+}
@@ -0,0 +1,191 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxAttempts is how often a delivery is tried before it is moved to the dead-letter list
+	MaxAttempts = 10
+	// requestTimeout bounds a single delivery attempt
+	requestTimeout = 10 * time.Second
+	// deliveryBatch is the largest number of deliveries sent per run
+	deliveryBatch = 50
+	// maxDrainBody is how much of a response is read so its connection can be reused
+	maxDrainBody = 64 << 10
+)
+
+// Deliver sends the pending deliveries that are due and returns how many
+// succeeded. Failed attempts are retried later with exponential backoff.
+func Deliver(ctx context.Context, db *gorm.DB, client *http.Client) (int, error) {
+	var deliveries []database.WebhookDelivery
+	err := db.Preload("Webhook").
+		Where("status = ? AND next_attempt_at <= ?", database.DeliveryPending, time.Now()).
+		Order("next_attempt_at, id").Limit(deliveryBatch).Find(&deliveries).Error
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+
+		// Claim the delivery by counting the attempt, so that other workers
+		// skip it until the attempt has had time to finish
+		result := db.Model(&database.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND attempts = ?", delivery.ID, database.DeliveryPending, delivery.Attempts).
+			Updates(map[string]interface{}{
+				"attempts":        delivery.Attempts + 1,
+				"next_attempt_at": time.Now().Add(backoff(delivery.Attempts + 1)),
+			})
+		if result.Error != nil {
+			return delivered, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		delivery.Attempts++
+
+		attempt := database.WebhookAttempt{DeliveryID: delivery.ID}
+		hook := delivery.Webhook
+		if hook.ID == 0 || !hook.IsActive {
+			// The webhook was removed or switched off after the payload was queued
+			attempt.Error = "webhook is no longer active"
+			delivery.Attempts = MaxAttempts
+		} else {
+			start := time.Now()
+			attempt.StatusCode, err = send(ctx, client, hook, delivery)
+			attempt.DurationMS = time.Since(start).Milliseconds()
+			if err != nil {
+				attempt.Error = err.Error()
+			}
+		}
+
+		updates := map[string]interface{}{
+			"last_status_code": attempt.StatusCode,
+			"last_error":       attempt.Error,
+		}
+		if attempt.Error == "" {
+			updates["status"] = database.DeliveryDelivered
+			updates["delivered_at"] = time.Now()
+			delivered++
+		} else {
+			log.Printf("Webhook delivery %d to %s failed (attempt %d): %s", delivery.ID, hook.URL, delivery.Attempts, attempt.Error)
+			if delivery.Attempts >= MaxAttempts {
+				updates["status"] = database.DeliveryDead
+			}
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&attempt).Error; err != nil {
+				return err
+			}
+			return tx.Model(&delivery).Updates(updates).Error
+		})
+		if err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// send posts a delivery to its webhook and returns the response status code.
+// Any status other than 2xx is an error.
+func send(ctx context.Context, client *http.Client, hook database.Webhook, delivery database.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "event-feedback-webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.Topic)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Signature", Sign(hook.Secret, time.Now(), body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// The response body is not kept, as organizers see the error and the
+	// body could be anything the receiving server sends
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Retry moves a dead delivery back into the queue for another round of attempts
+func Retry(db *gorm.DB, delivery database.WebhookDelivery) error {
+	return db.Model(&delivery).Updates(map[string]interface{}{
+		"status":          database.DeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error
+}
+
+// backoff returns how long to wait before the attempt after the given one:
+// 30 seconds after the first, doubling up to 12 hours
+func backoff(attempts int) time.Duration {
+	delay := 30 * time.Second
+	for i := 1; i < attempts && delay < 12*time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > 12*time.Hour {
+		delay = 12 * time.Hour
+	}
+	return delay
+}
+
+// Worker periodically delivers the webhook queue
+type Worker struct {
+	DB       *gorm.DB
+	Client   *http.Client
+	Interval time.Duration
+}
+
+// NewWorker returns a worker checking the queue every 10 seconds and
+// delivering to public addresses only
+func NewWorker(db *gorm.DB) *Worker {
+	return &Worker{
+		DB:       db,
+		Client:   NewClient(),
+		Interval: 10 * time.Second,
+	}
+}
+
+// Run works until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := Deliver(ctx, w.DB, w.Client); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to deliver webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+)
+
+// verify checks a signature header as a receiver following the package
+// documentation would, also rejecting timestamps over five minutes old
+// to stop replays
+func verify(secret, header string, body []byte, now time.Time) bool {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || now.Sub(time.Unix(sent, 0)) > 5*time.Minute {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
+
+func TestSign(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"submission.completed"}`)
+	header := Sign("whsec_test", now, body)
+
+	if !strings.HasPrefix(header, "t=1700000000,v1=") || len(header) != len("t=1700000000,v1=")+64 {
+		t.Fatalf("got header %q, want t=1700000000,v1=<64 hex digits>", header)
+	}
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		valid  bool
+	}{
+		{name: "valid", secret: "whsec_test", header: header, body: body, now: now, valid: true},
+		{name: "wrong secret", secret: "whsec_other", header: header, body: body, now: now},
+		{name: "changed body", secret: "whsec_test", header: header, body: []byte(`{"type":"form.published"}`), now: now},
+		{name: "changed timestamp", secret: "whsec_test", header: strings.Replace(header, "t=1700000000", "t=1700000001", 1), body: body, now: now},
+		{name: "replayed later", secret: "whsec_test", header: header, body: body, now: now.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		if valid := verify(tt.secret, tt.header, tt.body, tt.now); valid != tt.valid {
+			t.Errorf("%s: got valid %t, want %t", tt.name, valid, tt.valid)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 3, want: 2 * time.Minute},
+		{attempts: 9, want: 128 * time.Minute},
+		{attempts: 12, want: 12 * time.Hour},
+		{attempts: 100, want: 12 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverRetriesFailedDeliveries(t *testing.T) {
+	db, err := database.Open(database.Config{Driver: "sqlite", Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// The receiver checks every signature, and fails until told otherwise
+	var status, signed atomic.Int64
+	status.Store(http.StatusInternalServerError)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if verify("whsec_test", r.Header.Get("X-Webhook-Signature"), body, time.Now()) {
+			signed.Add(1)
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	event := database.Event{Name: "Conference", Date: time.Now()}
+	hook := database.Webhook{URL: server.URL, Secret: "whsec_test", IsActive: true}
+	delivery := database.WebhookDelivery{Topic: "form.published", Payload: `{"type":"form.published"}`, Status: database.DeliveryPending, NextAttemptAt: time.Now()}
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(db.Create(&event).Error)
+	hook.EventID = event.ID
+	must(db.Create(&hook).Error)
+	delivery.WebhookID = hook.ID
+	must(db.Create(&delivery).Error)
+
+	// The failed attempt is logged and retried after the backoff
+	start := time.Now()
+	delivered, err := Deliver(context.Background(), db, server.Client())
+	must(err)
+	must(db.First(&delivery, delivery.ID).Error)
+	if delivered != 0 || delivery.Status != database.DeliveryPending || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Errorf("got %d delivered, delivery %+v; want a pending delivery with 1 attempt", delivered, delivery)
+	}
+	if wait := delivery.NextAttemptAt.Sub(start); wait < backoff(1) || wait > backoff(1)+time.Minute {
+		t.Errorf("next attempt in %v, want %v", wait, backoff(1))
+	}
+
+	// Nothing is sent before the retry is due
+	delivered, err = Deliver(context.Background(), db, server.Client())
+	must(err)
+	if delivered != 0 || signed.Load() != 1 {
+		t.Errorf("got %d delivered and %d requests before the retry was due, want 0 and 1", delivered, signed.Load())
+	}
+
+	status.Store(http.StatusNoContent)
+	must(db.Model(&delivery).Update("next_attempt_at", time.Now()).Error)
+	delivered, err = Deliver(context.Background(), db, server.Client())
+	must(err)
+	must(db.First(&delivery, delivery.ID).Error)
+	if delivered != 1 || delivery.Status != database.DeliveryDelivered || delivery.Attempts != 2 || signed.Load() != 2 {
+		t.Errorf("got %d delivered, %d signed requests, delivery %+v; want it delivered on attempt 2", delivered, signed.Load(), delivery)
+	}
+	var attempts int64
+	must(db.Model(&database.WebhookAttempt{}).Where("delivery_id = ?", delivery.ID).Count(&attempts).Error)
+	if attempts != 2 {
+		t.Errorf("got %d logged attempts, want 2", attempts)
+	}
+}
@@ -0,0 +1,157 @@
+// Package webhooks sends the lifecycle events of forms and submissions to
+// the endpoints organizers configure. Payloads are queued in the database in
+// the same transaction as the change they describe and delivered in the
+// background with retries.
+//
+// Every request is a JSON POST signed with the webhook's secret. The
+// X-Webhook-Signature header has the form "t=<unix time>,v1=<signature>",
+// where the signature is the hex encoded HMAC-SHA256 of "<unix time>.<body>".
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/event-feedback/internal/database"
+	"gorm.io/gorm"
+)
+
+// Payload is the JSON body sent to webhooks
+type Payload struct {
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// FormData describes a form in payloads
+type FormData struct {
+	ID          uint   `json:"id"`
+	EventID     uint   `json:"event_id"`
+	Title       string `json:"title"`
+	IsMultiStep bool   `json:"is_multi_step"`
+	IsPublished bool   `json:"is_published"`
+	VersionID   *uint  `json:"published_version_id"`
+}
+
+// SubmissionData describes a completed submission in payloads
+type SubmissionData struct {
+	ID            uint           `json:"id"`
+	SubmissionKey string         `json:"submission_key"`
+	FormVersionID *uint          `json:"form_version_id"`
+	CompletedAt   *time.Time     `json:"completed_at"`
+	Responses     []ResponseData `json:"responses"`
+}
+
+// ResponseData is an answer to a single field in payloads. Answers to
+// multi-value fields are also given as a list in Values.
+type ResponseData struct {
+	FieldID  uint     `json:"field_id"`
+	Label    string   `json:"label"`
+	Response string   `json:"response"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// FormPublished queues the form.published or form.unpublished event,
+// depending on the form's current state
+func FormPublished(tx *gorm.DB, form database.Form) error {
+	topic := database.TopicFormUnpublished
+	if form.IsPublished {
+		topic = database.TopicFormPublished
+	}
+	return trigger(tx, topic, form, map[string]interface{}{"form": formData(form)})
+}
+
+// SubmissionCompleted queues the submission.completed event. It must be
+// called in the transaction that completes the submission.
+func SubmissionCompleted(tx *gorm.DB, form database.Form, submission database.Submission) error {
+	answers, err := database.SubmissionAnswers(tx, submission)
+	if err != nil {
+		return err
+	}
+
+	data := SubmissionData{
+		ID:            submission.ID,
+		SubmissionKey: submission.SubmissionKey,
+		FormVersionID: submission.FormVersionID,
+		Responses:     make([]ResponseData, 0, len(answers)),
+	}
+	if submission.CompletedAt.Valid {
+		completedAt := submission.CompletedAt.Time
+		data.CompletedAt = &completedAt
+	}
+	for _, resp := range answers {
+		item := ResponseData{FieldID: resp.Field.ID, Label: resp.Field.Label, Response: resp.Response}
+		if database.IsMultiValueField(resp.Field.FieldType) {
+			item.Values = database.SplitResponseValues(resp.Response)
+		}
+		data.Responses = append(data.Responses, item)
+	}
+
+	return trigger(tx, database.TopicSubmissionCompleted, form, map[string]interface{}{
+		"form":       formData(form),
+		"submission": data,
+	})
+}
+
+// trigger queues a payload for every active webhook of the form's event that
+// is not limited to another form
+func trigger(tx *gorm.DB, topic string, form database.Form, data interface{}) error {
+	var hooks []database.Webhook
+	err := tx.Where("event_id = ? AND is_active = ? AND (form_id IS NULL OR form_id = ?)", form.EventID, true, form.ID).
+		Find(&hooks).Error
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(Payload{Type: topic, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]database.WebhookDelivery, 0, len(hooks))
+	for _, hook := range hooks {
+		deliveries = append(deliveries, database.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Topic:         topic,
+			Payload:       string(body),
+			Status:        database.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// formData converts a form to its payload representation
+func formData(form database.Form) FormData {
+	return FormData{
+		ID:          form.ID,
+		EventID:     form.EventID,
+		Title:       form.Title,
+		IsMultiStep: form.IsMultiStep,
+		IsPublished: form.IsPublished,
+		VersionID:   form.PublishedVersionID,
+	}
+}
+
+// Sign returns the X-Webhook-Signature header value for body sent at t
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random secret for signing payloads
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
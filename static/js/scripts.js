@@ -173,4 +173,46 @@ document.addEventListener('DOMContentLoaded', function() {
             }
         });
     }
-});
\ No newline at end of file
+});
+// Proof of work asked for before a form is submitted
+document.addEventListener('DOMContentLoaded', function() {
+    const nonceInput = document.querySelector('input[name="pow_nonce"]');
+    if (!nonceInput) return;
+
+    const form = nonceInput.closest('form');
+    const puzzle = form.querySelector('input[name="pow_puzzle"]').value;
+    const difficulty = parseInt(nonceInput.dataset.powDifficulty, 10);
+    const status = form.querySelector('.pow-status');
+    const submitButtons = form.querySelectorAll('button[type="submit"]:not([value="prev"])');
+    submitButtons.forEach(button => button.disabled = true);
+
+    // Count the zero bits at the start of a hash
+    function leadingZeroBits(bytes) {
+        let n = 0;
+        for (const b of bytes) {
+            if (b === 0) {
+                n += 8;
+                continue;
+            }
+            return n + Math.clz32(b) - 24;
+        }
+        return n;
+    }
+
+    // Try nonces in batches so that the page stays responsive
+    const encoder = new TextEncoder();
+    let nonce = 0;
+    async function solve() {
+        for (let end = nonce + 1000; nonce < end; nonce++) {
+            const hash = await crypto.subtle.digest('SHA-256', encoder.encode(puzzle + ':' + nonce));
+            if (leadingZeroBits(new Uint8Array(hash)) >= difficulty) {
+                nonceInput.value = nonce.toString();
+                submitButtons.forEach(button => button.disabled = false);
+                if (status) status.textContent = 'Ready to submit.';
+                return;
+            }
+        }
+        setTimeout(solve, 0);
+    }
+    solve();
+});